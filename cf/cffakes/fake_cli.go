@@ -9,17 +9,17 @@ import (
 )
 
 type FakeCli struct {
-	GetAppStub        func(string) (plugin_models.GetAppModel, error)
-	getAppMutex       sync.RWMutex
-	getAppArgsForCall []struct {
-		arg1 string
+	CliCommandWithoutTerminalOutputStub        func(...string) ([]string, error)
+	cliCommandWithoutTerminalOutputMutex       sync.RWMutex
+	cliCommandWithoutTerminalOutputArgsForCall []struct {
+		arg1 []string
 	}
-	getAppReturns struct {
-		result1 plugin_models.GetAppModel
+	cliCommandWithoutTerminalOutputReturns struct {
+		result1 []string
 		result2 error
 	}
-	getAppReturnsOnCall map[int]struct {
-		result1 plugin_models.GetAppModel
+	cliCommandWithoutTerminalOutputReturnsOnCall map[int]struct {
+		result1 []string
 		result2 error
 	}
 	GetCurrentOrgStub        func() (plugin_models.Organization, error)
@@ -46,31 +46,6 @@ type FakeCli struct {
 		result1 plugin_models.Space
 		result2 error
 	}
-	GetSpaceStub        func(string) (plugin_models.GetSpace_Model, error)
-	getSpaceMutex       sync.RWMutex
-	getSpaceArgsForCall []struct {
-		arg1 string
-	}
-	getSpaceReturns struct {
-		result1 plugin_models.GetSpace_Model
-		result2 error
-	}
-	getSpaceReturnsOnCall map[int]struct {
-		result1 plugin_models.GetSpace_Model
-		result2 error
-	}
-	GetSpacesStub        func() ([]plugin_models.GetSpaces_Model, error)
-	getSpacesMutex       sync.RWMutex
-	getSpacesArgsForCall []struct {
-	}
-	getSpacesReturns struct {
-		result1 []plugin_models.GetSpaces_Model
-		result2 error
-	}
-	getSpacesReturnsOnCall map[int]struct {
-		result1 []plugin_models.GetSpaces_Model
-		result2 error
-	}
 	UsernameStub        func() (string, error)
 	usernameMutex       sync.RWMutex
 	usernameArgsForCall []struct {
@@ -87,65 +62,66 @@ type FakeCli struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeCli) GetApp(arg1 string) (plugin_models.GetAppModel, error) {
-	fake.getAppMutex.Lock()
-	ret, specificReturn := fake.getAppReturnsOnCall[len(fake.getAppArgsForCall)]
-	fake.getAppArgsForCall = append(fake.getAppArgsForCall, struct {
-		arg1 string
+func (fake *FakeCli) CliCommandWithoutTerminalOutput(arg1 ...string) ([]string, error) {
+	fake.cliCommandWithoutTerminalOutputMutex.Lock()
+	ret, specificReturn := fake.cliCommandWithoutTerminalOutputReturnsOnCall[len(fake.cliCommandWithoutTerminalOutputArgsForCall)]
+	fake.cliCommandWithoutTerminalOutputArgsForCall = append(fake.cliCommandWithoutTerminalOutputArgsForCall, struct {
+		arg1 []string
 	}{arg1})
-	fake.recordInvocation("GetApp", []interface{}{arg1})
-	fake.getAppMutex.Unlock()
-	if fake.GetAppStub != nil {
-		return fake.GetAppStub(arg1)
+	stub := fake.CliCommandWithoutTerminalOutputStub
+	fakeReturns := fake.cliCommandWithoutTerminalOutputReturns
+	fake.recordInvocation("CliCommandWithoutTerminalOutput", []interface{}{arg1})
+	fake.cliCommandWithoutTerminalOutputMutex.Unlock()
+	if stub != nil {
+		return stub(arg1...)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getAppReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeCli) GetAppCallCount() int {
-	fake.getAppMutex.RLock()
-	defer fake.getAppMutex.RUnlock()
-	return len(fake.getAppArgsForCall)
+func (fake *FakeCli) CliCommandWithoutTerminalOutputCallCount() int {
+	fake.cliCommandWithoutTerminalOutputMutex.RLock()
+	defer fake.cliCommandWithoutTerminalOutputMutex.RUnlock()
+	return len(fake.cliCommandWithoutTerminalOutputArgsForCall)
 }
 
-func (fake *FakeCli) GetAppCalls(stub func(string) (plugin_models.GetAppModel, error)) {
-	fake.getAppMutex.Lock()
-	defer fake.getAppMutex.Unlock()
-	fake.GetAppStub = stub
+func (fake *FakeCli) CliCommandWithoutTerminalOutputCalls(stub func(...string) ([]string, error)) {
+	fake.cliCommandWithoutTerminalOutputMutex.Lock()
+	defer fake.cliCommandWithoutTerminalOutputMutex.Unlock()
+	fake.CliCommandWithoutTerminalOutputStub = stub
 }
 
-func (fake *FakeCli) GetAppArgsForCall(i int) string {
-	fake.getAppMutex.RLock()
-	defer fake.getAppMutex.RUnlock()
-	argsForCall := fake.getAppArgsForCall[i]
+func (fake *FakeCli) CliCommandWithoutTerminalOutputArgsForCall(i int) []string {
+	fake.cliCommandWithoutTerminalOutputMutex.RLock()
+	defer fake.cliCommandWithoutTerminalOutputMutex.RUnlock()
+	argsForCall := fake.cliCommandWithoutTerminalOutputArgsForCall[i]
 	return argsForCall.arg1
 }
 
-func (fake *FakeCli) GetAppReturns(result1 plugin_models.GetAppModel, result2 error) {
-	fake.getAppMutex.Lock()
-	defer fake.getAppMutex.Unlock()
-	fake.GetAppStub = nil
-	fake.getAppReturns = struct {
-		result1 plugin_models.GetAppModel
+func (fake *FakeCli) CliCommandWithoutTerminalOutputReturns(result1 []string, result2 error) {
+	fake.cliCommandWithoutTerminalOutputMutex.Lock()
+	defer fake.cliCommandWithoutTerminalOutputMutex.Unlock()
+	fake.CliCommandWithoutTerminalOutputStub = nil
+	fake.cliCommandWithoutTerminalOutputReturns = struct {
+		result1 []string
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeCli) GetAppReturnsOnCall(i int, result1 plugin_models.GetAppModel, result2 error) {
-	fake.getAppMutex.Lock()
-	defer fake.getAppMutex.Unlock()
-	fake.GetAppStub = nil
-	if fake.getAppReturnsOnCall == nil {
-		fake.getAppReturnsOnCall = make(map[int]struct {
-			result1 plugin_models.GetAppModel
+func (fake *FakeCli) CliCommandWithoutTerminalOutputReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.cliCommandWithoutTerminalOutputMutex.Lock()
+	defer fake.cliCommandWithoutTerminalOutputMutex.Unlock()
+	fake.CliCommandWithoutTerminalOutputStub = nil
+	if fake.cliCommandWithoutTerminalOutputReturnsOnCall == nil {
+		fake.cliCommandWithoutTerminalOutputReturnsOnCall = make(map[int]struct {
+			result1 []string
 			result2 error
 		})
 	}
-	fake.getAppReturnsOnCall[i] = struct {
-		result1 plugin_models.GetAppModel
+	fake.cliCommandWithoutTerminalOutputReturnsOnCall[i] = struct {
+		result1 []string
 		result2 error
 	}{result1, result2}
 }
@@ -155,15 +131,16 @@ func (fake *FakeCli) GetCurrentOrg() (plugin_models.Organization, error) {
 	ret, specificReturn := fake.getCurrentOrgReturnsOnCall[len(fake.getCurrentOrgArgsForCall)]
 	fake.getCurrentOrgArgsForCall = append(fake.getCurrentOrgArgsForCall, struct {
 	}{})
+	stub := fake.GetCurrentOrgStub
+	fakeReturns := fake.getCurrentOrgReturns
 	fake.recordInvocation("GetCurrentOrg", []interface{}{})
 	fake.getCurrentOrgMutex.Unlock()
-	if fake.GetCurrentOrgStub != nil {
-		return fake.GetCurrentOrgStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getCurrentOrgReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -210,15 +187,16 @@ func (fake *FakeCli) GetCurrentSpace() (plugin_models.Space, error) {
 	ret, specificReturn := fake.getCurrentSpaceReturnsOnCall[len(fake.getCurrentSpaceArgsForCall)]
 	fake.getCurrentSpaceArgsForCall = append(fake.getCurrentSpaceArgsForCall, struct {
 	}{})
+	stub := fake.GetCurrentSpaceStub
+	fakeReturns := fake.getCurrentSpaceReturns
 	fake.recordInvocation("GetCurrentSpace", []interface{}{})
 	fake.getCurrentSpaceMutex.Unlock()
-	if fake.GetCurrentSpaceStub != nil {
-		return fake.GetCurrentSpaceStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getCurrentSpaceReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -260,138 +238,21 @@ func (fake *FakeCli) GetCurrentSpaceReturnsOnCall(i int, result1 plugin_models.S
 	}{result1, result2}
 }
 
-func (fake *FakeCli) GetSpace(arg1 string) (plugin_models.GetSpace_Model, error) {
-	fake.getSpaceMutex.Lock()
-	ret, specificReturn := fake.getSpaceReturnsOnCall[len(fake.getSpaceArgsForCall)]
-	fake.getSpaceArgsForCall = append(fake.getSpaceArgsForCall, struct {
-		arg1 string
-	}{arg1})
-	fake.recordInvocation("GetSpace", []interface{}{arg1})
-	fake.getSpaceMutex.Unlock()
-	if fake.GetSpaceStub != nil {
-		return fake.GetSpaceStub(arg1)
-	}
-	if specificReturn {
-		return ret.result1, ret.result2
-	}
-	fakeReturns := fake.getSpaceReturns
-	return fakeReturns.result1, fakeReturns.result2
-}
-
-func (fake *FakeCli) GetSpaceCallCount() int {
-	fake.getSpaceMutex.RLock()
-	defer fake.getSpaceMutex.RUnlock()
-	return len(fake.getSpaceArgsForCall)
-}
-
-func (fake *FakeCli) GetSpaceCalls(stub func(string) (plugin_models.GetSpace_Model, error)) {
-	fake.getSpaceMutex.Lock()
-	defer fake.getSpaceMutex.Unlock()
-	fake.GetSpaceStub = stub
-}
-
-func (fake *FakeCli) GetSpaceArgsForCall(i int) string {
-	fake.getSpaceMutex.RLock()
-	defer fake.getSpaceMutex.RUnlock()
-	argsForCall := fake.getSpaceArgsForCall[i]
-	return argsForCall.arg1
-}
-
-func (fake *FakeCli) GetSpaceReturns(result1 plugin_models.GetSpace_Model, result2 error) {
-	fake.getSpaceMutex.Lock()
-	defer fake.getSpaceMutex.Unlock()
-	fake.GetSpaceStub = nil
-	fake.getSpaceReturns = struct {
-		result1 plugin_models.GetSpace_Model
-		result2 error
-	}{result1, result2}
-}
-
-func (fake *FakeCli) GetSpaceReturnsOnCall(i int, result1 plugin_models.GetSpace_Model, result2 error) {
-	fake.getSpaceMutex.Lock()
-	defer fake.getSpaceMutex.Unlock()
-	fake.GetSpaceStub = nil
-	if fake.getSpaceReturnsOnCall == nil {
-		fake.getSpaceReturnsOnCall = make(map[int]struct {
-			result1 plugin_models.GetSpace_Model
-			result2 error
-		})
-	}
-	fake.getSpaceReturnsOnCall[i] = struct {
-		result1 plugin_models.GetSpace_Model
-		result2 error
-	}{result1, result2}
-}
-
-func (fake *FakeCli) GetSpaces() ([]plugin_models.GetSpaces_Model, error) {
-	fake.getSpacesMutex.Lock()
-	ret, specificReturn := fake.getSpacesReturnsOnCall[len(fake.getSpacesArgsForCall)]
-	fake.getSpacesArgsForCall = append(fake.getSpacesArgsForCall, struct {
-	}{})
-	fake.recordInvocation("GetSpaces", []interface{}{})
-	fake.getSpacesMutex.Unlock()
-	if fake.GetSpacesStub != nil {
-		return fake.GetSpacesStub()
-	}
-	if specificReturn {
-		return ret.result1, ret.result2
-	}
-	fakeReturns := fake.getSpacesReturns
-	return fakeReturns.result1, fakeReturns.result2
-}
-
-func (fake *FakeCli) GetSpacesCallCount() int {
-	fake.getSpacesMutex.RLock()
-	defer fake.getSpacesMutex.RUnlock()
-	return len(fake.getSpacesArgsForCall)
-}
-
-func (fake *FakeCli) GetSpacesCalls(stub func() ([]plugin_models.GetSpaces_Model, error)) {
-	fake.getSpacesMutex.Lock()
-	defer fake.getSpacesMutex.Unlock()
-	fake.GetSpacesStub = stub
-}
-
-func (fake *FakeCli) GetSpacesReturns(result1 []plugin_models.GetSpaces_Model, result2 error) {
-	fake.getSpacesMutex.Lock()
-	defer fake.getSpacesMutex.Unlock()
-	fake.GetSpacesStub = nil
-	fake.getSpacesReturns = struct {
-		result1 []plugin_models.GetSpaces_Model
-		result2 error
-	}{result1, result2}
-}
-
-func (fake *FakeCli) GetSpacesReturnsOnCall(i int, result1 []plugin_models.GetSpaces_Model, result2 error) {
-	fake.getSpacesMutex.Lock()
-	defer fake.getSpacesMutex.Unlock()
-	fake.GetSpacesStub = nil
-	if fake.getSpacesReturnsOnCall == nil {
-		fake.getSpacesReturnsOnCall = make(map[int]struct {
-			result1 []plugin_models.GetSpaces_Model
-			result2 error
-		})
-	}
-	fake.getSpacesReturnsOnCall[i] = struct {
-		result1 []plugin_models.GetSpaces_Model
-		result2 error
-	}{result1, result2}
-}
-
 func (fake *FakeCli) Username() (string, error) {
 	fake.usernameMutex.Lock()
 	ret, specificReturn := fake.usernameReturnsOnCall[len(fake.usernameArgsForCall)]
 	fake.usernameArgsForCall = append(fake.usernameArgsForCall, struct {
 	}{})
+	stub := fake.UsernameStub
+	fakeReturns := fake.usernameReturns
 	fake.recordInvocation("Username", []interface{}{})
 	fake.usernameMutex.Unlock()
-	if fake.UsernameStub != nil {
-		return fake.UsernameStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.usernameReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -436,16 +297,12 @@ func (fake *FakeCli) UsernameReturnsOnCall(i int, result1 string, result2 error)
 func (fake *FakeCli) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.getAppMutex.RLock()
-	defer fake.getAppMutex.RUnlock()
+	fake.cliCommandWithoutTerminalOutputMutex.RLock()
+	defer fake.cliCommandWithoutTerminalOutputMutex.RUnlock()
 	fake.getCurrentOrgMutex.RLock()
 	defer fake.getCurrentOrgMutex.RUnlock()
 	fake.getCurrentSpaceMutex.RLock()
 	defer fake.getCurrentSpaceMutex.RUnlock()
-	fake.getSpaceMutex.RLock()
-	defer fake.getSpaceMutex.RUnlock()
-	fake.getSpacesMutex.RLock()
-	defer fake.getSpacesMutex.RUnlock()
 	fake.usernameMutex.RLock()
 	defer fake.usernameMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -2,6 +2,7 @@
 package cffakes
 
 import (
+	"context"
 	"sync"
 
 	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
@@ -9,11 +10,12 @@ import (
 )
 
 type FakeProcessInstanceIDFetcher struct {
-	FetchStub        func(lager.Logger, string) (map[int]string, error)
+	FetchStub        func(context.Context, lager.Logger, string) (map[int]string, error)
 	fetchMutex       sync.RWMutex
 	fetchArgsForCall []struct {
-		arg1 lager.Logger
-		arg2 string
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
 	}
 	fetchReturns struct {
 		result1 map[int]string
@@ -27,22 +29,24 @@ type FakeProcessInstanceIDFetcher struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeProcessInstanceIDFetcher) Fetch(arg1 lager.Logger, arg2 string) (map[int]string, error) {
+func (fake *FakeProcessInstanceIDFetcher) Fetch(arg1 context.Context, arg2 lager.Logger, arg3 string) (map[int]string, error) {
 	fake.fetchMutex.Lock()
 	ret, specificReturn := fake.fetchReturnsOnCall[len(fake.fetchArgsForCall)]
 	fake.fetchArgsForCall = append(fake.fetchArgsForCall, struct {
-		arg1 lager.Logger
-		arg2 string
-	}{arg1, arg2})
-	fake.recordInvocation("Fetch", []interface{}{arg1, arg2})
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.FetchStub
+	fakeReturns := fake.fetchReturns
+	fake.recordInvocation("Fetch", []interface{}{arg1, arg2, arg3})
 	fake.fetchMutex.Unlock()
-	if fake.FetchStub != nil {
-		return fake.FetchStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.fetchReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -52,17 +56,17 @@ func (fake *FakeProcessInstanceIDFetcher) FetchCallCount() int {
 	return len(fake.fetchArgsForCall)
 }
 
-func (fake *FakeProcessInstanceIDFetcher) FetchCalls(stub func(lager.Logger, string) (map[int]string, error)) {
+func (fake *FakeProcessInstanceIDFetcher) FetchCalls(stub func(context.Context, lager.Logger, string) (map[int]string, error)) {
 	fake.fetchMutex.Lock()
 	defer fake.fetchMutex.Unlock()
 	fake.FetchStub = stub
 }
 
-func (fake *FakeProcessInstanceIDFetcher) FetchArgsForCall(i int) (lager.Logger, string) {
+func (fake *FakeProcessInstanceIDFetcher) FetchArgsForCall(i int) (context.Context, lager.Logger, string) {
 	fake.fetchMutex.RLock()
 	defer fake.fetchMutex.RUnlock()
 	argsForCall := fake.fetchArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeProcessInstanceIDFetcher) FetchReturns(result1 map[int]string, result2 error) {
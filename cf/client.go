@@ -1,24 +1,33 @@
 package cf
 
 import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync"
+
 	plugin_models "code.cloudfoundry.org/cli/plugin/models"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/pool"
 	"code.cloudfoundry.org/lager"
 )
 
 //go:generate counterfeiter . Cli
 
 type Cli interface {
-	GetApp(string) (plugin_models.GetAppModel, error)
+	CliCommandWithoutTerminalOutput(args ...string) ([]string, error)
 	GetCurrentOrg() (plugin_models.Organization, error)
 	GetCurrentSpace() (plugin_models.Space, error)
-	GetSpace(spaceName string) (plugin_models.GetSpace_Model, error)
-	GetSpaces() ([]plugin_models.GetSpaces_Model, error)
 	Username() (string, error)
 }
 
 //go:generate counterfeiter . ProcessInstanceIDFetcher
 type ProcessInstanceIDFetcher interface {
-	Fetch(logger lager.Logger, appGUID string) (map[int]string, error)
+	Fetch(ctx context.Context, logger lager.Logger, appGUID string) (map[int]string, error)
+}
+
+type Org struct {
+	Name string
+	Guid string
 }
 
 type Space struct {
@@ -26,11 +35,43 @@ type Space struct {
 	Applications []Application
 }
 
+// Application is an app and the instances of its web process. Processes,
+// MemoryInMB and InstanceCount are only set by GetApplication. Processes
+// lists every process of the app that has instances, starting with the web
+// process.
 type Application struct {
-	Name      string
-	Guid      string
-	Space     string
-	Instances map[int]Instance
+	Name          string
+	Guid          string
+	Space         string
+	Instances     map[int]Instance
+	MemoryInMB    int64
+	InstanceCount int
+	Processes     []Process
+}
+
+// WebProcessType is the type of the process apps are pushed with by default.
+const WebProcessType = "web"
+
+// Process is one of the processes of an app, such as web or a worker from a
+// Procfile. Guid is the log-cache source ID of its metrics. MemoryInMB is the
+// memory limit of each instance, and InstanceCount the number of instances
+// the process is scaled to.
+type Process struct {
+	Type          string
+	Guid          string
+	Instances     map[int]Instance
+	MemoryInMB    int64
+	InstanceCount int
+}
+
+// AllProcesses returns the processes of the app, or only its web process when
+// they have not been looked up.
+func (a Application) AllProcesses() []Process {
+	if len(a.Processes) > 0 {
+		return a.Processes
+	}
+
+	return []Process{{Type: WebProcessType, Guid: a.Guid, Instances: a.Instances, MemoryInMB: a.MemoryInMB, InstanceCount: a.InstanceCount}}
 }
 
 type Instance struct {
@@ -40,85 +81,208 @@ type Instance struct {
 
 type Client struct {
 	cli                      Cli
+	cloudController          CloudController
 	processInstanceIDFetcher ProcessInstanceIDFetcher
+	spaceFilter              SpaceFilter
+	concurrency              int
 }
 
+// NewClient returns a client that looks up the targeted org and space and the
+// user with cli, and lists orgs, apps, spaces and processes from the v3 API with
+// `cf curl` unless another cloud controller is set with WithCloudController.
 func NewClient(cli Cli, processInstanceIDFetcher ProcessInstanceIDFetcher) Client {
-	return Client{cli: cli, processInstanceIDFetcher: processInstanceIDFetcher}
+	lockedCli := newLockedCli(cli)
+	return Client{
+		cli:                      lockedCli,
+		cloudController:          cliCloudController{cli: lockedCli},
+		processInstanceIDFetcher: processInstanceIDFetcher,
+		concurrency:              pool.DefaultSize,
+	}
 }
 
-func (c Client) GetSpaces(logger lager.Logger) ([]Space, error) {
+// WithCloudController makes the client list orgs, apps, spaces and processes with
+// the given cloud controller, such as a V3Client.
+func (c Client) WithCloudController(cloudController CloudController) Client {
+	c.cloudController = cloudController
+	return c
+}
+
+// WithConcurrency sets how many apps GetSpaces and GetSpacesInOrg look up
+// the instances of at the same time.
+func (c Client) WithConcurrency(concurrency int) Client {
+	c.concurrency = concurrency
+	return c
+}
+
+// WithSpaceFilter makes GetSpaces and GetSpacesInOrg skip the spaces that do
+// not match the filter, without looking up their apps.
+func (c Client) WithSpaceFilter(filter SpaceFilter) Client {
+	c.spaceFilter = filter
+	return c
+}
+
+// lockedCli serialises calls to the cf CLI, which serves plugin requests from
+// shared state, so that the client can be used from several goroutines.
+type lockedCli struct {
+	cli   Cli
+	mutex *sync.Mutex
+}
+
+func newLockedCli(cli Cli) lockedCli {
+	return lockedCli{cli: cli, mutex: new(sync.Mutex)}
+}
+
+func (c lockedCli) CliCommandWithoutTerminalOutput(args ...string) ([]string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.cli.CliCommandWithoutTerminalOutput(args...)
+}
+
+func (c lockedCli) GetCurrentOrg() (plugin_models.Organization, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.cli.GetCurrentOrg()
+}
+
+func (c lockedCli) GetCurrentSpace() (plugin_models.Space, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.cli.GetCurrentSpace()
+}
+
+func (c lockedCli) Username() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.cli.Username()
+}
+
+// GetSpaces returns the spaces of the targeted org, with their apps.
+func (c Client) GetSpaces(ctx context.Context, logger lager.Logger) ([]Space, error) {
 	logger = logger.Session("cf-get-spaces")
 	logger.Info("start")
 	defer logger.Info("end")
 
-	var spaces []Space
-
-	cfSpaces, err := c.cli.GetSpaces()
+	org, err := c.cli.GetCurrentOrg()
 	if err != nil {
-		logger.Error("failed-to-get-spaces", err)
+		logger.Error("failed-to-get-current-org", err)
 		return nil, err
 	}
 
-	for _, cfSpace := range cfSpaces {
-		cfSpaceDetails, err := c.cli.GetSpace(cfSpace.Name)
-		if err != nil {
-			logger.Error("failed-to-get-space", err, lager.Data{"space": cfSpace.Name})
-			return nil, err
-		}
+	return c.GetSpacesInOrg(ctx, logger, org.Guid)
+}
 
-		var applications []Application
-		for _, cfApp := range cfSpaceDetails.Applications {
-			processInstanceIDs, err := c.processInstanceIDFetcher.Fetch(logger, cfApp.Guid)
-			if err != nil {
-				return []Space{}, err
-			}
-
-			instances := map[int]Instance{}
-			for instanceID, processInstanceID := range processInstanceIDs {
-				instances[instanceID] = Instance{InstanceID: instanceID, ProcessInstanceID: processInstanceID}
-			}
-			applications = append(applications, Application{Guid: cfApp.Guid, Name: cfApp.Name, Space: cfSpace.Name, Instances: instances})
+// addInstances looks up the instances of every app of the spaces, using up
+// to the configured number of concurrent lookups.
+func (c Client) addInstances(ctx context.Context, logger lager.Logger, spaces []Space) error {
+	var applications []*Application
+	for i := range spaces {
+		for j := range spaces[i].Applications {
+			applications = append(applications, &spaces[i].Applications[j])
 		}
-
-		spaces = append(spaces, Space{Name: cfSpace.Name, Applications: applications})
 	}
 
-	return spaces, nil
+	return pool.Run(ctx, c.concurrency, len(applications), func(ctx context.Context, i int) error {
+		application := applications[i]
+		processInstanceIDs, err := c.processInstanceIDFetcher.Fetch(ctx, logger, application.Guid)
+		if err != nil {
+			return err
+		}
+
+		application.Instances = map[int]Instance{}
+		for instanceID, processInstanceID := range processInstanceIDs {
+			application.Instances[instanceID] = Instance{InstanceID: instanceID, ProcessInstanceID: processInstanceID}
+		}
+		return nil
+	})
 }
 
-func (c Client) GetApplication(logger lager.Logger, appName string) (Application, error) {
+// GetApplication returns the app with the given name in the targeted space,
+// with all its processes.
+func (c Client) GetApplication(ctx context.Context, logger lager.Logger, appName string) (Application, error) {
 	logger = logger.Session("cf-get-application", lager.Data{"app": appName})
 	logger.Info("start")
 	defer logger.Info("end")
 
-	app, err := c.cli.GetApp(appName)
+	space, err := c.cli.GetCurrentSpace()
 	if err != nil {
-		logger.Error("failed-to-get-app", err)
+		logger.Error("failed-to-get-current-space", err)
 		return Application{}, err
 	}
 
-	space, err := c.cli.GetCurrentSpace()
+	app, err := c.getApp(ctx, logger, appName, space.Guid)
 	if err != nil {
-		logger.Error("failed-to-get-current-space", err)
 		return Application{}, err
 	}
 
-	processInstanceIDs, err := c.processInstanceIDFetcher.Fetch(logger, app.Guid)
+	processes, err := c.getProcesses(ctx, logger, app.Guid)
 	if err != nil {
 		return Application{}, err
 	}
 
-	instances := map[int]Instance{}
-	for id, _ := range app.Instances {
-		processInstanceID, hasProcessInstanceID := processInstanceIDs[id]
-		if !hasProcessInstanceID {
-			continue
+	webProcess := processes[0]
+	return Application{
+		Name:          app.Name,
+		Guid:          app.Guid,
+		Space:         space.Name,
+		Instances:     webProcess.Instances,
+		MemoryInMB:    webProcess.MemoryInMB,
+		InstanceCount: webProcess.InstanceCount,
+		Processes:     processes,
+	}, nil
+}
+
+// GetOrgs returns the orgs the user can see, sorted by name.
+func (c Client) GetOrgs(ctx context.Context, logger lager.Logger) ([]Org, error) {
+	logger = logger.Session("cf-get-orgs")
+	logger.Info("start")
+	defer logger.Info("end")
+
+	var orgs []Org
+	err := c.listV3Resources(ctx, logger, "/v3/organizations?order_by=name", func(page v3Page) error {
+		var v3Orgs []v3Org
+		if err := json.Unmarshal(page.Resources, &v3Orgs); err != nil {
+			return err
+		}
+		for _, v3Org := range v3Orgs {
+			orgs = append(orgs, Org{Name: v3Org.Name, Guid: v3Org.Guid})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+// GetApplicationNames returns the names of the apps in the targeted space.
+func (c Client) GetApplicationNames(ctx context.Context, logger lager.Logger) ([]string, error) {
+	logger = logger.Session("cf-get-application-names")
+	logger.Info("start")
+	defer logger.Info("end")
+
+	space, err := c.cli.GetCurrentSpace()
+	if err != nil {
+		logger.Error("failed-to-get-current-space", err)
+		return nil, err
+	}
+
+	var appNames []string
+	err = c.listV3Resources(ctx, logger, "/v3/apps?space_guids="+url.QueryEscape(space.Guid), func(page v3Page) error {
+		var apps []v3App
+		if err := json.Unmarshal(page.Resources, &apps); err != nil {
+			return err
+		}
+		for _, app := range apps {
+			appNames = append(appNames, app.Name)
 		}
-		instances[id] = Instance{InstanceID: id, ProcessInstanceID: processInstanceID}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return Application{Name: app.Name, Guid: app.Guid, Space: space.Name, Instances: instances}, nil
+	return appNames, nil
 }
 
 func (c Client) GetCurrentOrg(logger lager.Logger) (string, error) {
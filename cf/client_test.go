@@ -1,9 +1,10 @@
 package cf_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"time"
+	"strings"
 
 	plugin_models "code.cloudfoundry.org/cli/plugin/models"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
@@ -21,6 +22,8 @@ var _ = Describe("Client", func() {
 		cfClient                     cf.Client
 		err                          error
 		logger                       lager.Logger
+		ctx                          context.Context
+		responses                    map[string]string
 	)
 
 	BeforeEach(func() {
@@ -28,37 +31,36 @@ var _ = Describe("Client", func() {
 		fakeProcessInstanceIDFetcher = new(cffakes.FakeProcessInstanceIDFetcher)
 		cfClient = cf.NewClient(fakeCli, fakeProcessInstanceIDFetcher)
 		logger = lagertest.NewTestLogger("cf-client-test")
+		ctx = context.Background()
+
+		responses = map[string]string{}
+		fakeCli.CliCommandWithoutTerminalOutputStub = func(args ...string) ([]string, error) {
+			if len(args) != 2 || args[0] != "curl" {
+				return nil, fmt.Errorf("unexpected command: %v", args)
+			}
+			response, ok := responses[args[1]]
+			if !ok {
+				return nil, fmt.Errorf("unexpected path: %s", args[1])
+			}
+			return strings.Split(response, "\n"), nil
+		}
 	})
 
 	Describe("Spaces", func() {
 		var spaces []cf.Space
 
 		BeforeEach(func() {
-			fakeCli.GetCurrentSpaceReturns(plugin_models.Space{SpaceFields: plugin_models.SpaceFields{Name: "the-space"}}, nil)
-			fakeCli.GetSpacesReturns([]plugin_models.GetSpaces_Model{
-				{Guid: "space1-guid", Name: "space-1"},
-				{Guid: "space2-guid", Name: "space-2"},
-			}, nil)
-
-			fakeCli.GetSpaceStub = func(spaceName string) (plugin_models.GetSpace_Model, error) {
-				switch spaceName {
-				case "space-1":
-					return plugin_models.GetSpace_Model{
-						Applications: []plugin_models.GetSpace_Apps{
-							{Name: "app-1", Guid: "space-1-app-1-guid"},
-							{Name: "app-2", Guid: "space-1-app-2-guid"},
-						},
-					}, nil
-				case "space-2":
-					return plugin_models.GetSpace_Model{
-						Applications: []plugin_models.GetSpace_Apps{
-							{Name: "app-1", Guid: "space-2-app-1-guid"},
-						},
-					}, nil
-				}
-				return plugin_models.GetSpace_Model{}, fmt.Errorf("Space '%s' not found", spaceName)
-			}
-			fakeProcessInstanceIDFetcher.FetchStub = func(logger lager.Logger, appGuid string) (map[int]string, error) {
+			fakeCli.GetCurrentOrgReturns(plugin_models.Organization{OrganizationFields: plugin_models.OrganizationFields{Guid: "the-org-guid", Name: "the-org"}}, nil)
+			responses["/v3/apps?organization_guids=the-org-guid&include=space"] = `{
+				"pagination": {"next": null},
+				"resources": [
+					{"guid": "space-1-app-1-guid", "name": "app-1", "relationships": {"space": {"data": {"guid": "space-1-guid"}}}},
+					{"guid": "space-1-app-2-guid", "name": "app-2", "relationships": {"space": {"data": {"guid": "space-1-guid"}}}},
+					{"guid": "space-2-app-1-guid", "name": "app-1", "relationships": {"space": {"data": {"guid": "space-2-guid"}}}}
+				],
+				"included": {"spaces": [{"guid": "space-1-guid", "name": "space-1"}, {"guid": "space-2-guid", "name": "space-2"}]}
+			}`
+			fakeProcessInstanceIDFetcher.FetchStub = func(_ context.Context, logger lager.Logger, appGuid string) (map[int]string, error) {
 				switch appGuid {
 				case "space-1-app-1-guid":
 					return map[int]string{0: "space-1-app-1-process-instance-0"}, nil
@@ -73,10 +75,10 @@ var _ = Describe("Client", func() {
 		})
 
 		JustBeforeEach(func() {
-			spaces, err = cfClient.GetSpaces(logger)
+			spaces, err = cfClient.GetSpaces(ctx, logger)
 		})
 
-		It("fetches all spaces", func() {
+		It("fetches all spaces of the targeted org", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(spaces).To(Equal([]cf.Space{
 				{
@@ -95,28 +97,61 @@ var _ = Describe("Client", func() {
 			}))
 		})
 
-		When("fetching the list of spaces fails", func() {
+		It("lists the apps with a single request", func() {
+			Expect(fakeCli.CliCommandWithoutTerminalOutputCallCount()).To(Equal(1))
+		})
+
+		When("a space filter is set", func() {
 			BeforeEach(func() {
-				fakeCli.GetSpacesReturns(nil, errors.New("get-spaces-error"))
+				responses["/v3/spaces?organization_guids=the-org-guid"] = `{
+					"pagination": {"next": null},
+					"resources": [{"guid": "space-1-guid", "name": "space-1"}, {"guid": "space-2-guid", "name": "space-2"}]
+				}`
+				responses["/v3/apps?organization_guids=the-org-guid&space_guids=space-2-guid&include=space"] = `{
+					"pagination": {"next": null},
+					"resources": [
+						{"guid": "space-2-app-1-guid", "name": "app-1", "relationships": {"space": {"data": {"guid": "space-2-guid"}}}}
+					],
+					"included": {"spaces": [{"guid": "space-2-guid", "name": "space-2"}]}
+				}`
+				cfClient = cfClient.WithSpaceFilter(cf.SpaceFilter{Names: []string{"space-2"}})
+			})
+
+			It("only fetches the matching spaces", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spaces).To(HaveLen(1))
+				Expect(spaces[0].Name).To(Equal("space-2"))
+			})
+
+			It("does not look up the apps of the other spaces", func() {
+				Expect(fakeProcessInstanceIDFetcher.FetchCallCount()).To(Equal(1))
+			})
+		})
+
+		When("getting the targeted org fails", func() {
+			BeforeEach(func() {
+				fakeCli.GetCurrentOrgReturns(plugin_models.Organization{}, errors.New("get-org-error"))
 			})
 
 			It("returns the error", func() {
-				Expect(err).To(MatchError("get-spaces-error"))
+				Expect(err).To(MatchError("get-org-error"))
 			})
 		})
 
-		When("fetching details about a space fails", func() {
+		When("listing the apps fails", func() {
 			BeforeEach(func() {
-				fakeCli.GetSpaceReturns(plugin_models.GetSpace_Model{}, errors.New("get-space-error"))
+				fakeCli.CliCommandWithoutTerminalOutputStub = nil
+				fakeCli.CliCommandWithoutTerminalOutputReturns(nil, errors.New("curl-error"))
 			})
 
 			It("returns the error", func() {
-				Expect(err).To(MatchError("get-space-error"))
+				Expect(err).To(MatchError("curl-error"))
 			})
 		})
 
 		When("fetching process instance ids fails", func() {
 			BeforeEach(func() {
+				fakeProcessInstanceIDFetcher.FetchStub = nil
 				fakeProcessInstanceIDFetcher.FetchReturns(nil, errors.New("process-instance-id-err"))
 			})
 
@@ -124,44 +159,72 @@ var _ = Describe("Client", func() {
 				Expect(err).To(MatchError("process-instance-id-err"))
 			})
 		})
+
+		When("the concurrency is limited to one", func() {
+			BeforeEach(func() {
+				cfClient = cfClient.WithConcurrency(1)
+			})
+
+			It("still fetches all spaces in order", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spaces).To(HaveLen(2))
+				Expect(spaces[0].Applications[1].Instances[0].ProcessInstanceID).To(Equal("space-1-app-2-process-instance-0"))
+				Expect(spaces[1].Applications[0].Instances[0].ProcessInstanceID).To(Equal("space-2-app-1-process-instance-0"))
+			})
+		})
+
+		When("the context is cancelled", func() {
+			BeforeEach(func() {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				cancel()
+			})
+
+			It("does not fetch the instances and returns the context error", func() {
+				Expect(err).To(MatchError(context.Canceled))
+				Expect(fakeProcessInstanceIDFetcher.FetchCallCount()).To(Equal(0))
+			})
+		})
 	})
 
 	Describe("Application", func() {
 		var application cf.Application
 
 		BeforeEach(func() {
-			fakeCli.GetCurrentSpaceReturns(plugin_models.Space{SpaceFields: plugin_models.SpaceFields{Name: "the-space"}}, nil)
-			fakeCli.UsernameReturns("the-user", nil)
-			fakeCli.GetAppReturns(plugin_models.GetAppModel{
-				Guid: "qwerty",
-				Name: "YTREWQ",
-				Instances: []plugin_models.GetApp_AppInstanceFields{
-					plugin_models.GetApp_AppInstanceFields{Since: time.Unix(123, 456)},
-					plugin_models.GetApp_AppInstanceFields{Since: time.Unix(789, 0)},
-				},
-			}, nil)
+			fakeCli.GetCurrentSpaceReturns(plugin_models.Space{SpaceFields: plugin_models.SpaceFields{Guid: "the-space-guid", Name: "the-space"}}, nil)
+			responses["/v3/apps?names=myapp&space_guids=the-space-guid"] = `{
+				"pagination": {"next": null},
+				"resources": [{"guid": "qwerty", "name": "YTREWQ"}]
+			}`
+			responses["/v3/apps/qwerty/processes"] = `{
+				"pagination": {"next": null},
+				"resources": [{"guid": "qwerty", "type": "web", "instances": 2, "memory_in_mb": 512}]
+			}`
 			fakeProcessInstanceIDFetcher.FetchReturns(map[int]string{0: "proc-instance-id-0", 1: "proc-instance-id-1"}, nil)
 		})
 
 		JustBeforeEach(func() {
-			application, err = cfClient.GetApplication(logger, "myapp")
+			application, err = cfClient.GetApplication(context.Background(), logger, "myapp")
 		})
 
 		It("gets the application info", func() {
 			Expect(err).NotTo(HaveOccurred())
-
-			Expect(fakeCli.GetAppCallCount()).To(Equal(1))
-			actualAppName := fakeCli.GetAppArgsForCall(0)
-			Expect(actualAppName).To(Equal("myapp"))
-
 			Expect(application.Guid).To(Equal("qwerty"))
 			Expect(application.Name).To(Equal("YTREWQ"))
 			Expect(application.Space).To(Equal("the-space"))
+			Expect(application.MemoryInMB).To(BeEquivalentTo(512))
+			Expect(application.InstanceCount).To(Equal(2))
+		})
+
+		It("looks up the app and its processes with the v3 API", func() {
+			Expect(fakeCli.CliCommandWithoutTerminalOutputCallCount()).To(Equal(2))
+			Expect(fakeCli.CliCommandWithoutTerminalOutputArgsForCall(0)).To(Equal([]string{"curl", "/v3/apps?names=myapp&space_guids=the-space-guid"}))
+			Expect(fakeCli.CliCommandWithoutTerminalOutputArgsForCall(1)).To(Equal([]string{"curl", "/v3/apps/qwerty/processes"}))
 		})
 
 		It("gets process instance IDs", func() {
 			Expect(fakeProcessInstanceIDFetcher.FetchCallCount()).To(Equal(1))
-			_, appId := fakeProcessInstanceIDFetcher.FetchArgsForCall(0)
+			_, _, appId := fakeProcessInstanceIDFetcher.FetchArgsForCall(0)
 			Expect(appId).To(Equal("qwerty"))
 		})
 
@@ -172,6 +235,69 @@ var _ = Describe("Client", func() {
 			))
 		})
 
+		It("returns the web process with the application instances", func() {
+			Expect(application.Processes).To(Equal([]cf.Process{
+				{Type: "web", Guid: "qwerty", Instances: application.Instances, MemoryInMB: 512, InstanceCount: 2},
+			}))
+		})
+
+		When("the app has other processes", func() {
+			BeforeEach(func() {
+				responses["/v3/apps/qwerty/processes"] = `{
+					"pagination": {"next": null},
+					"resources": [
+						{"guid": "worker-guid", "type": "worker", "instances": 1, "memory_in_mb": 1024},
+						{"guid": "qwerty", "type": "web", "instances": 2, "memory_in_mb": 512},
+						{"guid": "clock-guid", "type": "clock", "instances": 1},
+						{"guid": "idle-guid", "type": "idle", "instances": 0}
+					]
+				}`
+				fakeProcessInstanceIDFetcher.FetchStub = func(_ context.Context, logger lager.Logger, guid string) (map[int]string, error) {
+					if guid == "qwerty" {
+						return map[int]string{0: "proc-instance-id-0", 1: "proc-instance-id-1"}, nil
+					}
+					return map[int]string{0: guid + "-instance-0", 1: guid + "-stale-instance-1"}, nil
+				}
+			})
+
+			It("returns the processes with instances, web first", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(application.Processes).To(Equal([]cf.Process{
+					{Type: "web", Guid: "qwerty", Instances: application.Instances, MemoryInMB: 512, InstanceCount: 2},
+					{Type: "clock", Guid: "clock-guid", Instances: map[int]cf.Instance{0: {InstanceID: 0, ProcessInstanceID: "clock-guid-instance-0"}}, InstanceCount: 1},
+					{Type: "worker", Guid: "worker-guid", Instances: map[int]cf.Instance{0: {InstanceID: 0, ProcessInstanceID: "worker-guid-instance-0"}}, MemoryInMB: 1024, InstanceCount: 1},
+				}))
+			})
+
+			It("looks up the instances of each process by its guid", func() {
+				Expect(fakeProcessInstanceIDFetcher.FetchCallCount()).To(Equal(3))
+				_, _, guid := fakeProcessInstanceIDFetcher.FetchArgsForCall(1)
+				Expect(guid).To(Equal("clock-guid"))
+				_, _, guid = fakeProcessInstanceIDFetcher.FetchArgsForCall(2)
+				Expect(guid).To(Equal("worker-guid"))
+			})
+		})
+
+		When("the app is not found", func() {
+			BeforeEach(func() {
+				responses["/v3/apps?names=myapp&space_guids=the-space-guid"] = `{"pagination": {"next": null}, "resources": []}`
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("App 'myapp' not found"))
+			})
+		})
+
+		When("listing the processes fails", func() {
+			BeforeEach(func() {
+				responses["/v3/apps/qwerty/processes"] = `{"errors": [{"detail": "Process not found"}]}`
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("Process not found"))
+			})
+		})
+
 		When("process instance id is not available for an instance", func() {
 			BeforeEach(func() {
 				fakeProcessInstanceIDFetcher.FetchReturns(map[int]string{1: "proc-instance-id-1"}, nil)
@@ -197,11 +323,12 @@ var _ = Describe("Client", func() {
 
 		When("get app errors", func() {
 			BeforeEach(func() {
-				fakeCli.GetAppReturns(plugin_models.GetAppModel{}, errors.New("app error"))
+				fakeCli.CliCommandWithoutTerminalOutputStub = nil
+				fakeCli.CliCommandWithoutTerminalOutputReturns(nil, errors.New("curl-error"))
 			})
 
 			It("returns the error", func() {
-				Expect(err).To(MatchError("app error"))
+				Expect(err).To(MatchError("curl-error"))
 			})
 		})
 
@@ -224,6 +351,238 @@ var _ = Describe("Client", func() {
 				Expect(err).To(MatchError("process-instance-id-err"))
 			})
 		})
+
+		When("a cloud controller is set", func() {
+			var fakeCloudController *cffakes.FakeCloudController
+
+			BeforeEach(func() {
+				fakeCloudController = new(cffakes.FakeCloudController)
+				fakeCloudController.GetStub = func(_ context.Context, logger lager.Logger, path string) ([]byte, error) {
+					return []byte(responses[path]), nil
+				}
+				cfClient = cfClient.WithCloudController(fakeCloudController)
+			})
+
+			It("uses it instead of cf curl", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(application.Guid).To(Equal("qwerty"))
+				Expect(fakeCloudController.GetCallCount()).To(Equal(2))
+				Expect(fakeCli.CliCommandWithoutTerminalOutputCallCount()).To(BeZero())
+			})
+		})
+	})
+
+	Describe("ApplicationNames", func() {
+		var appNames []string
+
+		BeforeEach(func() {
+			fakeCli.GetCurrentSpaceReturns(plugin_models.Space{SpaceFields: plugin_models.SpaceFields{Guid: "space-guid", Name: "the-space"}}, nil)
+			responses["/v3/apps?space_guids=space-guid"] = `{
+				"pagination": {"next": {"href": "https://api.example.com/v3/apps?space_guids=space-guid&page=2"}},
+				"resources": [{"guid": "app-1-guid", "name": "app-1"}]
+			}`
+			responses["/v3/apps?space_guids=space-guid&page=2"] = `{
+				"pagination": {"next": null},
+				"resources": [{"guid": "app-2-guid", "name": "app-2"}]
+			}`
+		})
+
+		JustBeforeEach(func() {
+			appNames, err = cfClient.GetApplicationNames(ctx, logger)
+		})
+
+		It("returns the names of the apps in the targeted space from every page", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(appNames).To(Equal([]string{"app-1", "app-2"}))
+		})
+
+		When("getting the current space fails", func() {
+			BeforeEach(func() {
+				fakeCli.GetCurrentSpaceReturns(plugin_models.Space{}, errors.New("space error"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("space error"))
+			})
+		})
+
+		When("the cloud controller returns an error", func() {
+			BeforeEach(func() {
+				responses["/v3/apps?space_guids=space-guid"] = `{"errors": [{"detail": "You are not authorized to perform the requested action"}]}`
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("You are not authorized to perform the requested action"))
+			})
+		})
+	})
+
+	Describe("Orgs", func() {
+		var orgs []cf.Org
+
+		BeforeEach(func() {
+			responses["/v3/organizations?order_by=name"] = `{
+				"pagination": {"next": {"href": "https://api.example.com/v3/organizations?order_by=name&page=2"}},
+				"resources": [{"guid": "org-1-guid", "name": "org-1"}]
+			}`
+			responses["/v3/organizations?order_by=name&page=2"] = `{
+				"pagination": {"next": null},
+				"resources": [{"guid": "org-2-guid", "name": "org-2"}]
+			}`
+		})
+
+		JustBeforeEach(func() {
+			orgs, err = cfClient.GetOrgs(ctx, logger)
+		})
+
+		It("returns the orgs from every page", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orgs).To(Equal([]cf.Org{
+				{Name: "org-1", Guid: "org-1-guid"},
+				{Name: "org-2", Guid: "org-2-guid"},
+			}))
+		})
+
+		When("curl fails", func() {
+			BeforeEach(func() {
+				fakeCli.CliCommandWithoutTerminalOutputStub = nil
+				fakeCli.CliCommandWithoutTerminalOutputReturns(nil, errors.New("curl-error"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("curl-error"))
+			})
+		})
+	})
+
+	Describe("SpacesInOrg", func() {
+		var spaces []cf.Space
+
+		BeforeEach(func() {
+			responses["/v3/apps?organization_guids=org-guid&include=space"] = `{
+				"pagination": {"next": {"href": "https://api.example.com/v3/apps?organization_guids=org-guid&include=space&page=2"}},
+				"resources": [
+					{"guid": "space-2-app-1-guid", "name": "app-1", "relationships": {"space": {"data": {"guid": "space-2-guid"}}}}
+				],
+				"included": {"spaces": [{"guid": "space-2-guid", "name": "space-2"}]}
+			}`
+			responses["/v3/apps?organization_guids=org-guid&include=space&page=2"] = `{
+				"pagination": {"next": null},
+				"resources": [
+					{"guid": "space-1-app-1-guid", "name": "app-1", "relationships": {"space": {"data": {"guid": "space-1-guid"}}}}
+				],
+				"included": {"spaces": [{"guid": "space-1-guid", "name": "space-1"}]}
+			}`
+			fakeProcessInstanceIDFetcher.FetchStub = func(_ context.Context, logger lager.Logger, appGuid string) (map[int]string, error) {
+				return map[int]string{0: appGuid + "-process-instance-0"}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			spaces, err = cfClient.GetSpacesInOrg(ctx, logger, "org-guid")
+		})
+
+		It("fetches every space of the org with its apps, sorted by name", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spaces).To(Equal([]cf.Space{
+				{
+					Name: "space-1",
+					Applications: []cf.Application{
+						{Name: "app-1", Guid: "space-1-app-1-guid", Space: "space-1", Instances: map[int]cf.Instance{0: {InstanceID: 0, ProcessInstanceID: "space-1-app-1-guid-process-instance-0"}}},
+					},
+				},
+				{
+					Name: "space-2",
+					Applications: []cf.Application{
+						{Name: "app-1", Guid: "space-2-app-1-guid", Space: "space-2", Instances: map[int]cf.Instance{0: {InstanceID: 0, ProcessInstanceID: "space-2-app-1-guid-process-instance-0"}}},
+					},
+				},
+			}))
+		})
+
+		When("a space filter is set", func() {
+			BeforeEach(func() {
+				responses["/v3/spaces?organization_guids=org-guid"] = `{
+					"pagination": {"next": {"href": "https://api.example.com/v3/spaces?organization_guids=org-guid&page=2"}},
+					"resources": [{"guid": "space-1-guid", "name": "space-1"}]
+				}`
+				responses["/v3/spaces?organization_guids=org-guid&page=2"] = `{
+					"pagination": {"next": null},
+					"resources": [{"guid": "space-2-guid", "name": "space-2"}]
+				}`
+				responses["/v3/apps?organization_guids=org-guid&space_guids=space-2-guid&include=space"] = `{
+					"pagination": {"next": null},
+					"resources": [
+						{"guid": "space-2-app-1-guid", "name": "app-1", "relationships": {"space": {"data": {"guid": "space-2-guid"}}}}
+					],
+					"included": {"spaces": [{"guid": "space-2-guid", "name": "space-2"}]}
+				}`
+				cfClient = cfClient.WithSpaceFilter(cf.SpaceFilter{Patterns: []string{"*-2"}})
+			})
+
+			It("only fetches the apps of the matching spaces", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spaces).To(HaveLen(1))
+				Expect(spaces[0].Name).To(Equal("space-2"))
+				Expect(fakeProcessInstanceIDFetcher.FetchCallCount()).To(Equal(1))
+				_, _, appGuid := fakeProcessInstanceIDFetcher.FetchArgsForCall(0)
+				Expect(appGuid).To(Equal("space-2-app-1-guid"))
+			})
+
+			When("no space matches", func() {
+				BeforeEach(func() {
+					cfClient = cfClient.WithSpaceFilter(cf.SpaceFilter{Names: []string{"other"}})
+				})
+
+				It("does not list the apps", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(spaces).To(BeEmpty())
+					Expect(fakeCli.CliCommandWithoutTerminalOutputCallCount()).To(Equal(2))
+				})
+			})
+		})
+
+		When("curl fails", func() {
+			BeforeEach(func() {
+				fakeCli.CliCommandWithoutTerminalOutputStub = nil
+				fakeCli.CliCommandWithoutTerminalOutputReturns(nil, errors.New("curl-error"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("curl-error"))
+			})
+		})
+
+		When("the cloud controller returns an error", func() {
+			BeforeEach(func() {
+				responses["/v3/apps?organization_guids=org-guid&include=space"] = `{"errors": [{"detail": "You are not authorized to perform the requested action"}]}`
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("You are not authorized to perform the requested action"))
+			})
+		})
+
+		When("the response is not valid JSON", func() {
+			BeforeEach(func() {
+				responses["/v3/apps?organization_guids=org-guid&include=space"] = "not json"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("Failed to parse the response of /v3/apps?organization_guids=org-guid&include=space")))
+			})
+		})
+
+		When("fetching process instance ids fails", func() {
+			BeforeEach(func() {
+				fakeProcessInstanceIDFetcher.FetchStub = nil
+				fakeProcessInstanceIDFetcher.FetchReturns(nil, errors.New("process-instance-id-err"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("process-instance-id-err"))
+			})
+		})
 	})
 
 	Describe("CurrentOrg", func() {
@@ -0,0 +1,98 @@
+package cf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter . CloudController
+
+// CloudController makes GET requests to the Cloud Controller API. Path is
+// relative to the API endpoint, such as /v3/apps?names=myapp, and the body of
+// the response is returned as is.
+type CloudController interface {
+	Get(ctx context.Context, logger lager.Logger, path string) ([]byte, error)
+}
+
+// cliCloudController makes requests with `cf curl`, through the plugin RPC
+// server of the cf CLI.
+type cliCloudController struct {
+	cli Cli
+}
+
+func (c cliCloudController) Get(ctx context.Context, logger lager.Logger, path string) ([]byte, error) {
+	output, err := c.cli.CliCommandWithoutTerminalOutput("curl", path)
+	if err != nil {
+		logger.Error("failed-to-curl", err, lager.Data{"path": path})
+		return nil, err
+	}
+
+	return []byte(strings.Join(output, "\n")), nil
+}
+
+// HTTPClient is satisfied by *http.Client and httpclient.AuthClient.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// V3Client calls the Cloud Controller API directly rather than through the
+// cf CLI, which is much faster when listing many apps. The HTTP client is
+// expected to authenticate the requests, as httpclient.AuthClient does.
+type V3Client struct {
+	apiURL     string
+	httpClient HTTPClient
+}
+
+func NewV3Client(apiURL string, httpClient HTTPClient) V3Client {
+	return V3Client{apiURL: strings.TrimSuffix(apiURL, "/"), httpClient: httpClient}
+}
+
+func (c V3Client) Get(ctx context.Context, logger lager.Logger, path string) ([]byte, error) {
+	logger = logger.Session("v3-client-get", lager.Data{"path": path})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+path, nil)
+	if err != nil {
+		logger.Error("failed-to-create-request", err)
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		logger.Error("request-failed", err)
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		logger.Error("failed-to-read-response", err)
+		return nil, err
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		err = parseV3Error(path, res.Status, body)
+		logger.Error("unexpected-status", err, lager.Data{"status": res.StatusCode})
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// parseV3Error returns the detail of the first error of a v3 error response,
+// or the status of the response when it has none.
+func parseV3Error(path, status string, body []byte) error {
+	var page v3Page
+	if err := json.Unmarshal(body, &page); err == nil && len(page.Errors) > 0 {
+		return errors.New(page.Errors[0].Detail)
+	}
+
+	return fmt.Errorf("Request to %s failed: %s", path, status)
+}
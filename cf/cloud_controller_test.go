@@ -0,0 +1,127 @@
+package cf_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf/cffakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("V3Client", func() {
+	var (
+		server   *httptest.Server
+		handler  http.HandlerFunc
+		requests []*http.Request
+		v3Client cf.V3Client
+		logger   lager.Logger
+		body     []byte
+		err      error
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"resources": []}`))
+		}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r)
+			handler(w, r)
+		}))
+		v3Client = cf.NewV3Client(server.URL+"/", server.Client())
+		logger = lagertest.NewTestLogger("v3-client-test")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	JustBeforeEach(func() {
+		body, err = v3Client.Get(context.Background(), logger, "/v3/apps?names=myapp")
+	})
+
+	It("gets the path from the API endpoint", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(MatchJSON(`{"resources": []}`))
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Method).To(Equal(http.MethodGet))
+		Expect(requests[0].URL.RequestURI()).To(Equal("/v3/apps?names=myapp"))
+		Expect(requests[0].Header.Get("Accept")).To(Equal("application/json"))
+	})
+
+	When("the cloud controller returns an error", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"errors": [{"detail": "You are not authorized to perform the requested action"}]}`))
+			}
+		})
+
+		It("returns its detail", func() {
+			Expect(err).To(MatchError("You are not authorized to perform the requested action"))
+		})
+	})
+
+	When("the response is an error without details", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		})
+
+		It("returns the status", func() {
+			Expect(err).To(MatchError("Request to /v3/apps?names=myapp failed: 502 Bad Gateway"))
+		})
+	})
+
+	When("the context is cancelled", func() {
+		It("does not make the request", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := v3Client.Get(ctx, logger, "/v3/apps")
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+			Expect(requests).To(HaveLen(1))
+		})
+	})
+
+	When("used by the client", func() {
+		var (
+			fakeCli  *cffakes.FakeCli
+			cfClient cf.Client
+			spaces   []cf.Space
+		)
+
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{
+					"pagination": {"next": null},
+					"resources": [{"guid": "app-guid", "name": "app", "relationships": {"space": {"data": {"guid": "space-guid"}}}}],
+					"included": {"spaces": [{"guid": "space-guid", "name": "space"}]}
+				}`))
+			}
+			fakeCli = new(cffakes.FakeCli)
+			fakeProcessInstanceIDFetcher := new(cffakes.FakeProcessInstanceIDFetcher)
+			fakeProcessInstanceIDFetcher.FetchReturns(map[int]string{0: "process-instance-0"}, nil)
+			cfClient = cf.NewClient(fakeCli, fakeProcessInstanceIDFetcher).WithCloudController(v3Client)
+		})
+
+		JustBeforeEach(func() {
+			spaces, err = cfClient.GetSpacesInOrg(context.Background(), logger, "org-guid")
+		})
+
+		It("lists the resources over HTTP rather than with cf curl", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spaces).To(HaveLen(1))
+			Expect(spaces[0].Applications[0].Name).To(Equal("app"))
+			Expect(requests[len(requests)-1].URL.RequestURI()).To(Equal("/v3/apps?organization_guids=org-guid&include=space"))
+			Expect(fakeCli.CliCommandWithoutTerminalOutputCallCount()).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,50 @@
+package cf
+
+import (
+	"fmt"
+	"path"
+)
+
+// SpaceFilter restricts the spaces a Client returns to those with one of the
+// given names or matching one of the given glob patterns. The zero value
+// matches every space.
+type SpaceFilter struct {
+	Names    []string
+	Patterns []string
+}
+
+func (f SpaceFilter) IsEmpty() bool {
+	return len(f.Names) == 0 && len(f.Patterns) == 0
+}
+
+// Validate checks that all the patterns are well formed, so that Matches can
+// ignore errors.
+func (f SpaceFilter) Validate() error {
+	for _, pattern := range f.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("Invalid space pattern %s: %s", pattern, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (f SpaceFilter) Matches(spaceName string) bool {
+	if f.IsEmpty() {
+		return true
+	}
+
+	for _, name := range f.Names {
+		if name == spaceName {
+			return true
+		}
+	}
+
+	for _, pattern := range f.Patterns {
+		if ok, _ := path.Match(pattern, spaceName); ok {
+			return true
+		}
+	}
+
+	return false
+}
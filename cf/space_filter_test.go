@@ -0,0 +1,40 @@
+package cf_test
+
+import (
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SpaceFilter", func() {
+	It("matches every space when empty", func() {
+		filter := cf.SpaceFilter{}
+		Expect(filter.IsEmpty()).To(BeTrue())
+		Expect(filter.Matches("anything")).To(BeTrue())
+	})
+
+	It("matches spaces by name", func() {
+		filter := cf.SpaceFilter{Names: []string{"dev", "prod"}}
+		Expect(filter.Matches("dev")).To(BeTrue())
+		Expect(filter.Matches("prod")).To(BeTrue())
+		Expect(filter.Matches("staging")).To(BeFalse())
+	})
+
+	It("matches spaces by pattern", func() {
+		filter := cf.SpaceFilter{Patterns: []string{"team-a-*"}}
+		Expect(filter.Matches("team-a-dev")).To(BeTrue())
+		Expect(filter.Matches("team-b-dev")).To(BeFalse())
+	})
+
+	It("matches spaces matching either a name or a pattern", func() {
+		filter := cf.SpaceFilter{Names: []string{"shared"}, Patterns: []string{"team-a-*"}}
+		Expect(filter.Matches("shared")).To(BeTrue())
+		Expect(filter.Matches("team-a-dev")).To(BeTrue())
+		Expect(filter.Matches("team-b-dev")).To(BeFalse())
+	})
+
+	It("rejects malformed patterns", func() {
+		Expect(cf.SpaceFilter{Patterns: []string{"team-*"}}.Validate()).To(Succeed())
+		Expect(cf.SpaceFilter{Patterns: []string{"team-["}}.Validate()).To(MatchError(ContainSubstring("Invalid space pattern team-[")))
+	})
+})
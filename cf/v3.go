@@ -0,0 +1,277 @@
+package cf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+)
+
+type v3Page struct {
+	Pagination struct {
+		Next *struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"pagination"`
+	Resources json.RawMessage `json:"resources"`
+	Included  struct {
+		Spaces []v3Space `json:"spaces"`
+	} `json:"included"`
+	Errors []struct {
+		Detail string `json:"detail"`
+	} `json:"errors"`
+}
+
+type v3Org struct {
+	Guid string `json:"guid"`
+	Name string `json:"name"`
+}
+
+type v3Space struct {
+	Guid string `json:"guid"`
+	Name string `json:"name"`
+}
+
+type v3App struct {
+	Guid          string `json:"guid"`
+	Name          string `json:"name"`
+	Relationships struct {
+		Space struct {
+			Data struct {
+				Guid string `json:"guid"`
+			} `json:"data"`
+		} `json:"space"`
+	} `json:"relationships"`
+}
+
+type v3Process struct {
+	Guid       string `json:"guid"`
+	Type       string `json:"type"`
+	Instances  int    `json:"instances"`
+	MemoryInMB int64  `json:"memory_in_mb"`
+}
+
+// getApp returns the app with the given name in the given space.
+func (c Client) getApp(ctx context.Context, logger lager.Logger, appName, spaceGUID string) (v3App, error) {
+	var apps []v3App
+	path := "/v3/apps?names=" + url.QueryEscape(appName) + "&space_guids=" + url.QueryEscape(spaceGUID)
+	err := c.listV3Resources(ctx, logger, path, func(page v3Page) error {
+		var pageApps []v3App
+		if err := json.Unmarshal(page.Resources, &pageApps); err != nil {
+			return err
+		}
+		apps = append(apps, pageApps...)
+		return nil
+	})
+	if err != nil {
+		return v3App{}, err
+	}
+
+	if len(apps) == 0 {
+		err = fmt.Errorf("App '%s' not found", appName)
+		logger.Error("app-not-found", err)
+		return v3App{}, err
+	}
+
+	return apps[0], nil
+}
+
+// getProcesses returns the web process of the app, followed by its other
+// processes that have instances, sorted by type. The metrics of the web
+// process are looked up by app guid, and those of the other processes by
+// process guid, as they do not share the source ID of the app. Instances
+// above the number the process is scaled to are left out, as they are
+// shutting down.
+func (c Client) getProcesses(ctx context.Context, logger lager.Logger, appGUID string) ([]Process, error) {
+	logger = logger.Session("cf-get-processes", lager.Data{"app-guid": appGUID})
+	logger.Info("start")
+	defer logger.Info("end")
+
+	var v3Processes []v3Process
+	err := c.listV3Resources(ctx, logger, "/v3/apps/"+url.PathEscape(appGUID)+"/processes", func(page v3Page) error {
+		var pageProcesses []v3Process
+		if err := json.Unmarshal(page.Resources, &pageProcesses); err != nil {
+			return err
+		}
+		v3Processes = append(v3Processes, pageProcesses...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	webProcess := v3Process{Type: WebProcessType}
+	var otherProcesses []v3Process
+	for _, v3Process := range v3Processes {
+		switch {
+		case v3Process.Type == WebProcessType:
+			webProcess = v3Process
+		case v3Process.Instances > 0:
+			otherProcesses = append(otherProcesses, v3Process)
+		}
+	}
+	sort.Slice(otherProcesses, func(i, j int) bool {
+		return otherProcesses[i].Type < otherProcesses[j].Type
+	})
+
+	var processes []Process
+	for _, v3Process := range append([]v3Process{webProcess}, otherProcesses...) {
+		sourceID := v3Process.Guid
+		if v3Process.Type == WebProcessType {
+			sourceID = appGUID
+		}
+
+		processInstanceIDs, err := c.processInstanceIDFetcher.Fetch(ctx, logger, sourceID)
+		if err != nil {
+			return nil, err
+		}
+
+		instances := map[int]Instance{}
+		for instanceID, processInstanceID := range processInstanceIDs {
+			if instanceID >= v3Process.Instances {
+				continue
+			}
+			instances[instanceID] = Instance{InstanceID: instanceID, ProcessInstanceID: processInstanceID}
+		}
+
+		processes = append(processes, Process{
+			Type:          v3Process.Type,
+			Guid:          sourceID,
+			Instances:     instances,
+			MemoryInMB:    v3Process.MemoryInMB,
+			InstanceCount: v3Process.Instances,
+		})
+	}
+
+	return processes, nil
+}
+
+// GetSpacesInOrg returns the spaces of any org the user can see that have
+// apps, with their apps, sorted by name. Unlike GetSpaces it does not depend
+// on the targeted org. The spaces are included in the list of apps, so the
+// spaces of the org are only listed to find the ones matching the space
+// filter.
+func (c Client) GetSpacesInOrg(ctx context.Context, logger lager.Logger, orgGUID string) ([]Space, error) {
+	logger = logger.Session("cf-get-spaces-in-org", lager.Data{"org-guid": orgGUID})
+	logger.Info("start")
+	defer logger.Info("end")
+
+	appsPath := "/v3/apps?organization_guids=" + url.QueryEscape(orgGUID)
+	if !c.spaceFilter.IsEmpty() {
+		spaceGUIDs, err := c.getMatchingSpaceGUIDs(ctx, logger, orgGUID)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(spaceGUIDs) == 0 {
+			return nil, nil
+		}
+		appsPath += "&space_guids=" + strings.Join(spaceGUIDs, ",")
+	}
+	appsPath += "&include=space"
+
+	spaceNames := map[string]string{}
+	appsPerSpace := map[string][]v3App{}
+	err := c.listV3Resources(ctx, logger, appsPath, func(page v3Page) error {
+		var apps []v3App
+		if err := json.Unmarshal(page.Resources, &apps); err != nil {
+			return err
+		}
+		for _, app := range apps {
+			spaceGUID := app.Relationships.Space.Data.Guid
+			appsPerSpace[spaceGUID] = append(appsPerSpace[spaceGUID], app)
+		}
+		for _, space := range page.Included.Spaces {
+			spaceNames[space.Guid] = space.Name
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var spaces []Space
+	for spaceGUID, apps := range appsPerSpace {
+		var applications []Application
+		for _, app := range apps {
+			applications = append(applications, Application{Guid: app.Guid, Name: app.Name, Space: spaceNames[spaceGUID]})
+		}
+
+		spaces = append(spaces, Space{Name: spaceNames[spaceGUID], Applications: applications})
+	}
+	sort.Slice(spaces, func(i, j int) bool {
+		return spaces[i].Name < spaces[j].Name
+	})
+
+	if err = c.addInstances(ctx, logger, spaces); err != nil {
+		return nil, err
+	}
+
+	return spaces, nil
+}
+
+func (c Client) getMatchingSpaceGUIDs(ctx context.Context, logger lager.Logger, orgGUID string) ([]string, error) {
+	var spaceGUIDs []string
+	err := c.listV3Resources(ctx, logger, "/v3/spaces?organization_guids="+url.QueryEscape(orgGUID), func(page v3Page) error {
+		var spaces []v3Space
+		if err := json.Unmarshal(page.Resources, &spaces); err != nil {
+			return err
+		}
+		for _, space := range spaces {
+			if c.spaceFilter.Matches(space.Name) {
+				spaceGUIDs = append(spaceGUIDs, space.Guid)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return spaceGUIDs, nil
+}
+
+// listV3Resources calls handlePage with every page of a v3 list endpoint,
+// following the next links.
+func (c Client) listV3Resources(ctx context.Context, logger lager.Logger, path string, handlePage func(v3Page) error) error {
+	for path != "" {
+		body, err := c.cloudController.Get(ctx, logger, path)
+		if err != nil {
+			return err
+		}
+
+		var page v3Page
+		if err = json.Unmarshal(body, &page); err != nil {
+			logger.Error("failed-to-parse-response", err, lager.Data{"path": path})
+			return fmt.Errorf("Failed to parse the response of %s: %s", path, err.Error())
+		}
+
+		if len(page.Errors) > 0 {
+			err = errors.New(page.Errors[0].Detail)
+			logger.Error("cloud-controller-error", err, lager.Data{"path": path})
+			return err
+		}
+
+		if err = handlePage(page); err != nil {
+			logger.Error("failed-to-parse-resources", err, lager.Data{"path": path})
+			return fmt.Errorf("Failed to parse the response of %s: %s", path, err.Error())
+		}
+
+		path = ""
+		if page.Pagination.Next != nil {
+			next, err := url.Parse(page.Pagination.Next.Href)
+			if err != nil {
+				logger.Error("failed-to-parse-next-page", err, lager.Data{"href": page.Pagination.Next.Href})
+				return err
+			}
+			path = next.RequestURI()
+		}
+	}
+
+	return nil
+}
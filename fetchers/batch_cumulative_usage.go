@@ -0,0 +1,78 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+	"code.cloudfoundry.org/lager"
+)
+
+type BatchCumulativeUsageFetcher struct {
+	logCacheClient LogCacheClient
+}
+
+// NewBatchCumulativeUsageFetcher creates a fetcher that gets the cumulative
+// usage of several apps with a single query, selecting all their source IDs
+// with a regular expression.
+func NewBatchCumulativeUsageFetcher(logCacheClient LogCacheClient) BatchCumulativeUsageFetcher {
+	return BatchCumulativeUsageFetcher{logCacheClient: logCacheClient}
+}
+
+// FetchAppsInstanceData returns the cumulative usage of the instances of the
+// given apps, keyed by app guid and then by instance ID.
+func (f BatchCumulativeUsageFetcher) FetchAppsInstanceData(ctx context.Context, logger lager.Logger, appsInstances map[string]map[int]cf.Instance) (map[string]map[int]interface{}, error) {
+	logger = logger.Session("batch-cumulative-usage-fetcher", lager.Data{"app-count": len(appsInstances)})
+	logger.Info("start")
+	defer logger.Info("end")
+
+	appsUsages := make(map[string]map[int]interface{})
+	if len(appsInstances) == 0 {
+		return appsUsages, nil
+	}
+
+	appGuids := make([]string, 0, len(appsInstances))
+	for appGuid := range appsInstances {
+		appGuids = append(appGuids, appGuid)
+		appsUsages[appGuid] = make(map[int]interface{})
+	}
+	sort.Strings(appGuids)
+
+	selector := strings.Join(appGuids, "|")
+	query := fmt.Sprintf(`absolute_usage{source_id=~"%s"} / absolute_entitlement{source_id=~"%s"}`, selector, selector)
+
+	promqlResult, err := f.logCacheClient.PromQL(ctx, query)
+	if err != nil {
+		logger.Error("promql-failed", err, lager.Data{"query": query})
+		return nil, wrapTimeout(ctx, err, "average usage", "")
+	}
+
+	for _, sample := range promqlResult.GetVector().GetSamples() {
+		appGuid := sample.GetMetric()["source_id"]
+		appInstances, ok := appsInstances[appGuid]
+		if !ok {
+			logger.Info("ignoring-unknown-source-id", lager.Data{"source-id": appGuid})
+			continue
+		}
+
+		instanceID, err := strconv.Atoi(sample.GetMetric()["instance_id"])
+		if err != nil {
+			logger.Info("ignoring-corrupt-instance-id", lager.Data{"instance-id": sample.GetMetric()["instance_id"]})
+			continue
+		}
+		processInstanceID := sample.GetMetric()["process_instance_id"]
+		if appInstances[instanceID].ProcessInstanceID != processInstanceID {
+			continue
+		}
+
+		appsUsages[appGuid][instanceID] = CumulativeInstanceData{
+			InstanceID: instanceID,
+			Usage:      sample.GetPoint().GetValue(),
+		}
+	}
+
+	return appsUsages, nil
+}
@@ -0,0 +1,167 @@
+package fetchers_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers/fetchersfakes"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Fetchers/BatchCumulativeUsage", func() {
+	var (
+		logCacheClient *fetchersfakes.FakeLogCacheClient
+		fetcher        fetchers.BatchCumulativeUsageFetcher
+		appsInstances  map[string]map[int]cf.Instance
+		appsUsages     map[string]map[int]interface{}
+		fetchErr       error
+	)
+
+	BeforeEach(func() {
+		logCacheClient = new(fetchersfakes.FakeLogCacheClient)
+		fetcher = fetchers.NewBatchCumulativeUsageFetcher(logCacheClient)
+
+		appsInstances = map[string]map[int]cf.Instance{
+			"foo": {
+				0: cf.Instance{InstanceID: 0, ProcessInstanceID: "foo-0"},
+				1: cf.Instance{InstanceID: 1, ProcessInstanceID: "foo-1"},
+			},
+			"bar": {
+				0: cf.Instance{InstanceID: 0, ProcessInstanceID: "bar-0"},
+			},
+		}
+
+		logCacheClient.PromQLReturns(queryResult(
+			appSample("foo", "0", "foo-0", point("1", 0.5)),
+			appSample("foo", "1", "foo-1", point("1", 1.5)),
+			appSample("bar", "0", "bar-0", point("1", 0.2)),
+		), nil)
+	})
+
+	JustBeforeEach(func() {
+		appsUsages, fetchErr = fetcher.FetchAppsInstanceData(context.Background(), logger, appsInstances)
+	})
+
+	It("logs start and end", func() {
+		Expect(logger).To(gbytes.Say("batch-cumulative-usage-fetcher.start"))
+		Expect(logger).To(gbytes.Say("batch-cumulative-usage-fetcher.end"))
+	})
+
+	It("queries the usage of all the apps at once", func() {
+		Expect(logCacheClient.PromQLCallCount()).To(Equal(1))
+		_, query, opts := logCacheClient.PromQLArgsForCall(0)
+		Expect(query).To(Equal(`absolute_usage{source_id=~"bar|foo"} / absolute_entitlement{source_id=~"bar|foo"}`))
+		Expect(opts).To(BeEmpty())
+	})
+
+	It("splits the usage by app and instance", func() {
+		Expect(fetchErr).NotTo(HaveOccurred())
+		Expect(appsUsages).To(Equal(map[string]map[int]interface{}{
+			"foo": {
+				0: fetchers.CumulativeInstanceData{InstanceID: 0, Usage: 0.5},
+				1: fetchers.CumulativeInstanceData{InstanceID: 1, Usage: 1.5},
+			},
+			"bar": {
+				0: fetchers.CumulativeInstanceData{InstanceID: 0, Usage: 0.2},
+			},
+		}))
+	})
+
+	When("an app has no usage", func() {
+		BeforeEach(func() {
+			logCacheClient.PromQLReturns(queryResult(
+				appSample("foo", "0", "foo-0", point("1", 0.5)),
+			), nil)
+		})
+
+		It("returns no instances for it", func() {
+			Expect(appsUsages).To(HaveKeyWithValue("bar", BeEmpty()))
+		})
+	})
+
+	When("there are no apps", func() {
+		BeforeEach(func() {
+			appsInstances = map[string]map[int]cf.Instance{}
+		})
+
+		It("does not query log cache", func() {
+			Expect(fetchErr).NotTo(HaveOccurred())
+			Expect(appsUsages).To(BeEmpty())
+			Expect(logCacheClient.PromQLCallCount()).To(BeZero())
+		})
+	})
+
+	When("a sample belongs to a previous instance", func() {
+		BeforeEach(func() {
+			logCacheClient.PromQLReturns(queryResult(
+				appSample("foo", "0", "foo-old", point("1", 0.5)),
+			), nil)
+		})
+
+		It("ignores it", func() {
+			Expect(appsUsages).To(HaveKeyWithValue("foo", BeEmpty()))
+		})
+	})
+
+	When("a sample has an unknown source id", func() {
+		BeforeEach(func() {
+			logCacheClient.PromQLReturns(queryResult(
+				appSample("baz", "0", "baz-0", point("1", 0.5)),
+			), nil)
+		})
+
+		It("ignores it", func() {
+			Expect(fetchErr).NotTo(HaveOccurred())
+			Expect(appsUsages).NotTo(HaveKey("baz"))
+		})
+
+		It("logs the problem", func() {
+			Expect(logger).To(SatisfyAll(
+				gbytes.Say("ignoring-unknown-source-id"),
+				gbytes.Say(`"source-id":"baz"`),
+			))
+		})
+	})
+
+	When("a sample has a corrupt instance id", func() {
+		BeforeEach(func() {
+			logCacheClient.PromQLReturns(queryResult(
+				appSample("foo", "dyado", "foo-0", point("1", 0.5)),
+			), nil)
+		})
+
+		It("ignores it", func() {
+			Expect(fetchErr).NotTo(HaveOccurred())
+			Expect(appsUsages).To(HaveKeyWithValue("foo", BeEmpty()))
+		})
+	})
+
+	When("fetching the usage fails", func() {
+		BeforeEach(func() {
+			logCacheClient.PromQLReturns(nil, errors.New("fetch-failed"))
+		})
+
+		It("returns the error", func() {
+			Expect(fetchErr).To(MatchError("fetch-failed"))
+		})
+
+		It("logs the error", func() {
+			Expect(logger).To(SatisfyAll(
+				gbytes.Say("promql-failed"),
+				gbytes.Say("fetch-failed"),
+			))
+		})
+	})
+})
+
+func appSample(sourceID, instanceID, procInstanceID string, point *logcache_v1.PromQL_Point) *logcache_v1.PromQL_Sample {
+	s := sample(instanceID, procInstanceID, point)
+	s.Metric["source_id"] = sourceID
+	return s
+}
@@ -0,0 +1,95 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+)
+
+// nanosecondsPerSecond converts the increase per second of the
+// absolute_usage and absolute_entitlement counters, which count nanoseconds
+// of CPU time, to cores.
+const nanosecondsPerSecond = 1e9
+
+// CPURateInstanceData is the CPU time an instance currently uses and is
+// entitled to per second, in cores.
+type CPURateInstanceData struct {
+	InstanceID  int
+	Usage       float64
+	Entitlement float64
+}
+
+type CPURateFetcher struct {
+	client LogCacheClient
+}
+
+func NewCPURateFetcher(client LogCacheClient) CPURateFetcher {
+	return CPURateFetcher{client: client}
+}
+
+// FetchInstanceData returns the instances that have both a usage and an
+// entitlement rate.
+func (f CPURateFetcher) FetchInstanceData(ctx context.Context, logger lager.Logger, appGUID string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+	logger = logger.Session("cpu-rate-fetcher", lager.Data{"app-guid": appGUID})
+	logger.Info("start")
+	defer logger.Info("end")
+
+	usages, err := f.fetchRates(ctx, logger, fmt.Sprintf(`irate(absolute_usage{source_id="%s"}[1m])`, appGUID), appInstances)
+	if err != nil {
+		return nil, wrapTimeout(ctx, err, "CPU rates", appGUID)
+	}
+
+	entitlements, err := f.fetchRates(ctx, logger, fmt.Sprintf(`irate(absolute_entitlement{source_id="%s"}[1m])`, appGUID), appInstances)
+	if err != nil {
+		return nil, wrapTimeout(ctx, err, "CPU rates", appGUID)
+	}
+
+	rates := map[int]interface{}{}
+	for instanceID, usage := range usages {
+		entitlement, ok := entitlements[instanceID]
+		if !ok {
+			continue
+		}
+
+		rates[instanceID] = CPURateInstanceData{
+			InstanceID:  instanceID,
+			Usage:       usage / nanosecondsPerSecond,
+			Entitlement: entitlement / nanosecondsPerSecond,
+		}
+	}
+
+	return rates, nil
+}
+
+func (f CPURateFetcher) fetchRates(ctx context.Context, logger lager.Logger, query string, appInstances map[int]cf.Instance) (map[int]float64, error) {
+	res, err := f.client.PromQL(ctx, query)
+	if err != nil {
+		logger.Error("promql-failed", err, lager.Data{"query": query})
+		return nil, err
+	}
+
+	return parseRates(logger, res, appInstances), nil
+}
+
+func parseRates(logger lager.Logger, res *logcache_v1.PromQL_InstantQueryResult, appInstances map[int]cf.Instance) map[int]float64 {
+	rates := map[int]float64{}
+	for _, sample := range res.GetVector().GetSamples() {
+		instanceID, err := strconv.Atoi(sample.GetMetric()["instance_id"])
+		if err != nil {
+			logger.Info("ignoring-corrupt-instance-id", lager.Data{"instance-id": sample.GetMetric()["instance_id"]})
+			continue
+		}
+
+		if sample.GetMetric()["process_instance_id"] != appInstances[instanceID].ProcessInstanceID {
+			continue
+		}
+
+		rates[instanceID] = sample.GetPoint().GetValue()
+	}
+
+	return rates
+}
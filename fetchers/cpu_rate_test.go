@@ -0,0 +1,104 @@
+package fetchers_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers/fetchersfakes"
+	logcache "code.cloudfoundry.org/log-cache/pkg/client"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Fetchers/CPURate", func() {
+	var (
+		logCacheClient *fetchersfakes.FakeLogCacheClient
+		fetcher        fetchers.CPURateFetcher
+		appInstances   map[int]cf.Instance
+		rates          map[int]interface{}
+		fetchErr       error
+	)
+
+	BeforeEach(func() {
+		logCacheClient = new(fetchersfakes.FakeLogCacheClient)
+		fetcher = fetchers.NewCPURateFetcher(logCacheClient)
+
+		appInstances = map[int]cf.Instance{
+			0: {InstanceID: 0, ProcessInstanceID: "abc"},
+			1: {InstanceID: 1, ProcessInstanceID: "def"},
+		}
+
+		logCacheClient.PromQLStub = func(ctx context.Context, query string, opts ...logcache.PromQLOption) (*logcache_v1.PromQL_InstantQueryResult, error) {
+			if strings.Contains(query, "absolute_usage") {
+				return queryResult(
+					sample("0", "abc", point("1", 125e6)),
+					sample("1", "def", point("1", 1.5e9)),
+				), nil
+			}
+			return queryResult(
+				sample("0", "abc", point("1", 250e6)),
+				sample("1", "def", point("1", 250e6)),
+			), nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		rates, fetchErr = fetcher.FetchInstanceData(context.Background(), logger, "foo", appInstances)
+	})
+
+	It("logs start and end", func() {
+		Expect(logger).To(gbytes.Say("cpu-rate-fetcher.start"))
+		Expect(logger).To(gbytes.Say("cpu-rate-fetcher.end"))
+	})
+
+	It("queries the rate of the usage and entitlement counters", func() {
+		Expect(logCacheClient.PromQLCallCount()).To(Equal(2))
+		_, query, _ := logCacheClient.PromQLArgsForCall(0)
+		Expect(query).To(Equal(`irate(absolute_usage{source_id="foo"}[1m])`))
+		_, query, _ = logCacheClient.PromQLArgsForCall(1)
+		Expect(query).To(Equal(`irate(absolute_entitlement{source_id="foo"}[1m])`))
+	})
+
+	It("returns the rates in cores", func() {
+		Expect(fetchErr).NotTo(HaveOccurred())
+		Expect(rates).To(Equal(map[int]interface{}{
+			0: fetchers.CPURateInstanceData{InstanceID: 0, Usage: 0.125, Entitlement: 0.25},
+			1: fetchers.CPURateInstanceData{InstanceID: 1, Usage: 1.5, Entitlement: 0.25},
+		}))
+	})
+
+	When("the samples of an instance belong to a previous instance", func() {
+		BeforeEach(func() {
+			appInstances[1] = cf.Instance{InstanceID: 1, ProcessInstanceID: "new"}
+		})
+
+		It("leaves it out", func() {
+			Expect(rates).To(HaveLen(1))
+			Expect(rates).To(HaveKey(0))
+		})
+	})
+
+	When("fetching a rate fails", func() {
+		BeforeEach(func() {
+			logCacheClient.PromQLStub = nil
+			logCacheClient.PromQLReturns(nil, errors.New("fetch-failed"))
+		})
+
+		It("returns the error", func() {
+			Expect(fetchErr).To(MatchError("fetch-failed"))
+		})
+
+		It("logs the error", func() {
+			Expect(logger).To(SatisfyAll(
+				gbytes.Say("promql-failed"),
+				gbytes.Say("fetch-failed"),
+			))
+		})
+	})
+})
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
 	"code.cloudfoundry.org/lager"
+	logcache "code.cloudfoundry.org/log-cache/pkg/client"
 )
 
 type CumulativeInstanceData struct {
@@ -16,22 +18,39 @@ type CumulativeInstanceData struct {
 
 type CumulativeUsageFetcher struct {
 	logCacheClient LogCacheClient
+	since          time.Time
+	until          time.Time
 }
 
 func NewCumulativeUsageFetcher(logCacheClient LogCacheClient) CumulativeUsageFetcher {
 	return CumulativeUsageFetcher{logCacheClient: logCacheClient}
 }
 
-func (f CumulativeUsageFetcher) FetchInstanceData(logger lager.Logger, appGuid string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+// NewCumulativeUsageFetcherWithWindow creates a fetcher that averages the
+// usage between since and until, rather than since each instance started. The
+// average is the ratio of the increase of the usage and entitlement counters
+// over that window. A zero since behaves like NewCumulativeUsageFetcher.
+func NewCumulativeUsageFetcherWithWindow(logCacheClient LogCacheClient, since, until time.Time) CumulativeUsageFetcher {
+	return CumulativeUsageFetcher{logCacheClient: logCacheClient, since: since, until: until}
+}
+
+func (f CumulativeUsageFetcher) FetchInstanceData(ctx context.Context, logger lager.Logger, appGuid string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
 	logger = logger.Session("cumulative-usage-fetcher", lager.Data{"app-guid": appGuid})
 	logger.Info("start")
 	defer logger.Info("end")
 
 	query := fmt.Sprintf(`absolute_usage{source_id="%s"} / absolute_entitlement{source_id="%s"}`, appGuid, appGuid)
-	promqlResult, err := f.logCacheClient.PromQL(context.Background(), query)
+	var opts []logcache.PromQLOption
+	if !f.since.IsZero() {
+		window := fmt.Sprintf("%ds", int64(f.until.Sub(f.since).Seconds()))
+		query = fmt.Sprintf(`delta(absolute_usage{source_id="%s"}[%s]) / delta(absolute_entitlement{source_id="%s"}[%s])`, appGuid, window, appGuid, window)
+		opts = append(opts, logcache.WithPromQLTime(f.until))
+	}
+
+	promqlResult, err := f.logCacheClient.PromQL(ctx, query, opts...)
 	if err != nil {
 		logger.Error("promql-failed", err, lager.Data{"query": query})
-		return nil, err
+		return nil, wrapTimeout(ctx, err, "average usage", appGuid)
 	}
 
 	instanceUsages := make(map[int]interface{})
@@ -1,7 +1,9 @@
 package fetchers_test
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -35,7 +37,7 @@ var _ = Describe("Fetchers/CumulativeUsage", func() {
 	})
 
 	JustBeforeEach(func() {
-		cumulativeUsage, fetchErr = fetcher.FetchInstanceData(logger, appGuid, appInstances)
+		cumulativeUsage, fetchErr = fetcher.FetchInstanceData(context.Background(), logger, appGuid, appInstances)
 	})
 
 	It("logs start and end", func() {
@@ -43,6 +45,29 @@ var _ = Describe("Fetchers/CumulativeUsage", func() {
 		Expect(logger).To(gbytes.Say("cumulative-usage-fetcher.end"))
 	})
 
+	It("queries the usage since the instances started", func() {
+		Expect(logCacheClient.PromQLCallCount()).To(Equal(1))
+		_, query, opts := logCacheClient.PromQLArgsForCall(0)
+		Expect(query).To(Equal(`absolute_usage{source_id="foo"} / absolute_entitlement{source_id="foo"}`))
+		Expect(opts).To(BeEmpty())
+	})
+
+	When("the fetcher has a time window", func() {
+		var until time.Time
+
+		BeforeEach(func() {
+			until = time.Unix(1500000000, 0)
+			fetcher = fetchers.NewCumulativeUsageFetcherWithWindow(logCacheClient, until.Add(-6*time.Hour), until)
+		})
+
+		It("queries the increase of usage and entitlement over the window", func() {
+			Expect(logCacheClient.PromQLCallCount()).To(Equal(1))
+			_, query, opts := logCacheClient.PromQLArgsForCall(0)
+			Expect(query).To(Equal(`delta(absolute_usage{source_id="foo"}[21600s]) / delta(absolute_entitlement{source_id="foo"}[21600s])`))
+			Expect(promQLParams(opts...).Get("time")).To(Equal("1500000000.000"))
+		})
+	})
+
 	When("fetched data has corrupt instance id", func() {
 		BeforeEach(func() {
 			logCacheClient.PromQLReturns(queryResult(
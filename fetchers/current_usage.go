@@ -18,7 +18,7 @@ type CurrentInstanceData struct {
 
 //go:generate counterfeiter . Fetcher
 type Fetcher interface {
-	FetchInstanceData(logger lager.Logger, appGUID string, appInstances map[int]cf.Instance) (map[int]interface{}, error)
+	FetchInstanceData(ctx context.Context, logger lager.Logger, appGUID string, appInstances map[int]cf.Instance) (map[int]interface{}, error)
 }
 
 type CurrentUsageFetcher struct {
@@ -40,16 +40,16 @@ func NewCurrentUsageFetcherWithFallbackFetcher(client LogCacheClient, fallbackFe
 	}
 }
 
-func (f CurrentUsageFetcher) FetchInstanceData(logger lager.Logger, appGUID string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+func (f CurrentUsageFetcher) FetchInstanceData(ctx context.Context, logger lager.Logger, appGUID string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
 	logger = logger.Session("current-usage-fetcher", lager.Data{"app-guid": appGUID})
 	logger.Info("start")
 	defer logger.Info("end")
 
 	query := fmt.Sprintf(`idelta(absolute_usage{source_id="%s"}[1m]) / idelta(absolute_entitlement{source_id="%s"}[1m])`, appGUID, appGUID)
-	res, err := f.client.PromQL(context.Background(), query)
+	res, err := f.client.PromQL(ctx, query)
 	if err != nil {
 		logger.Error("promql-failed", err, lager.Data{"query": query})
-		return nil, err
+		return nil, wrapTimeout(ctx, err, "current usage", appGUID)
 	}
 
 	currentUsage := parseCurrentUsage(logger, res, appInstances)
@@ -59,7 +59,7 @@ func (f CurrentUsageFetcher) FetchInstanceData(logger lager.Logger, appGUID stri
 
 	logger.Info("falling-back-to-cumulative-fetcher")
 
-	cumulativeResult, err := f.fallbackFetcher.FetchInstanceData(logger, appGUID, appInstances)
+	cumulativeResult, err := f.fallbackFetcher.FetchInstanceData(ctx, logger, appGUID, appInstances)
 	if err != nil {
 		logger.Info("fallback-fetcher-failed")
 		return nil, err
@@ -1,6 +1,7 @@
 package fetchers_test
 
 import (
+	"context"
 	"errors"
 
 	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
@@ -38,7 +39,7 @@ var _ = Describe("CurrentUsage", func() {
 	})
 
 	JustBeforeEach(func() {
-		currentUsage, fetchErr = fetcher.FetchInstanceData(logger, appGuid, appInstances)
+		currentUsage, fetchErr = fetcher.FetchInstanceData(context.Background(), logger, appGuid, appInstances)
 	})
 
 	It("logs start and end", func() {
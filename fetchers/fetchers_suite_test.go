@@ -1,10 +1,12 @@
 package fetchers_test
 
 import (
+	"net/url"
 	"testing"
 
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
+	logcache "code.cloudfoundry.org/log-cache/pkg/client"
 	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -69,6 +71,22 @@ func sample(instanceID, procInstanceID string, point *logcache_v1.PromQL_Point)
 	}
 }
 
+func promQLParams(opts ...logcache.PromQLOption) url.Values {
+	params := url.Values{}
+	for _, opt := range opts {
+		opt(&url.URL{}, params)
+	}
+	return params
+}
+
+func readParams(opts ...logcache.ReadOption) url.Values {
+	params := url.Values{}
+	for _, opt := range opts {
+		opt(&url.URL{}, params)
+	}
+	return params
+}
+
 func point(time string, value float64) *logcache_v1.PromQL_Point {
 	return &logcache_v1.PromQL_Point{Time: time, Value: value}
 }
@@ -21,30 +21,75 @@ type LastSpikeInstanceData struct {
 type LastSpikeFetcher struct {
 	client LogCacheClient
 	since  time.Time
+	limit  int
+}
+
+func NewLastSpikeFetcherWithLimit(client LogCacheClient, since time.Time, limit int) *LastSpikeFetcher {
+	return &LastSpikeFetcher{client: client, since: since, limit: limit}
 }
 
 func NewLastSpikeFetcher(client LogCacheClient, since time.Time) *LastSpikeFetcher {
-	return &LastSpikeFetcher{client: client, since: since}
+	return NewLastSpikeFetcherWithLimit(client, since, 1000)
 }
 
-func (f LastSpikeFetcher) FetchInstanceData(logger lager.Logger, appGUID string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+func (f LastSpikeFetcher) FetchInstanceData(ctx context.Context, logger lager.Logger, appGUID string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
 	logger = logger.Session("last-spike-fetcher", lager.Data{"app-guid": appGUID})
 	logger.Info("start")
 	defer logger.Info("end")
 
-	res, err := f.client.Read(context.Background(), appGUID, f.since,
-		logcache.WithEnvelopeTypes(logcache_v1.EnvelopeType_GAUGE),
-		logcache.WithDescending(),
-		logcache.WithNameFilter("spike"),
-	)
+	// The spikes of all the instances are read together, newest first, so the
+	// last spike of an instance which spiked less recently than the others
+	// may only be on a later page. Up to maxReadTries pages are read to find
+	// it; if that is not enough, the instances whose last spike is before the
+	// oldest envelope read are reported as not having spiked.
+	res, truncatedBefore, err := readSpikeEnvelopes(ctx, logger, f.client, appGUID, f.since, f.limit)
 	if err != nil {
-		logger.Error("logcache-client-read-failed", err)
 		return nil, err
 	}
+	if !truncatedBefore.IsZero() {
+		logger.Info("spikes-truncated", lager.Data{"before": truncatedBefore})
+	}
 
 	return parseLastSpike(logger, res, appInstances)
 }
 
+// readSpikeEnvelopes reads the spike gauges emitted since the given time,
+// newest first. Like ProcessInstanceIDFetcher.Fetch, it pages backwards
+// through the results by moving the end of the range to the timestamp of the
+// oldest envelope read, until log-cache returns fewer than limit envelopes or
+// maxReadTries is reached. In the latter case, it also returns the time of the
+// oldest envelope read, before which spikes may be missing.
+func readSpikeEnvelopes(ctx context.Context, logger lager.Logger, client LogCacheClient, appGUID string, since time.Time, limit int) ([]*loggregator_v2.Envelope, time.Time, error) {
+	end := time.Now()
+
+	var envelopes []*loggregator_v2.Envelope
+	for i := 0; i < maxReadTries; i++ {
+		page, err := client.Read(ctx, appGUID, since,
+			logcache.WithEnvelopeTypes(logcache_v1.EnvelopeType_GAUGE),
+			logcache.WithDescending(),
+			logcache.WithNameFilter("spike"),
+			logcache.WithEndTime(end),
+			logcache.WithLimit(limit),
+		)
+		if err != nil {
+			logger.Error("logcache-client-read-failed", err)
+			return nil, time.Time{}, wrapTimeout(ctx, err, "spikes", appGUID)
+		}
+
+		envelopes = append(envelopes, page...)
+
+		if len(page) < limit {
+			return envelopes, time.Time{}, nil
+		}
+
+		logger.Info("more-metrics-to-fetch", lager.Data{"iteration": i, "max-iterations": maxReadTries, "page-size": limit})
+		end = time.Unix(0, page[len(page)-1].Timestamp)
+	}
+
+	logger.Info("max-read-tries-reached", lager.Data{"max-iterations": maxReadTries, "truncated-before": end})
+	return envelopes, end, nil
+}
+
 func parseLastSpike(logger lager.Logger, res []*loggregator_v2.Envelope, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
 	logger = logger.Session("parse-last-spike")
 	logger.Info("start")
@@ -52,48 +97,60 @@ func parseLastSpike(logger lager.Logger, res []*loggregator_v2.Envelope, appInst
 
 	lastSpikePerInstance := make(map[int]interface{})
 	for _, envelope := range res {
-		instanceID, err := strconv.Atoi(envelope.InstanceId)
-		if err != nil {
-			logger.Info("ignoring-corrupt-instance-id", lager.Data{"instance-id": envelope.InstanceId, "envelope": envelope})
-			continue
-		}
-
-		if _, alreadySet := lastSpikePerInstance[instanceID]; alreadySet {
-			continue
-		}
-
-		envelopeGauge, ok := envelope.Message.(*loggregator_v2.Envelope_Gauge)
+		spike, ok := parseSpikeEnvelope(logger, envelope, appInstances)
 		if !ok {
-			logger.Info("ignoring-non-gauge-message", lager.Data{"gauge-message": envelope.Message})
 			continue
 		}
 
-		processInstanceID := envelope.Tags["process_instance_id"]
-		if appInstances[instanceID].ProcessInstanceID != processInstanceID {
+		if _, alreadySet := lastSpikePerInstance[spike.InstanceID]; alreadySet {
 			continue
 		}
 
-		if envelopeGauge.Gauge != nil && envelopeGauge.Gauge.Metrics != nil {
-			gaugeValues := envelopeGauge.Gauge.Metrics
-			spikeStartValue, ok := gaugeValues["spike_start"]
-			if !ok {
-				continue
-			}
-			spikeStart := time.Unix(int64(spikeStartValue.Value), 0)
-
-			spikeEndValue, ok := gaugeValues["spike_end"]
-			if !ok {
-				continue
-			}
-			spikeEnd := time.Unix(int64(spikeEndValue.Value), 0)
-
-			lastSpikePerInstance[instanceID] = LastSpikeInstanceData{
-				InstanceID: instanceID,
-				From:       spikeStart,
-				To:         spikeEnd,
-			}
-		}
+		lastSpikePerInstance[spike.InstanceID] = spike
 	}
 
 	return lastSpikePerInstance, nil
 }
+
+// parseSpikeEnvelope extracts the spike from a spike gauge envelope. It
+// returns false when the envelope is not a valid spike of one of the given app
+// instances.
+func parseSpikeEnvelope(logger lager.Logger, envelope *loggregator_v2.Envelope, appInstances map[int]cf.Instance) (LastSpikeInstanceData, bool) {
+	instanceID, err := strconv.Atoi(envelope.InstanceId)
+	if err != nil {
+		logger.Info("ignoring-corrupt-instance-id", lager.Data{"instance-id": envelope.InstanceId, "envelope": envelope})
+		return LastSpikeInstanceData{}, false
+	}
+
+	envelopeGauge, ok := envelope.Message.(*loggregator_v2.Envelope_Gauge)
+	if !ok {
+		logger.Info("ignoring-non-gauge-message", lager.Data{"gauge-message": envelope.Message})
+		return LastSpikeInstanceData{}, false
+	}
+
+	processInstanceID := envelope.Tags["process_instance_id"]
+	if appInstances[instanceID].ProcessInstanceID != processInstanceID {
+		return LastSpikeInstanceData{}, false
+	}
+
+	if envelopeGauge.Gauge == nil || envelopeGauge.Gauge.Metrics == nil {
+		return LastSpikeInstanceData{}, false
+	}
+
+	gaugeValues := envelopeGauge.Gauge.Metrics
+	spikeStartValue, ok := gaugeValues["spike_start"]
+	if !ok {
+		return LastSpikeInstanceData{}, false
+	}
+
+	spikeEndValue, ok := gaugeValues["spike_end"]
+	if !ok {
+		return LastSpikeInstanceData{}, false
+	}
+
+	return LastSpikeInstanceData{
+		InstanceID: instanceID,
+		From:       time.Unix(int64(spikeStartValue.Value), 0),
+		To:         time.Unix(int64(spikeEndValue.Value), 0),
+	}, true
+}
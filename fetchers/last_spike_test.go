@@ -1,6 +1,7 @@
 package fetchers_test
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -36,7 +37,7 @@ var _ = Describe("LastSpikeFetcher", func() {
 	})
 
 	JustBeforeEach(func() {
-		spikes, fetchErr = fetcher.FetchInstanceData(logger, appGuid, appInstances)
+		spikes, fetchErr = fetcher.FetchInstanceData(context.Background(), logger, appGuid, appInstances)
 	})
 
 	When("fetching the list of data points from log-cache fails", func() {
@@ -36,7 +36,7 @@ func NewProcessInstanceIDFetcher(client LogCacheClient) ProcessInstanceIDFetcher
 // re-read. As soon as fewer than 1000 results are returned we stop, as we have
 // exhausted the range. We also apply a 10 iteration sanity check to avoid
 // looping forever.
-func (f ProcessInstanceIDFetcher) Fetch(logger lager.Logger, appGUID string) (map[int]string, error) {
+func (f ProcessInstanceIDFetcher) Fetch(ctx context.Context, logger lager.Logger, appGUID string) (map[int]string, error) {
 	logger = logger.Session("process-instance-id-fetch", lager.Data{"app-guid": appGUID})
 	logger.Info("start")
 	defer logger.Info("end")
@@ -47,7 +47,7 @@ func (f ProcessInstanceIDFetcher) Fetch(logger lager.Logger, appGUID string) (ma
 	processInstanceIDs := map[int]string{}
 
 	for i := 0; i < maxReadTries; i++ {
-		envelopes, err := f.client.Read(context.Background(), appGUID, start,
+		envelopes, err := f.client.Read(ctx, appGUID, start,
 			logcache.WithDescending(),
 			logcache.WithEnvelopeTypes(logcache_v1.EnvelopeType_GAUGE),
 			logcache.WithNameFilter("absolute_entitlement"),
@@ -57,7 +57,7 @@ func (f ProcessInstanceIDFetcher) Fetch(logger lager.Logger, appGUID string) (ma
 
 		if err != nil {
 			logger.Error("log-cache-read-failed", err)
-			return nil, err
+			return nil, wrapTimeout(ctx, err, "process instance IDs", appGUID)
 		}
 
 		for _, envelope := range envelopes {
@@ -1,6 +1,7 @@
 package fetchers_test
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -26,7 +27,7 @@ var _ = Describe("Process Instance ID Fetcher", func() {
 	})
 
 	JustBeforeEach(func() {
-		processInstanceIDs, err = fetcher.Fetch(logger, "the-app")
+		processInstanceIDs, err = fetcher.Fetch(context.Background(), logger, "the-app")
 	})
 
 	When("the first query returns #limit envelopes but doesn't return enough data to build the list of instances", func() {
@@ -0,0 +1,39 @@
+package fetchers
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	logcache "code.cloudfoundry.org/log-cache/pkg/client"
+)
+
+type RetentionFetcher struct {
+	client LogCacheClient
+}
+
+func NewRetentionFetcher(client LogCacheClient) RetentionFetcher {
+	return RetentionFetcher{client: client}
+}
+
+// FetchOldestTimestamp returns the time of the oldest envelope log-cache holds
+// for the app since the given time. When log-cache retains less history than
+// requested, this is later than since. It returns the zero time when there
+// are no envelopes at all.
+func (f RetentionFetcher) FetchOldestTimestamp(ctx context.Context, logger lager.Logger, appGUID string, since time.Time) (time.Time, error) {
+	logger = logger.Session("retention-fetcher", lager.Data{"app-guid": appGUID})
+	logger.Info("start")
+	defer logger.Info("end")
+
+	envelopes, err := f.client.Read(ctx, appGUID, since, logcache.WithLimit(1))
+	if err != nil {
+		logger.Error("logcache-client-read-failed", err)
+		return time.Time{}, wrapTimeout(ctx, err, "retention", appGUID)
+	}
+
+	if len(envelopes) == 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(0, envelopes[0].Timestamp), nil
+}
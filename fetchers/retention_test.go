@@ -0,0 +1,74 @@
+package fetchers_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers/fetchersfakes"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+var _ = Describe("RetentionFetcher", func() {
+	var (
+		logCacheClient *fetchersfakes.FakeLogCacheClient
+		fetcher        fetchers.RetentionFetcher
+		since          time.Time
+		oldest         time.Time
+		fetchErr       error
+	)
+
+	BeforeEach(func() {
+		since = time.Unix(100, 0)
+		logCacheClient = new(fetchersfakes.FakeLogCacheClient)
+		fetcher = fetchers.NewRetentionFetcher(logCacheClient)
+
+		logCacheClient.ReadReturns([]*loggregator_v2.Envelope{
+			{Timestamp: time.Unix(200, 0).UnixNano()},
+		}, nil)
+	})
+
+	JustBeforeEach(func() {
+		oldest, fetchErr = fetcher.FetchOldestTimestamp(context.Background(), logger, "foo", since)
+	})
+
+	It("reads the oldest envelope since the given time", func() {
+		Expect(logCacheClient.ReadCallCount()).To(Equal(1))
+		_, sourceID, start, opts := logCacheClient.ReadArgsForCall(0)
+		Expect(sourceID).To(Equal("foo"))
+		Expect(start).To(Equal(since))
+		params := readParams(opts...)
+		Expect(params.Get("limit")).To(Equal("1"))
+		Expect(params.Get("descending")).To(BeEmpty())
+	})
+
+	It("returns the time of the oldest envelope", func() {
+		Expect(fetchErr).NotTo(HaveOccurred())
+		Expect(oldest).To(Equal(time.Unix(200, 0)))
+	})
+
+	When("there are no envelopes", func() {
+		BeforeEach(func() {
+			logCacheClient.ReadReturns(nil, nil)
+		})
+
+		It("returns the zero time", func() {
+			Expect(fetchErr).NotTo(HaveOccurred())
+			Expect(oldest).To(BeZero())
+		})
+	})
+
+	When("reading from log-cache fails", func() {
+		BeforeEach(func() {
+			logCacheClient.ReadReturns(nil, errors.New("boo"))
+		})
+
+		It("returns the error", func() {
+			Expect(fetchErr).To(MatchError("boo"))
+		})
+	})
+})
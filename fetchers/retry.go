@@ -0,0 +1,137 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/httpclient"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/lager"
+	logcache "code.cloudfoundry.org/log-cache/pkg/client"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+)
+
+const (
+	defaultInitialBackoff = 250 * time.Millisecond
+	defaultMaxBackoff     = 5 * time.Second
+)
+
+// RetryingLogCacheClient retries requests to log-cache that fail with a
+// retryable status or a network error, so that a single 502 from log-cache
+// does not fail a whole report. Between attempts it waits for as long as the
+// Retry-After header of the response asks, or else for a jittered
+// exponential backoff. Statuses are only known when the HTTP client of the
+// log-cache client is wrapped in an httpclient.StatusClient.
+type RetryingLogCacheClient struct {
+	client         LogCacheClient
+	logger         lager.Logger
+	retries        int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+func NewRetryingLogCacheClient(client LogCacheClient, logger lager.Logger, retries int) RetryingLogCacheClient {
+	return RetryingLogCacheClient{
+		client:         client,
+		logger:         logger.Session("log-cache-retry"),
+		retries:        retries,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+	}
+}
+
+// WithBackoff changes the backoff before the first retry, which doubles
+// after each retry up to max.
+func (c RetryingLogCacheClient) WithBackoff(initial, max time.Duration) RetryingLogCacheClient {
+	c.initialBackoff = initial
+	c.maxBackoff = max
+	return c
+}
+
+func (c RetryingLogCacheClient) Read(ctx context.Context, sourceID string, start time.Time, opts ...logcache.ReadOption) ([]*loggregator_v2.Envelope, error) {
+	var envelopes []*loggregator_v2.Envelope
+	err := c.retry(ctx, lager.Data{"source-id": sourceID}, func() error {
+		var err error
+		envelopes, err = c.client.Read(ctx, sourceID, start, opts...)
+		return err
+	})
+	return envelopes, err
+}
+
+func (c RetryingLogCacheClient) PromQL(ctx context.Context, query string, opts ...logcache.PromQLOption) (*logcache_v1.PromQL_InstantQueryResult, error) {
+	var res *logcache_v1.PromQL_InstantQueryResult
+	err := c.retry(ctx, lager.Data{"query": query}, func() error {
+		var err error
+		res, err = c.client.PromQL(ctx, query, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c RetryingLogCacheClient) PromQLRange(ctx context.Context, query string, opts ...logcache.PromQLOption) (*logcache_v1.PromQL_RangeQueryResult, error) {
+	var res *logcache_v1.PromQL_RangeQueryResult
+	err := c.retry(ctx, lager.Data{"query": query}, func() error {
+		var err error
+		res, err = c.client.PromQLRange(ctx, query, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c RetryingLogCacheClient) retry(ctx context.Context, data lager.Data, attempt func() error) error {
+	backoff := c.initialBackoff
+	for retry := 1; ; retry++ {
+		err := attempt()
+		if err == nil || retry > c.retries || ctx.Err() != nil || !isRetryable(err) {
+			return err
+		}
+
+		delay := jitter(backoff)
+		var statusErr httpclient.StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+			delay = statusErr.RetryAfter
+		}
+
+		data["retry"] = retry
+		data["max-retries"] = c.retries
+		data["delay"] = delay.String()
+		data["error"] = err.Error()
+		c.logger.Debug("retrying", data)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		backoff *= 2
+		if backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+// isRetryable tells whether the request may succeed if it is made again.
+// Timeouts are not retried, as the deadline of the request is spent.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var statusErr httpclient.StatusError
+	if errors.As(err, &statusErr) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// jitter picks a delay between half the backoff and the whole of it, so that
+// concurrent fetchers do not all retry at the same time.
+func jitter(backoff time.Duration) time.Duration {
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
@@ -0,0 +1,168 @@
+package fetchers_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers/fetchersfakes"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/httpclient"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("RetryingLogCacheClient", func() {
+	var (
+		fakeClient  *fetchersfakes.FakeLogCacheClient
+		logger      *lagertest.TestLogger
+		client      fetchers.RetryingLogCacheClient
+		ctx         context.Context
+		cancel      context.CancelFunc
+		unavailable error
+		result      *logcache_v1.PromQL_InstantQueryResult
+		err         error
+	)
+
+	BeforeEach(func() {
+		fakeClient = new(fetchersfakes.FakeLogCacheClient)
+		logger = lagertest.NewTestLogger("retry-test")
+		client = fetchers.NewRetryingLogCacheClient(fakeClient, logger, 3).WithBackoff(time.Millisecond, 4*time.Millisecond)
+		ctx = context.Background()
+		unavailable = httpclient.StatusError{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+
+		fakeClient.PromQLReturnsOnCall(0, nil, unavailable)
+		fakeClient.PromQLReturnsOnCall(1, nil, unavailable)
+		fakeClient.PromQLReturnsOnCall(2, queryResult(), nil)
+	})
+
+	JustBeforeEach(func() {
+		result, err = client.PromQL(ctx, "query")
+	})
+
+	It("retries requests that fail with a retryable status", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(queryResult()))
+		Expect(fakeClient.PromQLCallCount()).To(Equal(3))
+		_, query, _ := fakeClient.PromQLArgsForCall(2)
+		Expect(query).To(Equal("query"))
+	})
+
+	It("logs every retry at debug level", func() {
+		Expect(logger.LogMessages()).To(Equal([]string{
+			"retry-test.log-cache-retry.retrying",
+			"retry-test.log-cache-retry.retrying",
+		}))
+		Expect(logger.Logs()[0].LogLevel).To(Equal(lager.DEBUG))
+		Expect(logger.Logs()[1].Data).To(HaveKeyWithValue("retry", BeEquivalentTo(2)))
+		Expect(logger).To(gbytes.Say("503 Service Unavailable"))
+	})
+
+	When("the requests keep failing", func() {
+		BeforeEach(func() {
+			fakeClient.PromQLReturnsOnCall(2, nil, unavailable)
+			fakeClient.PromQLReturnsOnCall(3, nil, unavailable)
+		})
+
+		It("gives up after the given number of retries", func() {
+			Expect(err).To(MatchError(unavailable))
+			Expect(fakeClient.PromQLCallCount()).To(Equal(4))
+		})
+	})
+
+	When("the server asks to retry after a while", func() {
+		BeforeEach(func() {
+			client = client.WithBackoff(time.Hour, time.Hour)
+			fakeClient.PromQLReturnsOnCall(0, nil, httpclient.StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Millisecond})
+			fakeClient.PromQLReturnsOnCall(1, queryResult(), nil)
+		})
+
+		It("waits for that long instead of backing off", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeClient.PromQLCallCount()).To(Equal(2))
+		})
+	})
+
+	When("a network error occurs", func() {
+		BeforeEach(func() {
+			fakeClient.PromQLReturnsOnCall(0, nil, &net.OpError{Op: "dial", Err: errors.New("connection refused")})
+			fakeClient.PromQLReturnsOnCall(1, queryResult(), nil)
+		})
+
+		It("retries the request", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeClient.PromQLCallCount()).To(Equal(2))
+		})
+	})
+
+	When("the request fails otherwise", func() {
+		BeforeEach(func() {
+			fakeClient.PromQLReturnsOnCall(0, nil, errors.New("unexpected status code 404"))
+		})
+
+		It("does not retry it", func() {
+			Expect(err).To(MatchError("unexpected status code 404"))
+			Expect(fakeClient.PromQLCallCount()).To(Equal(1))
+		})
+	})
+
+	When("the request times out", func() {
+		BeforeEach(func() {
+			fakeClient.PromQLReturnsOnCall(0, nil, context.DeadlineExceeded)
+		})
+
+		It("does not retry it", func() {
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(fakeClient.PromQLCallCount()).To(Equal(1))
+		})
+	})
+
+	When("the context ends while backing off", func() {
+		BeforeEach(func() {
+			client = client.WithBackoff(time.Hour, time.Hour)
+			ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("returns the error of the context", func() {
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(fakeClient.PromQLCallCount()).To(Equal(1))
+		})
+	})
+
+	When("retries are disabled", func() {
+		BeforeEach(func() {
+			client = fetchers.NewRetryingLogCacheClient(fakeClient, logger, 0)
+		})
+
+		It("makes a single request", func() {
+			Expect(err).To(MatchError(unavailable))
+			Expect(fakeClient.PromQLCallCount()).To(Equal(1))
+		})
+	})
+
+	It("retries reads and range queries too", func() {
+		fakeClient.ReadReturnsOnCall(0, nil, unavailable)
+		fakeClient.ReadReturnsOnCall(1, []*loggregator_v2.Envelope{{SourceId: "foo"}}, nil)
+		envelopes, err := client.Read(context.Background(), "foo", time.Unix(1, 0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(envelopes).To(HaveLen(1))
+		Expect(fakeClient.ReadCallCount()).To(Equal(2))
+
+		fakeClient.PromQLRangeReturnsOnCall(0, nil, unavailable)
+		fakeClient.PromQLRangeReturnsOnCall(1, &logcache_v1.PromQL_RangeQueryResult{}, nil)
+		_, err = client.PromQLRange(context.Background(), "query")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeClient.PromQLRangeCallCount()).To(Equal(2))
+	})
+})
@@ -0,0 +1,86 @@
+package fetchers
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+	"code.cloudfoundry.org/lager"
+)
+
+type Spike struct {
+	From time.Time
+	To   time.Time
+}
+
+// SpikeHistoryInstanceData is the spikes of an instance. TruncatedBefore is
+// set when there were too many spike gauges to read them all, and spikes
+// before then may be missing.
+type SpikeHistoryInstanceData struct {
+	InstanceID      int
+	Spikes          []Spike
+	TruncatedBefore time.Time
+}
+
+type SpikeHistoryFetcher struct {
+	client LogCacheClient
+	since  time.Time
+	limit  int
+}
+
+func NewSpikeHistoryFetcherWithLimit(client LogCacheClient, since time.Time, limit int) SpikeHistoryFetcher {
+	return SpikeHistoryFetcher{client: client, since: since, limit: limit}
+}
+
+func NewSpikeHistoryFetcher(client LogCacheClient, since time.Time) SpikeHistoryFetcher {
+	return NewSpikeHistoryFetcherWithLimit(client, since, 1000)
+}
+
+// FetchInstanceData returns every distinct spike of each instance since the
+// configured time, oldest first. A spike gauge is emitted repeatedly while an
+// instance is spiking, with the same start and a growing end, so spikes are
+// told apart by their start.
+func (f SpikeHistoryFetcher) FetchInstanceData(ctx context.Context, logger lager.Logger, appGUID string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+	logger = logger.Session("spike-history-fetcher", lager.Data{"app-guid": appGUID})
+	logger.Info("start")
+	defer logger.Info("end")
+
+	res, truncatedBefore, err := readSpikeEnvelopes(ctx, logger, f.client, appGUID, f.since, f.limit)
+	if err != nil {
+		return nil, err
+	}
+
+	spikesPerInstance := map[int]map[time.Time]Spike{}
+	for _, envelope := range res {
+		spike, ok := parseSpikeEnvelope(logger, envelope, appInstances)
+		if !ok {
+			continue
+		}
+
+		spikes, ok := spikesPerInstance[spike.InstanceID]
+		if !ok {
+			spikes = map[time.Time]Spike{}
+			spikesPerInstance[spike.InstanceID] = spikes
+		}
+
+		if existing, ok := spikes[spike.From]; ok && !spike.To.After(existing.To) {
+			continue
+		}
+		spikes[spike.From] = Spike{From: spike.From, To: spike.To}
+	}
+
+	spikeHistoryPerInstance := map[int]interface{}{}
+	for instanceID, spikes := range spikesPerInstance {
+		spikeHistory := SpikeHistoryInstanceData{InstanceID: instanceID, TruncatedBefore: truncatedBefore}
+		for _, spike := range spikes {
+			spikeHistory.Spikes = append(spikeHistory.Spikes, spike)
+		}
+		sort.Slice(spikeHistory.Spikes, func(i, j int) bool {
+			return spikeHistory.Spikes[i].From.Before(spikeHistory.Spikes[j].From)
+		})
+		spikeHistoryPerInstance[instanceID] = spikeHistory
+	}
+
+	return spikeHistoryPerInstance, nil
+}
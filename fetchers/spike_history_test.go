@@ -0,0 +1,168 @@
+package fetchers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers/fetchersfakes"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+var _ = Describe("SpikeHistoryFetcher", func() {
+	var (
+		logCacheClient *fetchersfakes.FakeLogCacheClient
+		fetcher        fetchers.SpikeHistoryFetcher
+		appInstances   map[int]cf.Instance
+		spikes         map[int]interface{}
+		fetchErr       error
+		since          time.Time
+	)
+
+	BeforeEach(func() {
+		since = time.Now().Add(-time.Hour)
+		logCacheClient = new(fetchersfakes.FakeLogCacheClient)
+		fetcher = fetchers.NewSpikeHistoryFetcher(logCacheClient, since)
+
+		appInstances = map[int]cf.Instance{
+			0: {InstanceID: 0, ProcessInstanceID: "abc"},
+			1: {InstanceID: 1, ProcessInstanceID: "def"},
+		}
+
+		logCacheClient.ReadReturns([]*loggregator_v2.Envelope{
+			spikeEnvelope("0", "abc", 50, 60),
+			spikeEnvelope("0", "abc", 50, 55),
+			spikeEnvelope("1", "def", 30, 40),
+			spikeEnvelope("0", "abc", 10, 20),
+			spikeEnvelope("0", "old-process", 1, 2),
+			spikeEnvelope("not-valid", "abc", 1, 2),
+		}, nil)
+	})
+
+	JustBeforeEach(func() {
+		spikes, fetchErr = fetcher.FetchInstanceData(context.Background(), logger, "foo", appInstances)
+	})
+
+	It("reads the spike gauges since the configured time", func() {
+		Expect(logCacheClient.ReadCallCount()).To(Equal(1))
+		_, sourceID, start, _ := logCacheClient.ReadArgsForCall(0)
+		Expect(sourceID).To(Equal("foo"))
+		Expect(start).To(Equal(since))
+	})
+
+	It("returns every distinct spike of each instance, oldest first", func() {
+		Expect(fetchErr).NotTo(HaveOccurred())
+		Expect(spikes).To(Equal(map[int]interface{}{
+			0: fetchers.SpikeHistoryInstanceData{
+				InstanceID: 0,
+				Spikes: []fetchers.Spike{
+					{From: time.Unix(10, 0), To: time.Unix(20, 0)},
+					{From: time.Unix(50, 0), To: time.Unix(60, 0)},
+				},
+			},
+			1: fetchers.SpikeHistoryInstanceData{
+				InstanceID: 1,
+				Spikes: []fetchers.Spike{
+					{From: time.Unix(30, 0), To: time.Unix(40, 0)},
+				},
+			},
+		}))
+	})
+
+	When("there are more spike gauges than log-cache returns in one read", func() {
+		BeforeEach(func() {
+			fetcher = fetchers.NewSpikeHistoryFetcherWithLimit(logCacheClient, since, 2)
+
+			firstPage := []*loggregator_v2.Envelope{
+				spikeEnvelope("0", "abc", 50, 60),
+				spikeEnvelope("0", "abc", 50, 55),
+			}
+			firstPage[1].Timestamp = time.Unix(55, 0).UnixNano()
+			logCacheClient.ReadReturnsOnCall(0, firstPage, nil)
+			logCacheClient.ReadReturnsOnCall(1, []*loggregator_v2.Envelope{
+				spikeEnvelope("0", "abc", 10, 20),
+			}, nil)
+		})
+
+		It("keeps reading older pages until a page is not full", func() {
+			Expect(logCacheClient.ReadCallCount()).To(Equal(2))
+
+			_, _, _, opts := logCacheClient.ReadArgsForCall(0)
+			Expect(readParams(opts...).Get("limit")).To(Equal("2"))
+
+			_, _, start, opts := logCacheClient.ReadArgsForCall(1)
+			Expect(start).To(Equal(since))
+			Expect(readParams(opts...).Get("end_time")).To(Equal(fmt.Sprint(time.Unix(55, 0).UnixNano())))
+		})
+
+		It("returns the spikes from every page", func() {
+			Expect(fetchErr).NotTo(HaveOccurred())
+			Expect(spikes).To(Equal(map[int]interface{}{
+				0: fetchers.SpikeHistoryInstanceData{
+					InstanceID: 0,
+					Spikes: []fetchers.Spike{
+						{From: time.Unix(10, 0), To: time.Unix(20, 0)},
+						{From: time.Unix(50, 0), To: time.Unix(60, 0)},
+					},
+				},
+			}))
+		})
+
+		When("every page is full", func() {
+			BeforeEach(func() {
+				logCacheClient.ReadReturnsOnCall(1, []*loggregator_v2.Envelope{
+					spikeEnvelope("0", "abc", 10, 20),
+					spikeEnvelope("0", "abc", 10, 15),
+				}, nil)
+				oldestEnvelope := spikeEnvelope("0", "abc", 1, 2)
+				oldestEnvelope.Timestamp = time.Unix(3, 0).UnixNano()
+				logCacheClient.ReadReturns([]*loggregator_v2.Envelope{
+					spikeEnvelope("0", "abc", 1, 2),
+					oldestEnvelope,
+				}, nil)
+			})
+
+			It("stops after 10 reads, which is our sanity limit", func() {
+				Expect(logCacheClient.ReadCallCount()).To(Equal(10))
+			})
+
+			It("tells that spikes before the oldest one read may be missing", func() {
+				Expect(spikes).To(HaveKey(0))
+				Expect(spikes[0].(fetchers.SpikeHistoryInstanceData).TruncatedBefore).To(Equal(time.Unix(3, 0)))
+			})
+		})
+	})
+
+	When("reading from log-cache fails", func() {
+		BeforeEach(func() {
+			logCacheClient.ReadReturns(nil, errors.New("boo"))
+		})
+
+		It("returns the error", func() {
+			Expect(fetchErr).To(MatchError("boo"))
+		})
+	})
+})
+
+func spikeEnvelope(instanceID, processInstanceID string, start, end float64) *loggregator_v2.Envelope {
+	return &loggregator_v2.Envelope{
+		InstanceId: instanceID,
+		Tags: map[string]string{
+			"process_instance_id": processInstanceID,
+		},
+		Message: &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{
+				Metrics: map[string]*loggregator_v2.GaugeValue{
+					"spike_start": {Value: start},
+					"spike_end":   {Value: end},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	logcache "code.cloudfoundry.org/log-cache/pkg/client"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+)
+
+// TimeoutLogCacheClient gives up on any request to log-cache that takes
+// longer than the timeout, so that a single slow request cannot hold up a
+// whole report.
+type TimeoutLogCacheClient struct {
+	client  LogCacheClient
+	timeout time.Duration
+}
+
+func NewTimeoutLogCacheClient(client LogCacheClient, timeout time.Duration) TimeoutLogCacheClient {
+	return TimeoutLogCacheClient{client: client, timeout: timeout}
+}
+
+func (c TimeoutLogCacheClient) Read(ctx context.Context, sourceID string, start time.Time, opts ...logcache.ReadOption) ([]*loggregator_v2.Envelope, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.client.Read(ctx, sourceID, start, opts...)
+}
+
+func (c TimeoutLogCacheClient) PromQL(ctx context.Context, query string, opts ...logcache.PromQLOption) (*logcache_v1.PromQL_InstantQueryResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.client.PromQL(ctx, query, opts...)
+}
+
+func (c TimeoutLogCacheClient) PromQLRange(ctx context.Context, query string, opts ...logcache.PromQLOption) (*logcache_v1.PromQL_RangeQueryResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.client.PromQLRange(ctx, query, opts...)
+}
+
+// TimeoutError is returned by the fetchers when log-cache did not answer
+// before the deadline of the request, so that callers can tell a slow
+// log-cache apart from other failures.
+type TimeoutError struct {
+	Fetch    string
+	SourceID string
+	Err      error
+}
+
+func (e TimeoutError) Error() string {
+	if e.SourceID == "" {
+		return fmt.Sprintf("Timed out fetching the %s from log-cache", e.Fetch)
+	}
+	return fmt.Sprintf("Timed out fetching the %s of %s from log-cache", e.Fetch, e.SourceID)
+}
+
+func (e TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// wrapTimeout turns err into a TimeoutError when the request to log-cache
+// exceeded its deadline, and returns it as is otherwise.
+func wrapTimeout(ctx context.Context, err error, fetch, sourceID string) error {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return TimeoutError{Fetch: fetch, SourceID: sourceID, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package fetchers_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers/fetchersfakes"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/lager/lagertest"
+	logcache "code.cloudfoundry.org/log-cache/pkg/client"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TimeoutLogCacheClient", func() {
+	var (
+		fakeClient *fetchersfakes.FakeLogCacheClient
+		client     fetchers.TimeoutLogCacheClient
+		before     time.Time
+	)
+
+	BeforeEach(func() {
+		fakeClient = new(fetchersfakes.FakeLogCacheClient)
+		client = fetchers.NewTimeoutLogCacheClient(fakeClient, time.Minute)
+		before = time.Now()
+	})
+
+	expectDeadline := func(ctx context.Context) {
+		deadline, ok := ctx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(deadline).To(BeTemporally("~", before.Add(time.Minute), time.Second))
+	}
+
+	It("sets a deadline on reads", func() {
+		fakeClient.ReadReturns([]*loggregator_v2.Envelope{{SourceId: "foo"}}, nil)
+
+		envelopes, err := client.Read(context.Background(), "foo", time.Unix(1, 0), logcache.WithLimit(1))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(envelopes).To(Equal([]*loggregator_v2.Envelope{{SourceId: "foo"}}))
+
+		ctx, sourceID, start, opts := fakeClient.ReadArgsForCall(0)
+		expectDeadline(ctx)
+		Expect(sourceID).To(Equal("foo"))
+		Expect(start).To(Equal(time.Unix(1, 0)))
+		Expect(readParams(opts...).Get("limit")).To(Equal("1"))
+	})
+
+	It("sets a deadline on instant queries", func() {
+		fakeClient.PromQLReturns(queryResult(), nil)
+
+		result, err := client.PromQL(context.Background(), "query")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(queryResult()))
+
+		ctx, query, _ := fakeClient.PromQLArgsForCall(0)
+		expectDeadline(ctx)
+		Expect(query).To(Equal("query"))
+	})
+
+	It("sets a deadline on range queries", func() {
+		fakeClient.PromQLRangeReturns(&logcache_v1.PromQL_RangeQueryResult{}, nil)
+
+		_, err := client.PromQLRange(context.Background(), "query")
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, query, _ := fakeClient.PromQLRangeArgsForCall(0)
+		expectDeadline(ctx)
+		Expect(query).To(Equal("query"))
+	})
+
+	It("keeps an earlier deadline of the caller", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, _ = client.PromQL(ctx, "query")
+
+		actualCtx, _, _ := fakeClient.PromQLArgsForCall(0)
+		deadline, _ := actualCtx.Deadline()
+		Expect(deadline).To(BeTemporally("~", before.Add(time.Second), 500*time.Millisecond))
+	})
+})
+
+var _ = Describe("TimeoutError", func() {
+	var (
+		fakeClient *fetchersfakes.FakeLogCacheClient
+		fetcher    fetchers.CurrentUsageFetcher
+		err        error
+	)
+
+	BeforeEach(func() {
+		fakeClient = new(fetchersfakes.FakeLogCacheClient)
+		fetcher = fetchers.NewCurrentUsageFetcher(fakeClient)
+	})
+
+	JustBeforeEach(func() {
+		_, err = fetcher.FetchInstanceData(context.Background(), lagertest.NewTestLogger("timeout-error-test"), "app-guid", map[int]cf.Instance{})
+	})
+
+	When("log-cache does not answer before the deadline", func() {
+		BeforeEach(func() {
+			fakeClient.PromQLReturns(nil, context.DeadlineExceeded)
+		})
+
+		It("returns a timeout error naming the fetch", func() {
+			var timeoutErr fetchers.TimeoutError
+			Expect(errors.As(err, &timeoutErr)).To(BeTrue())
+			Expect(err).To(MatchError("Timed out fetching the current usage of app-guid from log-cache"))
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		})
+	})
+
+	When("log-cache fails otherwise", func() {
+		BeforeEach(func() {
+			fakeClient.PromQLReturns(nil, errors.New("fetch-failed"))
+		})
+
+		It("returns the error as is", func() {
+			Expect(err).To(MatchError("fetch-failed"))
+		})
+	})
+
+	It("leaves out the source when there is none", func() {
+		Expect(fetchers.TimeoutError{Fetch: "average usage"}).To(MatchError("Timed out fetching the average usage from log-cache"))
+	})
+})
@@ -0,0 +1,100 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+	"code.cloudfoundry.org/lager"
+	logcache "code.cloudfoundry.org/log-cache/pkg/client"
+)
+
+type UsageSample struct {
+	Time  time.Time
+	Usage float64
+}
+
+type UsageHistoryInstanceData struct {
+	InstanceID int
+	Samples    []UsageSample
+}
+
+type UsageHistoryFetcher struct {
+	client LogCacheClient
+	since  time.Time
+	until  time.Time
+	step   time.Duration
+}
+
+func NewUsageHistoryFetcher(client LogCacheClient, since, until time.Time, step time.Duration) UsageHistoryFetcher {
+	return UsageHistoryFetcher{client: client, since: since, until: until, step: step}
+}
+
+// FetchInstanceData returns the usage of each instance between since and
+// until, one sample per step. Each sample is the ratio of the increase of the
+// usage and entitlement counters over the step before it. Steps in which the
+// entitlement did not increase have no sample, as their ratio is not a finite
+// number.
+func (f UsageHistoryFetcher) FetchInstanceData(ctx context.Context, logger lager.Logger, appGUID string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+	logger = logger.Session("usage-history-fetcher", lager.Data{"app-guid": appGUID})
+	logger.Info("start")
+	defer logger.Info("end")
+
+	step := fmt.Sprintf("%ds", int64(f.step.Seconds()))
+	query := fmt.Sprintf(`delta(absolute_usage{source_id="%s"}[%s]) / delta(absolute_entitlement{source_id="%s"}[%s])`, appGUID, step, appGUID, step)
+	res, err := f.client.PromQLRange(ctx, query,
+		logcache.WithPromQLStart(f.since),
+		logcache.WithPromQLEnd(f.until),
+		logcache.WithPromQLStep(step),
+	)
+	if err != nil {
+		logger.Error("promql-range-failed", err, lager.Data{"query": query})
+		return nil, wrapTimeout(ctx, err, "usage history", appGUID)
+	}
+
+	usageHistoryPerInstance := map[int]interface{}{}
+	for _, series := range res.GetMatrix().GetSeries() {
+		instanceID, err := strconv.Atoi(series.GetMetric()["instance_id"])
+		if err != nil {
+			logger.Info("ignoring-corrupt-instance-id", lager.Data{"instance-id": series.GetMetric()["instance_id"]})
+			continue
+		}
+
+		processInstanceID := series.GetMetric()["process_instance_id"]
+		if appInstances[instanceID].ProcessInstanceID != processInstanceID {
+			continue
+		}
+
+		usageHistory := UsageHistoryInstanceData{InstanceID: instanceID}
+		for _, point := range series.GetPoints() {
+			sampleTime, err := parsePromQLTime(point.GetTime())
+			if err != nil {
+				logger.Info("ignoring-corrupt-time", lager.Data{"time": point.GetTime()})
+				continue
+			}
+			if math.IsNaN(point.GetValue()) || math.IsInf(point.GetValue(), 0) {
+				logger.Debug("ignoring-non-finite-sample", lager.Data{"time": point.GetTime()})
+				continue
+			}
+			usageHistory.Samples = append(usageHistory.Samples, UsageSample{Time: sampleTime, Usage: point.GetValue()})
+		}
+		usageHistoryPerInstance[instanceID] = usageHistory
+	}
+
+	return usageHistoryPerInstance, nil
+}
+
+// parsePromQLTime parses the time of a PromQL point, which log-cache returns
+// as a decimal number of seconds since the epoch.
+func parsePromQLTime(value string) (time.Time, error) {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*float64(time.Second))), nil
+}
@@ -0,0 +1,120 @@
+package fetchers_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers/fetchersfakes"
+)
+
+var _ = Describe("UsageHistoryFetcher", func() {
+	var (
+		logCacheClient *fetchersfakes.FakeLogCacheClient
+		fetcher        fetchers.UsageHistoryFetcher
+		appInstances   map[int]cf.Instance
+		usageHistory   map[int]interface{}
+		fetchErr       error
+		since, until   time.Time
+	)
+
+	BeforeEach(func() {
+		since = time.Unix(1000, 0)
+		until = time.Unix(1600, 0)
+		logCacheClient = new(fetchersfakes.FakeLogCacheClient)
+		fetcher = fetchers.NewUsageHistoryFetcher(logCacheClient, since, until, 5*time.Minute)
+
+		appInstances = map[int]cf.Instance{
+			0: {InstanceID: 0, ProcessInstanceID: "abc"},
+			1: {InstanceID: 1, ProcessInstanceID: "def"},
+		}
+
+		logCacheClient.PromQLRangeReturns(rangeQueryResult(
+			series("0", "abc",
+				point("1300", 0.5),
+				point("1600.5", 1.5),
+			),
+			series("1", "def",
+				point("1600", 0.25),
+				point("not-a-time", 0.75),
+			),
+			series("1", "old-process",
+				point("1300", 2),
+			),
+			series("dyado", "abc",
+				point("1300", 2),
+			),
+		), nil)
+	})
+
+	JustBeforeEach(func() {
+		usageHistory, fetchErr = fetcher.FetchInstanceData(context.Background(), logger, "foo", appInstances)
+	})
+
+	It("queries the usage over each step between since and until", func() {
+		Expect(logCacheClient.PromQLRangeCallCount()).To(Equal(1))
+		_, query, opts := logCacheClient.PromQLRangeArgsForCall(0)
+		Expect(query).To(Equal(`delta(absolute_usage{source_id="foo"}[300s]) / delta(absolute_entitlement{source_id="foo"}[300s])`))
+		params := promQLParams(opts...)
+		Expect(params.Get("start")).To(Equal("1000.000"))
+		Expect(params.Get("end")).To(Equal("1600.000"))
+		Expect(params.Get("step")).To(Equal("300s"))
+	})
+
+	It("returns the samples of each current instance", func() {
+		Expect(fetchErr).NotTo(HaveOccurred())
+		Expect(usageHistory).To(Equal(map[int]interface{}{
+			0: fetchers.UsageHistoryInstanceData{
+				InstanceID: 0,
+				Samples: []fetchers.UsageSample{
+					{Time: time.Unix(1300, 0), Usage: 0.5},
+					{Time: time.Unix(1600, int64(500*time.Millisecond)), Usage: 1.5},
+				},
+			},
+			1: fetchers.UsageHistoryInstanceData{
+				InstanceID: 1,
+				Samples: []fetchers.UsageSample{
+					{Time: time.Unix(1600, 0), Usage: 0.25},
+				},
+			},
+		}))
+	})
+
+	When("a sample is not finite", func() {
+		BeforeEach(func() {
+			logCacheClient.PromQLRangeReturns(rangeQueryResult(
+				series("0", "abc",
+					point("1000", math.NaN()),
+					point("1300", 0.5),
+					point("1600", math.Inf(1)),
+				),
+			), nil)
+		})
+
+		It("leaves it out", func() {
+			Expect(fetchErr).NotTo(HaveOccurred())
+			Expect(usageHistory).To(Equal(map[int]interface{}{
+				0: fetchers.UsageHistoryInstanceData{
+					InstanceID: 0,
+					Samples:    []fetchers.UsageSample{{Time: time.Unix(1300, 0), Usage: 0.5}},
+				},
+			}))
+		})
+	})
+
+	When("the range query fails", func() {
+		BeforeEach(func() {
+			logCacheClient.PromQLRangeReturns(nil, errors.New("boo"))
+		})
+
+		It("returns the error", func() {
+			Expect(fetchErr).To(MatchError("boo"))
+		})
+	})
+})
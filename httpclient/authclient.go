@@ -1,31 +1,74 @@
 package httpclient
 
 import (
-	"crypto/tls"
+	"io"
+	"io/ioutil"
 	"net/http"
 )
 
+// AuthClient adds the token of the cf CLI to every request it makes with its
+// HTTP client, which is usually created with NewHTTPClient. The token getter
+// can be shared by several auth clients, so that they refresh the token only
+// once between them.
 type AuthClient struct {
 	tokenGetter *TokenGetter
+	httpClient  *http.Client
 }
 
-func NewAuthClient(getToken GetToken) *AuthClient {
-	tokenGetter := NewTokenGetter(getToken)
-
+func NewAuthClient(tokenGetter *TokenGetter, httpClient *http.Client) *AuthClient {
 	return &AuthClient{
 		tokenGetter: tokenGetter,
+		httpClient:  httpClient,
 	}
 }
 
-func (a *AuthClient) SkipSSLValidation() {
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+// Do makes the request with the current token. When the token is rejected,
+// as happens when it is revoked or expires early, a new token is fetched and
+// the request is made once more, provided its body can be sent again.
+func (a *AuthClient) Do(req *http.Request) (*http.Response, error) {
+	res, token, err := a.do(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	retry := cloneRequest(req)
+	if retry == nil {
+		return res, nil
+	}
+
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+
+	a.tokenGetter.Invalidate(token)
+	res, _, err = a.do(retry)
+	return res, err
 }
 
-func (a *AuthClient) Do(req *http.Request) (*http.Response, error) {
+func (a *AuthClient) do(req *http.Request) (*http.Response, string, error) {
 	t, err := a.tokenGetter.Token()
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	req.Header.Set("Authorization", t)
-	return http.DefaultClient.Do(req)
+	res, err := a.httpClient.Do(req)
+	return res, t, err
+}
+
+// cloneRequest returns a copy of the request to send again, or nil if its
+// body cannot be read a second time.
+func cloneRequest(req *http.Request) *http.Request {
+	retry := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry
+	}
+	if req.GetBody == nil {
+		return nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	retry.Body = body
+	return retry
 }
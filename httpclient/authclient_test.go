@@ -0,0 +1,115 @@
+package httpclient_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/httpclient"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/httpclient/httpclientfakes"
+)
+
+var _ = Describe("AuthClient", func() {
+	var (
+		server        *httptest.Server
+		validToken    string
+		fakeGetToken  *httpclientfakes.FakeGetToken
+		authClient    *httpclient.AuthClient
+		authHeaders   []string
+		requestBodies []string
+		req           *http.Request
+		res           *http.Response
+		err           error
+	)
+
+	BeforeEach(func() {
+		authHeaders = nil
+		requestBodies = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+			body, _ := ioutil.ReadAll(r.Body)
+			requestBodies = append(requestBodies, string(body))
+			if r.Header.Get("Authorization") != validToken {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+
+		revokedToken, tokenErr := aTokenExpiringIn(10 * time.Minute)
+		Expect(tokenErr).NotTo(HaveOccurred())
+		validToken, tokenErr = aTokenExpiringIn(20 * time.Minute)
+		Expect(tokenErr).NotTo(HaveOccurred())
+
+		fakeGetToken = new(httpclientfakes.FakeGetToken)
+		fakeGetToken.ReturnsOnCall(0, revokedToken, nil)
+		fakeGetToken.ReturnsOnCall(1, validToken, nil)
+		authClient = httpclient.NewAuthClient(httpclient.NewTokenGetter(fakeGetToken.Spy), server.Client())
+
+		req, err = http.NewRequest(http.MethodGet, server.URL, nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	JustBeforeEach(func() {
+		res, err = authClient.Do(req)
+	})
+
+	When("the token is rejected", func() {
+		It("refreshes it and retries the request once", func() {
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(authHeaders).To(HaveLen(2))
+			Expect(authHeaders[1]).To(Equal(validToken))
+			Expect(fakeGetToken.CallCount()).To(Equal(2))
+		})
+	})
+
+	When("the new token is rejected too", func() {
+		BeforeEach(func() {
+			validToken = "none"
+		})
+
+		It("returns the response", func() {
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(authHeaders).To(HaveLen(2))
+		})
+	})
+
+	When("the request has a body", func() {
+		BeforeEach(func() {
+			req, err = http.NewRequest(http.MethodPost, server.URL, strings.NewReader("body"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("sends it again", func() {
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(requestBodies).To(Equal([]string{"body", "body"}))
+		})
+	})
+
+	When("the body of the request cannot be sent again", func() {
+		BeforeEach(func() {
+			req, err = http.NewRequest(http.MethodPost, server.URL, ioutil.NopCloser(strings.NewReader("body")))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("does not retry the request", func() {
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(authHeaders).To(HaveLen(1))
+		})
+	})
+})
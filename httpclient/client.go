@@ -0,0 +1,80 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Config is how the HTTP client of the plugins connects to the Cloud
+// Controller and log-cache.
+type Config struct {
+	SkipSSLValidation bool
+	// CACertFiles are PEM bundles of certificate authorities to trust on top
+	// of the system ones and of those in the file named by SSL_CERT_FILE.
+	CACertFiles []string
+	// Timeout is the time limit of each request. Zero means no limit.
+	Timeout time.Duration
+}
+
+// NewHTTPClient returns a client with a transport of its own, so that its
+// TLS settings do not leak into http.DefaultTransport and the rest of the
+// process. It goes through the proxy given by HTTPS_PROXY, HTTP_PROXY and
+// NO_PROXY, and keeps connections open for reuse across requests.
+func NewHTTPClient(config Config) (*http.Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.SkipSSLValidation}
+
+	caCertFiles := config.CACertFiles
+	if sslCertFile := os.Getenv("SSL_CERT_FILE"); sslCertFile != "" {
+		caCertFiles = append([]string{sslCertFile}, caCertFiles...)
+	}
+	if len(caCertFiles) > 0 {
+		rootCAs, err := loadCertPool(caCertFiles)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   20,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	return &http.Client{Transport: transport, Timeout: config.Timeout}, nil
+}
+
+// loadCertPool adds the certificates of the files to the system ones.
+func loadCertPool(certFiles []string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	for _, certFile := range certFiles {
+		pem, err := ioutil.ReadFile(certFile)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read CA certificate %s: %s", certFile, err.Error())
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No certificates found in %s", certFile)
+		}
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,172 @@
+package httpclient_test
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/httpclient"
+)
+
+var _ = Describe("HTTP client", func() {
+	var (
+		server     *httptest.Server
+		handler    http.HandlerFunc
+		tempDir    string
+		certFile   string
+		config     httpclient.Config
+		httpClient *http.Client
+		clientErr  error
+	)
+
+	BeforeEach(func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Header.Get("Authorization")))
+		}
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(w, r)
+		}))
+
+		var err error
+		tempDir, err = ioutil.TempDir("", "httpclient-test")
+		Expect(err).NotTo(HaveOccurred())
+		certFile = filepath.Join(tempDir, "ca.pem")
+		certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		Expect(ioutil.WriteFile(certFile, certPEM, 0600)).To(Succeed())
+
+		config = httpclient.Config{}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		httpClient, clientErr = httpclient.NewHTTPClient(config)
+	})
+
+	get := func() (string, error) {
+		res, err := httpClient.Get(server.URL)
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close()
+		body, err := ioutil.ReadAll(res.Body)
+		return string(body), err
+	}
+
+	It("does not trust unknown certificate authorities", func() {
+		Expect(clientErr).NotTo(HaveOccurred())
+		_, err := get()
+		Expect(err).To(MatchError(ContainSubstring("certificate")))
+	})
+
+	It("uses a transport of its own", func() {
+		Expect(httpClient.Transport).NotTo(BeIdenticalTo(http.DefaultTransport))
+		Expect(httpClient.Transport.(*http.Transport).Proxy).NotTo(BeNil())
+	})
+
+	When("SSL validation is skipped", func() {
+		var defaultTLSConfig *tls.Config
+
+		BeforeEach(func() {
+			config.SkipSSLValidation = true
+			defaultTLSConfig = http.DefaultTransport.(*http.Transport).TLSClientConfig
+		})
+
+		It("skips it for its own requests only", func() {
+			Expect(get()).To(Equal(""))
+			Expect(http.DefaultTransport.(*http.Transport).TLSClientConfig).To(BeIdenticalTo(defaultTLSConfig))
+		})
+	})
+
+	When("a CA certificate file is given", func() {
+		BeforeEach(func() {
+			config.CACertFiles = []string{certFile}
+		})
+
+		It("trusts its certificate authorities", func() {
+			Expect(get()).To(Equal(""))
+		})
+	})
+
+	When("SSL_CERT_FILE is set", func() {
+		BeforeEach(func() {
+			os.Setenv("SSL_CERT_FILE", certFile)
+		})
+
+		AfterEach(func() {
+			os.Unsetenv("SSL_CERT_FILE")
+		})
+
+		It("trusts its certificate authorities", func() {
+			Expect(get()).To(Equal(""))
+		})
+	})
+
+	When("the CA certificate file does not exist", func() {
+		BeforeEach(func() {
+			config.CACertFiles = []string{filepath.Join(tempDir, "missing.pem")}
+		})
+
+		It("returns an error", func() {
+			Expect(clientErr).To(MatchError(ContainSubstring("Unable to read CA certificate")))
+		})
+	})
+
+	When("the CA certificate file has no certificates", func() {
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile(certFile, []byte("not a certificate"), 0600)).To(Succeed())
+			config.CACertFiles = []string{certFile}
+		})
+
+		It("returns an error", func() {
+			Expect(clientErr).To(MatchError("No certificates found in " + certFile))
+		})
+	})
+
+	When("a request takes longer than the timeout", func() {
+		BeforeEach(func() {
+			config.SkipSSLValidation = true
+			config.Timeout = 10 * time.Millisecond
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(100 * time.Millisecond)
+			}
+		})
+
+		It("gives up on it", func() {
+			_, err := get()
+			Expect(err).To(MatchError(ContainSubstring("Client.Timeout exceeded")))
+		})
+	})
+
+	When("used by an auth client", func() {
+		BeforeEach(func() {
+			config.SkipSSLValidation = true
+		})
+
+		It("sends the token with every request", func() {
+			token, err := aTokenExpiringIn(time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+			authClient := httpclient.NewAuthClient(httpclient.NewTokenGetter(func() (string, error) { return token, nil }), httpClient)
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			res, err := authClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+			body, err := ioutil.ReadAll(res.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal(token))
+		})
+	})
+})
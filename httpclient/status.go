@@ -0,0 +1,91 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTPClient is satisfied by *http.Client and AuthClient.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// StatusError is returned instead of a response whose status tells that the
+// request may succeed if it is made again later.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	// RetryAfter is how long the server asked to wait before retrying, or
+	// zero when it did not say.
+	RetryAfter time.Duration
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %s", e.Status)
+}
+
+// IsRetryableStatus tells whether a response with this status code is worth
+// retrying.
+func IsRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// StatusClient turns responses with a retryable status into StatusErrors.
+// The log-cache client only reports the status code of failed responses, so
+// this is how the Retry-After header reaches the retrying log-cache client.
+type StatusClient struct {
+	client HTTPClient
+}
+
+func NewStatusClient(client HTTPClient) StatusClient {
+	return StatusClient{client: client}
+}
+
+func (c StatusClient) Do(req *http.Request) (*http.Response, error) {
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsRetryableStatus(res.StatusCode) {
+		return res, nil
+	}
+
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+
+	return nil, StatusError{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter reads a Retry-After header, which is either a number of
+// seconds or a date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
@@ -0,0 +1,93 @@
+package httpclient_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/httpclient"
+)
+
+var _ = Describe("StatusClient", func() {
+	var (
+		server  *httptest.Server
+		handler http.HandlerFunc
+		res     *http.Response
+		err     error
+	)
+
+	BeforeEach(func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(w, r)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	JustBeforeEach(func() {
+		req, reqErr := http.NewRequest(http.MethodGet, server.URL, nil)
+		Expect(reqErr).NotTo(HaveOccurred())
+		res, err = httpclient.NewStatusClient(server.Client()).Do(req)
+	})
+
+	It("returns successful responses as they are", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		res.Body.Close()
+	})
+
+	When("the status is retryable", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "2")
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		})
+
+		It("returns a status error with the Retry-After delay", func() {
+			var statusErr httpclient.StatusError
+			Expect(errors.As(err, &statusErr)).To(BeTrue())
+			Expect(statusErr.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(statusErr.RetryAfter).To(Equal(2 * time.Second))
+			Expect(err).To(MatchError("unexpected status 503 Service Unavailable"))
+		})
+	})
+
+	When("Retry-After is a date", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		})
+
+		It("waits until then", func() {
+			var statusErr httpclient.StatusError
+			Expect(errors.As(err, &statusErr)).To(BeTrue())
+			Expect(statusErr.RetryAfter).To(BeNumerically("~", time.Minute, 2*time.Second))
+		})
+	})
+
+	When("the status is not retryable", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+
+		It("returns the response", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.StatusCode).To(Equal(http.StatusNotFound))
+			res.Body.Close()
+		})
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -12,8 +13,21 @@ import (
 
 type GetToken func() (string, error)
 
+const (
+	// expiryMargin is how long before it expires a token is refreshed, so
+	// that it does not expire while a request is in flight.
+	expiryMargin = 30 * time.Second
+	// opaqueTokenLifetime is how long a token whose expiry cannot be read is
+	// reused for. A 401 response gets it refreshed sooner.
+	opaqueTokenLifetime = time.Minute
+)
+
+// TokenGetter caches the token until shortly before it expires. It is safe to
+// use from several goroutines, so that a single one can be shared by all the
+// clients of a plugin.
 type TokenGetter struct {
 	getToken            GetToken
+	mutex               sync.Mutex
 	currentToken        string
 	tokenExpirationTime time.Time
 }
@@ -26,14 +40,29 @@ func NewTokenGetter(getToken GetToken) *TokenGetter {
 }
 
 func (t *TokenGetter) Token() (string, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
 	if t.tokenExpired() {
 		return t.refreshToken()
 	}
 	return t.currentToken, nil
 }
 
+// Invalidate makes the next call to Token get a new token, unless the token
+// was already replaced by another goroutine. It is called when a request
+// made with the token is rejected.
+func (t *TokenGetter) Invalidate(token string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if token == t.currentToken {
+		t.tokenExpirationTime = time.Now()
+	}
+}
+
 func (t *TokenGetter) tokenExpired() bool {
-	return time.Now().After(t.tokenExpirationTime)
+	return !time.Now().Before(t.tokenExpirationTime.Add(-expiryMargin))
 }
 
 func (t *TokenGetter) refreshToken() (string, error) {
@@ -48,6 +77,9 @@ func (t *TokenGetter) refreshToken() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if t.tokenExpirationTime.IsZero() {
+		t.tokenExpirationTime = time.Now().Add(opaqueTokenLifetime)
+	}
 	return token, nil
 }
 
@@ -55,8 +87,19 @@ type tokenJson struct {
 	ExpTime int64 `json:"exp"`
 }
 
+// extractExpirationTimeFromToken reads the expiry of a JWT, which may be
+// prefixed with its type as the cf CLI does. It returns the zero time for
+// opaque tokens and tokens without an expiry.
 func extractExpirationTimeFromToken(token string) (time.Time, error) {
-	encodedMetadata := strings.Split(token, ".")[1]
+	if fields := strings.Fields(token); len(fields) == 2 && strings.EqualFold(fields[0], "bearer") {
+		token = fields[1]
+	}
+
+	segments := strings.Split(token, ".")
+	if len(segments) < 2 {
+		return time.Time{}, nil
+	}
+	encodedMetadata := segments[1]
 
 	decodedMetadata, err := base64.RawURLEncoding.DecodeString(encodedMetadata)
 	if err != nil {
@@ -69,5 +112,9 @@ func extractExpirationTimeFromToken(token string) (time.Time, error) {
 		return time.Time{}, fmt.Errorf("invalid token: %s", err.Error())
 	}
 
+	if metadata.ExpTime == 0 {
+		return time.Time{}, nil
+	}
+
 	return time.Unix(metadata.ExpTime, 0), nil
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -101,6 +102,100 @@ var _ = Describe("Token", func() {
 		})
 	})
 
+	Context("when the token is requested concurrently", func() {
+		It("only fetches it once", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					token, err := tokenGetter.Token()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(token).To(Equal(tenMinutesToken))
+				}()
+			}
+			wg.Wait()
+
+			Expect(fakeGetToken.CallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the token is about to expire", func() {
+		BeforeEach(func() {
+			soonExpiringToken, err := aTokenExpiringIn(10 * time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			fakeGetToken.ReturnsOnCall(0, soonExpiringToken, nil)
+		})
+
+		It("refreshes it already", func() {
+			_, err := tokenGetter.Token()
+			Expect(err).NotTo(HaveOccurred())
+
+			token, err := tokenGetter.Token()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal(twentyMinutesToken))
+		})
+	})
+
+	Context("when the token is prefixed with its type", func() {
+		BeforeEach(func() {
+			fakeGetToken.ReturnsOnCall(0, "bearer "+tenMinutesToken, nil)
+		})
+
+		It("reads its expiry", func() {
+			_, err := tokenGetter.Token()
+			Expect(err).NotTo(HaveOccurred())
+
+			token, err := tokenGetter.Token()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("bearer " + tenMinutesToken))
+			Expect(fakeGetToken.CallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the token is opaque", func() {
+		BeforeEach(func() {
+			fakeGetToken.ReturnsOnCall(0, "bearer opaque-token", nil)
+		})
+
+		It("reuses it for a while", func() {
+			token, err := tokenGetter.Token()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("bearer opaque-token"))
+
+			_, err = tokenGetter.Token()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeGetToken.CallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the token is invalidated", func() {
+		It("returns a new token", func() {
+			token, err := tokenGetter.Token()
+			Expect(err).NotTo(HaveOccurred())
+
+			tokenGetter.Invalidate(token)
+
+			token, err = tokenGetter.Token()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal(twentyMinutesToken))
+		})
+
+		It("keeps a token that already replaced it", func() {
+			_, err := tokenGetter.Token()
+			Expect(err).NotTo(HaveOccurred())
+
+			tokenGetter.Invalidate("an-older-token")
+
+			token, err := tokenGetter.Token()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal(tenMinutesToken))
+			Expect(fakeGetToken.CallCount()).To(Equal(1))
+		})
+	})
+
 	Context("when the token lifetime expires", func() {
 		BeforeEach(func() {
 			expiredToken, err := anExpiredToken()
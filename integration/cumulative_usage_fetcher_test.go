@@ -1,6 +1,7 @@
 package integration_test
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -25,7 +26,7 @@ var _ = Describe("Cumulative Usage Fetcher", func() {
 	)
 
 	getUsages := func(appGUID string, appInstances map[int]cf.Instance) map[int]interface{} {
-		usages, err := fetcher.FetchInstanceData(logger, appGUID, appInstances)
+		usages, err := fetcher.FetchInstanceData(context.Background(), logger, appGUID, appInstances)
 		Expect(err).NotTo(HaveOccurred())
 		return usages
 	}
@@ -96,14 +97,14 @@ var _ = Describe("Cumulative Usage Fetcher", func() {
 		BeforeEach(func() {
 			logCacheClient := logcache.NewClient(
 				"http://1.2.3:123",
-				logcache.WithHTTPClient(httpclient.NewAuthClient(getToken)),
+				logcache.WithHTTPClient(httpclient.NewAuthClient(httpclient.NewTokenGetter(getToken), logCacheHttpClient)),
 			)
 
 			fetcher = fetchers.NewCumulativeUsageFetcher(logCacheClient)
 		})
 
 		It("returns an error about the url", func() {
-			_, err := fetcher.FetchInstanceData(logger, "anything", nil)
+			_, err := fetcher.FetchInstanceData(context.Background(), logger, "anything", nil)
 			Expect(err).To(MatchError(ContainSubstring("dial")))
 		})
 	})
@@ -1,6 +1,7 @@
 package integration_test
 
 import (
+	"context"
 	"time"
 
 	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
@@ -19,7 +20,7 @@ var _ = Describe("CurrentUsage", func() {
 
 	getCurrentUsage := func(appID string, instanceID int, processInstanceID string) func() float64 {
 		return func() float64 {
-			usages, err := fetcher.FetchInstanceData(logger, appID, map[int]cf.Instance{instanceID: {InstanceID: instanceID, ProcessInstanceID: processInstanceID}})
+			usages, err := fetcher.FetchInstanceData(context.Background(), logger, appID, map[int]cf.Instance{instanceID: {InstanceID: instanceID, ProcessInstanceID: processInstanceID}})
 			ExpectWithOffset(1, err).NotTo(HaveOccurred())
 			if len(usages) != 1 {
 				return -1
@@ -28,6 +28,7 @@ var (
 	cfApi                string
 	logEmitterHttpClient *http.Client
 	logCacheClient       *logcache.Client
+	logCacheHttpClient   *http.Client
 	getToken             func() (string, error)
 	logger               lager.Logger
 )
@@ -47,9 +48,12 @@ var _ = BeforeSuite(func() {
 	getToken = func() (string, error) {
 		return getCmdOutput("cf", "oauth-token"), nil
 	}
+	var err error
+	logCacheHttpClient, err = httpclient.NewHTTPClient(httpclient.Config{})
+	Expect(err).NotTo(HaveOccurred())
 	logCacheClient = logcache.NewClient(
 		logCacheURL,
-		logcache.WithHTTPClient(httpclient.NewAuthClient(getToken)),
+		logcache.WithHTTPClient(httpclient.NewAuthClient(httpclient.NewTokenGetter(getToken), logCacheHttpClient)),
 	)
 	logger = lagertest.NewTestLogger("cumulative-usage-fetcher-test")
 })
@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
@@ -22,7 +23,7 @@ var _ = Describe("Last Spike Fetcher", func() {
 	)
 
 	getSpikes := func(appGuid string, instanceMap map[int]cf.Instance) map[int]interface{} {
-		spikes, err := fetcher.FetchInstanceData(logger, appGuid, instanceMap)
+		spikes, err := fetcher.FetchInstanceData(context.Background(), logger, appGuid, instanceMap)
 		Expect(err).NotTo(HaveOccurred())
 		return spikes
 	}
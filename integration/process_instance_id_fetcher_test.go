@@ -1,6 +1,8 @@
 package integration_test
 
 import (
+	"context"
+
 	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
 	"github.com/google/uuid"
 	"github.com/masters-of-cats/test-log-emitter/emitters"
@@ -44,7 +46,7 @@ var _ = Describe("ProcessInstanceIDFetcher", func() {
 	})
 
 	It("fetches the last process instance id for each instance", func() {
-		Eventually(func() (map[int]string, error) { return fetcher.Fetch(logger, appID) }, "15s").Should(Equal(map[int]string{
+		Eventually(func() (map[int]string, error) { return fetcher.Fetch(context.Background(), logger, appID) }, "15s").Should(Equal(map[int]string{
 			1: "1b",
 			2: "2a",
 			3: "3b",
@@ -0,0 +1,341 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+	"code.cloudfoundry.org/lager"
+)
+
+// AppJSONSchemaVersion is the version of the document written by
+// AppJSONRenderer. It is bumped whenever a field is removed or changes its
+// meaning. Fields may be added without bumping the version.
+const AppJSONSchemaVersion = 1
+
+type AppJSONRenderer struct {
+	writer io.Writer
+}
+
+type appReportJSON struct {
+	SchemaVersion   int                  `json:"schema_version"`
+	Org             string               `json:"org"`
+	Space           string               `json:"space"`
+	Username        string               `json:"username"`
+	Application     string               `json:"application"`
+	UsageWindow     *timeWindowJSON      `json:"usage_window,omitempty"`
+	SpikeWindow     *spikeWindowJSON     `json:"spike_window,omitempty"`
+	Instances       []instanceReportJSON `json:"instances"`
+	Recommendations []recommendationJSON `json:"recommendations,omitempty"`
+}
+
+// recommendationJSON leaves out the recommended memory when the memory limit
+// of the process is unknown. Basis is either p95 or average.
+type recommendationJSON struct {
+	ProcessType              string  `json:"process_type"`
+	Target                   float64 `json:"target"`
+	Basis                    string  `json:"basis"`
+	Usage                    float64 `json:"usage"`
+	MemoryInMB               int64   `json:"memory_in_mb,omitempty"`
+	RecommendedMemoryInMB    int64   `json:"recommended_memory_in_mb,omitempty"`
+	InstanceCount            int     `json:"instance_count"`
+	RecommendedInstanceCount int     `json:"recommended_instance_count"`
+}
+
+type timeWindowJSON struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+}
+
+type spikeWindowJSON struct {
+	Since          time.Time `json:"since"`
+	AvailableSince time.Time `json:"available_since"`
+	Truncated      bool      `json:"truncated"`
+}
+
+type instanceReportJSON struct {
+	ProcessType     string               `json:"process_type,omitempty"`
+	InstanceID      int                  `json:"instance_id"`
+	CumulativeUsage float64              `json:"cumulative_usage"`
+	CurrentUsage    float64              `json:"current_usage"`
+	LastSpike       *spikeJSON           `json:"last_spike,omitempty"`
+	SpikeHistory    *spikeHistoryJSON    `json:"spike_history,omitempty"`
+	UsageHistory    *usageHistoryJSON    `json:"usage_history,omitempty"`
+	UsageStats      *usageStatsJSON      `json:"usage_stats,omitempty"`
+	CPURate         *cpuRateJSON         `json:"cpu_rate,omitempty"`
+	ThrottlePreview *throttlePreviewJSON `json:"throttle_preview,omitempty"`
+}
+
+// throttlePreviewJSON lists the periods the instance would have been
+// deprioritized, in the same shape as the spikes of the spike history.
+type throttlePreviewJSON struct {
+	WindowSeconds        float64             `json:"window_seconds"`
+	Periods              []spikeDurationJSON `json:"periods"`
+	Count                int                 `json:"count"`
+	TotalDurationSeconds float64             `json:"total_duration_seconds"`
+}
+
+type cpuRateJSON struct {
+	UsageCores       float64 `json:"usage_cores"`
+	EntitlementCores float64 `json:"entitlement_cores"`
+}
+
+// usageStatsJSON leaves out the percentiles when there are no samples, rather
+// than reporting them as zero.
+type usageStatsJSON struct {
+	P50         *float64 `json:"p50,omitempty"`
+	P95         *float64 `json:"p95,omitempty"`
+	P99         *float64 `json:"p99,omitempty"`
+	Max         *float64 `json:"max,omitempty"`
+	SampleCount int      `json:"sample_count"`
+}
+
+type usageHistoryJSON struct {
+	Samples []usageSampleJSON `json:"samples"`
+}
+
+type usageSampleJSON struct {
+	Time  time.Time `json:"time"`
+	Usage float64   `json:"usage"`
+}
+
+// spikeHistoryJSON has a truncated_before time when there were too many spikes
+// to read them all.
+type spikeHistoryJSON struct {
+	Spikes               []spikeDurationJSON `json:"spikes"`
+	Count                int                 `json:"count"`
+	TotalDurationSeconds float64             `json:"total_duration_seconds"`
+	TruncatedBefore      *time.Time          `json:"truncated_before,omitempty"`
+}
+
+type spikeDurationJSON struct {
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+	DurationSeconds float64   `json:"duration_seconds"`
+}
+
+type spikeJSON struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+func NewAppJSONRenderer(writer io.Writer) AppJSONRenderer {
+	return AppJSONRenderer{writer: writer}
+}
+
+// ShowApplicationReports writes each report on its own line, so that the
+// output for one app is a single document and the output for several apps
+// can be processed one document at a time.
+func (r AppJSONRenderer) ShowApplicationReports(logger lager.Logger, appReports []reporter.ApplicationReport) error {
+	logger = logger.Session("show-application-reports-json")
+	logger.Info("start")
+	defer logger.Info("end")
+
+	encoder := json.NewEncoder(r.writer)
+	for _, appReport := range appReports {
+		if err := encoder.Encode(toAppReportJSON(appReport)); err != nil {
+			logger.Error("json-encode-failed", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ShowApplicationReportsUpdate writes the new reports, so that watching apps
+// produces a stream of JSON documents.
+func (r AppJSONRenderer) ShowApplicationReportsUpdate(logger lager.Logger, previousReports, appReports []reporter.ApplicationReport) error {
+	return r.ShowApplicationReports(logger, appReports)
+}
+
+type spaceReportJSON struct {
+	SchemaVersion int                  `json:"schema_version"`
+	Org           string               `json:"org"`
+	Space         string               `json:"space"`
+	Username      string               `json:"username"`
+	Apps          []appUsageReportJSON `json:"apps"`
+}
+
+type appUsageReportJSON struct {
+	Name          string             `json:"name"`
+	InstanceCount int                `json:"instance_count"`
+	AverageUsage  float64            `json:"average_usage"`
+	CurrentUsage  float64            `json:"current_usage"`
+	WorstInstance *worstInstanceJSON `json:"worst_instance,omitempty"`
+}
+
+type worstInstanceJSON struct {
+	ProcessType     string  `json:"process_type,omitempty"`
+	InstanceID      int     `json:"instance_id"`
+	CumulativeUsage float64 `json:"cumulative_usage"`
+}
+
+// ShowSpaceReport writes the summary of the apps in the space. Apps without
+// instances have no worst instance.
+func (r AppJSONRenderer) ShowSpaceReport(logger lager.Logger, spaceReport reporter.SpaceUsageReport) error {
+	logger = logger.Session("show-space-report-json")
+	logger.Info("start")
+	defer logger.Info("end")
+
+	apps := make([]appUsageReportJSON, 0, len(spaceReport.Apps))
+	for _, app := range spaceReport.Apps {
+		appJSON := appUsageReportJSON{
+			Name:          app.ApplicationName,
+			InstanceCount: app.InstanceCount,
+			AverageUsage:  app.AverageUsage,
+			CurrentUsage:  app.CurrentUsage,
+		}
+		if app.InstanceCount > 0 {
+			appJSON.WorstInstance = &worstInstanceJSON{
+				ProcessType:     app.WorstInstance.ProcessType,
+				InstanceID:      app.WorstInstance.InstanceID,
+				CumulativeUsage: app.WorstInstance.CumulativeUsage.Value,
+			}
+		}
+		apps = append(apps, appJSON)
+	}
+
+	err := json.NewEncoder(r.writer).Encode(spaceReportJSON{
+		SchemaVersion: AppJSONSchemaVersion,
+		Org:           spaceReport.Org,
+		Space:         spaceReport.Space,
+		Username:      spaceReport.Username,
+		Apps:          apps,
+	})
+	if err != nil {
+		logger.Error("json-encode-failed", err)
+		return err
+	}
+
+	return nil
+}
+
+func toAppReportJSON(appReport reporter.ApplicationReport) appReportJSON {
+	instances := make([]instanceReportJSON, 0, len(appReport.InstanceReports))
+	for _, report := range appReport.InstanceReports {
+		instance := instanceReportJSON{
+			ProcessType:     report.ProcessType,
+			InstanceID:      report.InstanceID,
+			CumulativeUsage: report.CumulativeUsage.Value,
+			CurrentUsage:    report.CurrentUsage.Value,
+		}
+		if (report.LastSpike != reporter.LastSpike{}) {
+			instance.LastSpike = &spikeJSON{From: report.LastSpike.From, To: report.LastSpike.To}
+		}
+		if report.SpikeHistory != nil {
+			instance.SpikeHistory = toSpikeHistoryJSON(*report.SpikeHistory)
+		}
+		if report.UsageHistory != nil {
+			instance.UsageHistory = toUsageHistoryJSON(*report.UsageHistory)
+		}
+		if report.UsageStats != nil {
+			instance.UsageStats = toUsageStatsJSON(*report.UsageStats)
+		}
+		if report.CPURate != nil {
+			instance.CPURate = &cpuRateJSON{UsageCores: report.CPURate.Usage, EntitlementCores: report.CPURate.Entitlement}
+		}
+		if report.ThrottlePreview != nil {
+			instance.ThrottlePreview = toThrottlePreviewJSON(*report.ThrottlePreview)
+		}
+		instances = append(instances, instance)
+	}
+
+	reportJSON := appReportJSON{
+		SchemaVersion: AppJSONSchemaVersion,
+		Org:           appReport.Org,
+		Space:         appReport.Space,
+		Username:      appReport.Username,
+		Application:   appReport.ApplicationName,
+		Instances:     instances,
+	}
+	if !appReport.UsageWindow.IsZero() {
+		reportJSON.UsageWindow = &timeWindowJSON{Since: appReport.UsageWindow.Since, Until: appReport.UsageWindow.Until}
+	}
+	if (appReport.SpikeWindow != reporter.SpikeWindow{}) {
+		reportJSON.SpikeWindow = &spikeWindowJSON{
+			Since:          appReport.SpikeWindow.Since,
+			AvailableSince: appReport.SpikeWindow.AvailableSince,
+			Truncated:      appReport.SpikeWindow.IsTruncated(),
+		}
+	}
+	for _, recommendation := range appReport.Recommendations {
+		reportJSON.Recommendations = append(reportJSON.Recommendations, toRecommendationJSON(recommendation))
+	}
+
+	return reportJSON
+}
+
+func toRecommendationJSON(recommendation reporter.Recommendation) recommendationJSON {
+	basis := "average"
+	if recommendation.UsesP95 {
+		basis = "p95"
+	}
+
+	return recommendationJSON{
+		ProcessType:              recommendation.ProcessType,
+		Target:                   recommendation.Target,
+		Basis:                    basis,
+		Usage:                    recommendation.Usage,
+		MemoryInMB:               recommendation.MemoryInMB,
+		RecommendedMemoryInMB:    recommendation.RecommendedMemoryInMB,
+		InstanceCount:            recommendation.InstanceCount,
+		RecommendedInstanceCount: recommendation.RecommendedInstances,
+	}
+}
+
+func toThrottlePreviewJSON(throttlePreview reporter.ThrottlePreview) *throttlePreviewJSON {
+	periods := make([]spikeDurationJSON, 0, len(throttlePreview.Periods))
+	for _, period := range throttlePreview.Periods {
+		periods = append(periods, spikeDurationJSON{From: period.From, To: period.To, DurationSeconds: period.Duration().Seconds()})
+	}
+
+	return &throttlePreviewJSON{
+		WindowSeconds:        throttlePreview.Window.Seconds(),
+		Periods:              periods,
+		Count:                len(periods),
+		TotalDurationSeconds: throttlePreview.TotalDuration().Seconds(),
+	}
+}
+
+func toSpikeHistoryJSON(spikeHistory reporter.SpikeHistory) *spikeHistoryJSON {
+	spikes := make([]spikeDurationJSON, 0, len(spikeHistory.Spikes))
+	for _, spike := range spikeHistory.Spikes {
+		spikes = append(spikes, spikeDurationJSON{From: spike.From, To: spike.To, DurationSeconds: spike.Duration().Seconds()})
+	}
+
+	historyJSON := &spikeHistoryJSON{
+		Spikes:               spikes,
+		Count:                len(spikes),
+		TotalDurationSeconds: spikeHistory.TotalDuration().Seconds(),
+	}
+	if spikeHistory.IsTruncated() {
+		truncatedBefore := spikeHistory.TruncatedBefore
+		historyJSON.TruncatedBefore = &truncatedBefore
+	}
+
+	return historyJSON
+}
+
+func toUsageHistoryJSON(usageHistory reporter.UsageHistory) *usageHistoryJSON {
+	samples := make([]usageSampleJSON, 0, len(usageHistory.Samples))
+	for _, sample := range usageHistory.Samples {
+		samples = append(samples, usageSampleJSON{Time: sample.Time, Usage: sample.Value})
+	}
+
+	return &usageHistoryJSON{Samples: samples}
+}
+
+func toUsageStatsJSON(usageStats reporter.UsageStats) *usageStatsJSON {
+	if usageStats.SampleCount == 0 {
+		return &usageStatsJSON{}
+	}
+
+	return &usageStatsJSON{
+		P50:         &usageStats.P50,
+		P95:         &usageStats.P95,
+		P99:         &usageStats.P99,
+		Max:         &usageStats.Max,
+		SampleCount: usageStats.SampleCount,
+	}
+}
@@ -0,0 +1,482 @@
+package output_test
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/output"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+)
+
+var _ = Describe("App JSON Renderer", func() {
+	var (
+		appReport reporter.ApplicationReport
+		buffer    *gbytes.Buffer
+		renderer  output.AppJSONRenderer
+		renderErr error
+	)
+
+	BeforeEach(func() {
+		appReport = reporter.ApplicationReport{
+			ApplicationName: "myapp",
+			Org:             "theorg",
+			Space:           "thespace",
+			Username:        "theuser",
+			InstanceReports: []reporter.InstanceReport{
+				{
+					InstanceID:      0,
+					CumulativeUsage: reporter.CumulativeUsage{Value: 0.5},
+					CurrentUsage:    reporter.CurrentUsage{Value: 1.5},
+				},
+				{
+					InstanceID:      1,
+					CumulativeUsage: reporter.CumulativeUsage{Value: 0.75},
+					CurrentUsage:    reporter.CurrentUsage{Value: 0.25},
+					LastSpike: reporter.LastSpike{
+						From: time.Date(2019, 7, 30, 9, 0, 0, 0, time.UTC),
+						To:   time.Date(2019, 7, 31, 12, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+		}
+
+		buffer = gbytes.NewBuffer()
+		renderer = output.NewAppJSONRenderer(buffer)
+	})
+
+	JustBeforeEach(func() {
+		renderErr = renderer.ShowApplicationReports(logger, []reporter.ApplicationReport{appReport})
+	})
+
+	It("writes the report as JSON", func() {
+		Expect(renderErr).NotTo(HaveOccurred())
+		Expect(buffer.Contents()).To(MatchJSON(`{
+			"schema_version": 1,
+			"org": "theorg",
+			"space": "thespace",
+			"username": "theuser",
+			"application": "myapp",
+			"instances": [
+				{"instance_id": 0, "cumulative_usage": 0.5, "current_usage": 1.5},
+				{
+					"instance_id": 1,
+					"cumulative_usage": 0.75,
+					"current_usage": 0.25,
+					"last_spike": {"from": "2019-07-30T09:00:00Z", "to": "2019-07-31T12:00:00Z"}
+				}
+			]
+		}`))
+	})
+
+	When("the application has several processes", func() {
+		BeforeEach(func() {
+			appReport.InstanceReports = []reporter.InstanceReport{
+				{ProcessType: "web", InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 0.5}},
+				{ProcessType: "worker", InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 0.75}},
+			}
+		})
+
+		It("includes the process type of every instance", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(buffer.Contents()).To(MatchJSON(`{
+				"schema_version": 1,
+				"org": "theorg",
+				"space": "thespace",
+				"username": "theuser",
+				"application": "myapp",
+				"instances": [
+					{"process_type": "web", "instance_id": 0, "cumulative_usage": 0.5, "current_usage": 0},
+					{"process_type": "worker", "instance_id": 0, "cumulative_usage": 0.75, "current_usage": 0}
+				]
+			}`))
+		})
+	})
+
+	When("the report includes the CPU rate", func() {
+		BeforeEach(func() {
+			appReport.InstanceReports = []reporter.InstanceReport{
+				{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 0.5}, CPURate: &reporter.CPURate{Usage: 0.375, Entitlement: 0.25}},
+			}
+		})
+
+		It("includes the usage and entitlement in cores", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(buffer.Contents()).To(MatchJSON(`{
+				"schema_version": 1,
+				"org": "theorg",
+				"space": "thespace",
+				"username": "theuser",
+				"application": "myapp",
+				"instances": [
+					{
+						"instance_id": 0,
+						"cumulative_usage": 0.5,
+						"current_usage": 0,
+						"cpu_rate": {"usage_cores": 0.375, "entitlement_cores": 0.25}
+					}
+				]
+			}`))
+		})
+	})
+
+	When("the report includes a throttle preview", func() {
+		BeforeEach(func() {
+			appReport.InstanceReports = []reporter.InstanceReport{
+				{
+					InstanceID:      0,
+					CumulativeUsage: reporter.CumulativeUsage{Value: 0.5},
+					ThrottlePreview: &reporter.ThrottlePreview{
+						Window: time.Hour,
+						Periods: []reporter.Period{
+							{From: time.Date(2019, 7, 30, 9, 0, 0, 0, time.UTC), To: time.Date(2019, 7, 30, 9, 30, 0, 0, time.UTC)},
+						},
+					},
+				},
+			}
+		})
+
+		It("includes the periods the instances would have been deprioritized", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(buffer.Contents()).To(MatchJSON(`{
+				"schema_version": 1,
+				"org": "theorg",
+				"space": "thespace",
+				"username": "theuser",
+				"application": "myapp",
+				"instances": [
+					{
+						"instance_id": 0,
+						"cumulative_usage": 0.5,
+						"current_usage": 0,
+						"throttle_preview": {
+							"window_seconds": 3600,
+							"periods": [
+								{"from": "2019-07-30T09:00:00Z", "to": "2019-07-30T09:30:00Z", "duration_seconds": 1800}
+							],
+							"count": 1,
+							"total_duration_seconds": 1800
+						}
+					}
+				]
+			}`))
+		})
+	})
+
+	When("the report includes recommendations", func() {
+		BeforeEach(func() {
+			appReport.InstanceReports = nil
+			appReport.Recommendations = []reporter.Recommendation{
+				{ProcessType: "web", Target: 0.9, UsesP95: true, Usage: 1.35, MemoryInMB: 512, RecommendedMemoryInMB: 768, InstanceCount: 2, RecommendedInstances: 4},
+				{ProcessType: "worker", Target: 0.9, Usage: 1.5, InstanceCount: 1, RecommendedInstances: 2},
+			}
+		})
+
+		It("includes them", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(buffer.Contents()).To(MatchJSON(`{
+				"schema_version": 1,
+				"org": "theorg",
+				"space": "thespace",
+				"username": "theuser",
+				"application": "myapp",
+				"instances": [],
+				"recommendations": [
+					{
+						"process_type": "web",
+						"target": 0.9,
+						"basis": "p95",
+						"usage": 1.35,
+						"memory_in_mb": 512,
+						"recommended_memory_in_mb": 768,
+						"instance_count": 2,
+						"recommended_instance_count": 4
+					},
+					{
+						"process_type": "worker",
+						"target": 0.9,
+						"basis": "average",
+						"usage": 1.5,
+						"instance_count": 1,
+						"recommended_instance_count": 2
+					}
+				]
+			}`))
+		})
+	})
+
+	When("the usage is averaged over a time window", func() {
+		BeforeEach(func() {
+			appReport.UsageWindow = reporter.TimeWindow{
+				Since: time.Date(2019, 7, 30, 9, 0, 0, 0, time.UTC),
+				Until: time.Date(2019, 7, 31, 12, 0, 0, 0, time.UTC),
+			}
+			appReport.InstanceReports = nil
+		})
+
+		It("includes the time window", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(buffer.Contents()).To(MatchJSON(`{
+				"schema_version": 1,
+				"org": "theorg",
+				"space": "thespace",
+				"username": "theuser",
+				"application": "myapp",
+				"usage_window": {"since": "2019-07-30T09:00:00Z", "until": "2019-07-31T12:00:00Z"},
+				"instances": []
+			}`))
+		})
+	})
+
+	When("the spike window has been checked", func() {
+		BeforeEach(func() {
+			appReport.SpikeWindow = reporter.SpikeWindow{
+				Since:          time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC),
+				AvailableSince: time.Date(2019, 7, 29, 8, 0, 0, 0, time.UTC),
+			}
+			appReport.InstanceReports = nil
+		})
+
+		It("includes the spike window", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(buffer.Contents()).To(MatchJSON(`{
+				"schema_version": 1,
+				"org": "theorg",
+				"space": "thespace",
+				"username": "theuser",
+				"application": "myapp",
+				"spike_window": {"since": "2019-07-01T00:00:00Z", "available_since": "2019-07-29T08:00:00Z", "truncated": true},
+				"instances": []
+			}`))
+		})
+	})
+
+	When("the report includes the usage history", func() {
+		BeforeEach(func() {
+			appReport.InstanceReports[0].UsageHistory = &reporter.UsageHistory{
+				Samples: []reporter.UsageSample{
+					{Time: time.Date(2019, 7, 30, 9, 0, 0, 0, time.UTC), Value: 0.5},
+					{Time: time.Date(2019, 7, 30, 9, 1, 0, 0, time.UTC), Value: 1.25},
+				},
+			}
+			appReport.InstanceReports[1].UsageHistory = &reporter.UsageHistory{}
+		})
+
+		It("includes the samples", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(buffer.Contents()).To(MatchJSON(`{
+				"schema_version": 1,
+				"org": "theorg",
+				"space": "thespace",
+				"username": "theuser",
+				"application": "myapp",
+				"instances": [
+					{
+						"instance_id": 0,
+						"cumulative_usage": 0.5,
+						"current_usage": 1.5,
+						"usage_history": {
+							"samples": [
+								{"time": "2019-07-30T09:00:00Z", "usage": 0.5},
+								{"time": "2019-07-30T09:01:00Z", "usage": 1.25}
+							]
+						}
+					},
+					{
+						"instance_id": 1,
+						"cumulative_usage": 0.75,
+						"current_usage": 0.25,
+						"last_spike": {"from": "2019-07-30T09:00:00Z", "to": "2019-07-31T12:00:00Z"},
+						"usage_history": {"samples": []}
+					}
+				]
+			}`))
+		})
+	})
+
+	When("the report includes usage stats", func() {
+		BeforeEach(func() {
+			appReport.InstanceReports[0].UsageStats = &reporter.UsageStats{P50: 0.5, P95: 0.9, P99: 1.2, Max: 1.5, SampleCount: 30}
+			appReport.InstanceReports[1].UsageStats = &reporter.UsageStats{}
+		})
+
+		It("includes the stats, leaving out the percentiles of instances without samples", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(buffer.Contents()).To(MatchJSON(`{
+				"schema_version": 1,
+				"org": "theorg",
+				"space": "thespace",
+				"username": "theuser",
+				"application": "myapp",
+				"instances": [
+					{
+						"instance_id": 0,
+						"cumulative_usage": 0.5,
+						"current_usage": 1.5,
+						"usage_stats": {"p50": 0.5, "p95": 0.9, "p99": 1.2, "max": 1.5, "sample_count": 30}
+					},
+					{
+						"instance_id": 1,
+						"cumulative_usage": 0.75,
+						"current_usage": 0.25,
+						"last_spike": {"from": "2019-07-30T09:00:00Z", "to": "2019-07-31T12:00:00Z"},
+						"usage_stats": {"sample_count": 0}
+					}
+				]
+			}`))
+		})
+	})
+
+	When("the report includes the spike history", func() {
+		BeforeEach(func() {
+			appReport.InstanceReports[0].SpikeHistory = &reporter.SpikeHistory{}
+			appReport.InstanceReports[1].SpikeHistory = &reporter.SpikeHistory{
+				Spikes: []reporter.Spike{
+					{From: time.Date(2019, 7, 29, 2, 0, 0, 0, time.UTC), To: time.Date(2019, 7, 29, 2, 30, 0, 0, time.UTC)},
+					{From: time.Date(2019, 7, 30, 9, 0, 0, 0, time.UTC), To: time.Date(2019, 7, 31, 12, 0, 0, 0, time.UTC)},
+				},
+			}
+		})
+
+		It("includes the spikes and their totals", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(buffer.Contents()).To(MatchJSON(`{
+				"schema_version": 1,
+				"org": "theorg",
+				"space": "thespace",
+				"username": "theuser",
+				"application": "myapp",
+				"instances": [
+					{
+						"instance_id": 0,
+						"cumulative_usage": 0.5,
+						"current_usage": 1.5,
+						"spike_history": {"spikes": [], "count": 0, "total_duration_seconds": 0}
+					},
+					{
+						"instance_id": 1,
+						"cumulative_usage": 0.75,
+						"current_usage": 0.25,
+						"last_spike": {"from": "2019-07-30T09:00:00Z", "to": "2019-07-31T12:00:00Z"},
+						"spike_history": {
+							"spikes": [
+								{"from": "2019-07-29T02:00:00Z", "to": "2019-07-29T02:30:00Z", "duration_seconds": 1800},
+								{"from": "2019-07-30T09:00:00Z", "to": "2019-07-31T12:00:00Z", "duration_seconds": 97200}
+							],
+							"count": 2,
+							"total_duration_seconds": 99000
+						}
+					}
+				]
+			}`))
+		})
+	})
+
+	When("there were too many spikes to read them all", func() {
+		BeforeEach(func() {
+			appReport.InstanceReports[0].SpikeHistory = &reporter.SpikeHistory{
+				TruncatedBefore: time.Date(2019, 7, 29, 1, 0, 0, 0, time.UTC),
+			}
+		})
+
+		It("says which spikes are missing", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(buffer.Contents()).To(ContainSubstring(`"spike_history":{"spikes":[],"count":0,"total_duration_seconds":0,"truncated_before":"2019-07-29T01:00:00Z"}`))
+		})
+	})
+
+	When("there are no instances of the application", func() {
+		BeforeEach(func() {
+			appReport.InstanceReports = nil
+		})
+
+		It("writes an empty list of instances", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(buffer.Contents()).To(MatchJSON(`{
+				"schema_version": 1,
+				"org": "theorg",
+				"space": "thespace",
+				"username": "theuser",
+				"application": "myapp",
+				"instances": []
+			}`))
+		})
+	})
+
+	When("writing the report fails", func() {
+		BeforeEach(func() {
+			renderer = output.NewAppJSONRenderer(failingWriter{})
+		})
+
+		It("returns the error", func() {
+			Expect(renderErr).To(MatchError("write-failed"))
+		})
+	})
+})
+
+var _ = Describe("App JSON Renderer for multiple apps", func() {
+	It("writes a document per app, one per line", func() {
+		buffer := gbytes.NewBuffer()
+		renderer := output.NewAppJSONRenderer(buffer)
+
+		Expect(renderer.ShowApplicationReports(logger, []reporter.ApplicationReport{
+			{ApplicationName: "app-1"},
+			{ApplicationName: "app-2"},
+		})).To(Succeed())
+
+		lines := strings.Split(strings.TrimSpace(string(buffer.Contents())), "\n")
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[0]).To(MatchJSON(`{"schema_version": 1, "org": "", "space": "", "username": "", "application": "app-1", "instances": []}`))
+		Expect(lines[1]).To(MatchJSON(`{"schema_version": 1, "org": "", "space": "", "username": "", "application": "app-2", "instances": []}`))
+	})
+})
+
+var _ = Describe("App JSON Renderer for spaces", func() {
+	It("writes the summary of every app", func() {
+		buffer := gbytes.NewBuffer()
+		renderer := output.NewAppJSONRenderer(buffer)
+
+		Expect(renderer.ShowSpaceReport(logger, reporter.SpaceUsageReport{
+			Org:      "theorg",
+			Space:    "thespace",
+			Username: "theuser",
+			Apps: []reporter.AppUsageReport{
+				{
+					ApplicationName: "busy",
+					InstanceCount:   2,
+					AverageUsage:    1,
+					CurrentUsage:    1.5,
+					WorstInstance:   reporter.InstanceReport{InstanceID: 1, CumulativeUsage: reporter.CumulativeUsage{Value: 1.25}},
+				},
+				{ApplicationName: "stopped"},
+			},
+		})).To(Succeed())
+
+		Expect(buffer.Contents()).To(MatchJSON(`{
+			"schema_version": 1,
+			"org": "theorg",
+			"space": "thespace",
+			"username": "theuser",
+			"apps": [
+				{
+					"name": "busy",
+					"instance_count": 2,
+					"average_usage": 1,
+					"current_usage": 1.5,
+					"worst_instance": {"instance_id": 1, "cumulative_usage": 1.25}
+				},
+				{"name": "stopped", "instance_count": 0, "average_usage": 0, "current_usage": 0}
+			]
+		}`))
+	})
+})
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write-failed")
+}
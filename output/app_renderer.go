@@ -2,8 +2,11 @@ package output
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"code.cloudfoundry.org/cli/cf/terminal"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
 	"code.cloudfoundry.org/lager"
 	"github.com/fatih/color"
@@ -13,57 +16,217 @@ const DateFmt = "2006-01-02 15:04:05"
 const noColor color.Attribute = -1
 
 type AppRenderer struct {
-	display AppDisplay
+	display    AppDisplay
+	thresholds reporter.Thresholds
+	units      Units
 }
 
+// Units tells whether usage is shown as a ratio of the entitlement, in cores,
+// or both.
+type Units string
+
+const (
+	UnitsRatio Units = "ratio"
+	UnitsCores Units = "cores"
+	UnitsBoth  Units = "both"
+)
+
 //go:generate counterfeiter . AppDisplay
 
 type AppDisplay interface {
 	ShowMessage(message string, values ...interface{})
 	ShowTable(logger lager.Logger, headers []string, rows [][]string) error
+	Clear()
 }
 
 func NewAppRenderer(display AppDisplay) AppRenderer {
-	return AppRenderer{display: display}
+	return AppRenderer{display: display, thresholds: reporter.DefaultThresholds, units: UnitsRatio}
+}
+
+// WithThresholds changes when instances are highlighted as over or near
+// their entitlement.
+func (r AppRenderer) WithThresholds(thresholds reporter.Thresholds) AppRenderer {
+	r.thresholds = thresholds
+	return r
+}
+
+// WithUnits changes how the usage of instances is shown. Usage in cores
+// requires the reports to include the CPU rate of the instances.
+func (r AppRenderer) WithUnits(units Units) AppRenderer {
+	r.units = units
+	return r
+}
+
+// ShowApplicationReports shows a section for each report, one after the
+// other.
+func (r AppRenderer) ShowApplicationReports(logger lager.Logger, appReports []reporter.ApplicationReport) error {
+	logger = logger.Session("show-application-reports")
+	logger.Info("start")
+	defer logger.Info("end")
+
+	for i, appReport := range appReports {
+		if i > 0 {
+			r.display.ShowMessage("")
+		}
+		if err := r.showApplicationReport(logger, appReport, map[string]bool{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func (r AppRenderer) ShowApplicationReport(logger lager.Logger, appReport reporter.ApplicationReport) error {
-	logger = logger.Session("show-application-report")
+// ShowApplicationReportsUpdate redraws all the sections in place. The
+// instances of each app are compared with the previous report of the same app.
+func (r AppRenderer) ShowApplicationReportsUpdate(logger lager.Logger, previousReports, appReports []reporter.ApplicationReport) error {
+	logger = logger.Session("show-application-reports-update")
 	logger.Info("start")
 	defer logger.Info("end")
 
+	previousReportsByApp := map[string]reporter.ApplicationReport{}
+	for _, previousReport := range previousReports {
+		previousReportsByApp[previousReport.ApplicationName] = previousReport
+	}
+
+	r.display.Clear()
+
+	for i, appReport := range appReports {
+		if i > 0 {
+			r.display.ShowMessage("")
+		}
+		crossedInstances := crossedEntitlement(previousReportsByApp[appReport.ApplicationName], appReport, r.thresholds)
+		if err := r.showApplicationReport(logger, appReport, crossedInstances); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ShowSpaceReport shows a row per app, with the apps over or near their
+// entitlement highlighted.
+func (r AppRenderer) ShowSpaceReport(logger lager.Logger, spaceReport reporter.SpaceUsageReport) error {
+	logger = logger.Session("show-space-report")
+	logger.Info("start")
+	defer logger.Info("end")
+
+	r.display.ShowMessage("Showing CPU usage against entitlement for apps in org %s / space %s as %s ...\n",
+		terminal.EntityNameColor(spaceReport.Org),
+		terminal.EntityNameColor(spaceReport.Space),
+		terminal.EntityNameColor(spaceReport.Username),
+	)
+
+	if len(spaceReport.Apps) == 0 {
+		r.display.ShowMessage("There are no apps in this space.")
+		return nil
+	}
+
+	var rows [][]string
+	for _, app := range spaceReport.Apps {
+		if app.InstanceCount == 0 {
+			rows = append(rows, []string{app.ApplicationName, "0", "-", "-", "-"})
+			continue
+		}
+
+		rowColor := noColor
+		if r.thresholds.IsOver(app.WorstInstance.CumulativeUsage.Value) {
+			rowColor = color.FgRed
+		} else if r.thresholds.IsNear(app.WorstInstance.CumulativeUsage.Value) {
+			rowColor = color.FgYellow
+		}
+		rows = append(rows, colorizeRow([]string{
+			app.ApplicationName,
+			fmt.Sprintf("%d", app.InstanceCount),
+			fmt.Sprintf("%.2f%%", app.AverageUsage*100),
+			fmt.Sprintf("%.2f%%", app.CurrentUsage*100),
+			fmt.Sprintf("%s (%.2f%%)", instanceName(app.WorstInstance), app.WorstInstance.CumulativeUsage.Value*100),
+		}, rowColor))
+	}
+
+	return r.display.ShowTable(logger, []string{
+		terminal.Colorize("app", color.Bold),
+		terminal.Colorize("instances", color.Bold),
+		terminal.Colorize("avg usage", color.Bold),
+		terminal.Colorize("curr usage", color.Bold),
+		terminal.Colorize("worst instance", color.Bold),
+	}, rows)
+}
+
+func (r AppRenderer) showApplicationReport(logger lager.Logger, appReport reporter.ApplicationReport, crossedInstances map[string]bool) error {
 	r.showAppInfoHeader(appReport)
+	r.showUsageWindow(appReport)
 
 	if len(appReport.InstanceReports) == 0 {
 		r.display.ShowMessage("There are no running instances of this application.")
 		return nil
 	}
 
-	if err := r.showTable(logger, appReport); err != nil {
+	if err := r.showTable(logger, appReport, crossedInstances); err != nil {
 		return err
 	}
+	r.showCrossedInstances(appReport, crossedInstances)
 	r.showMessage(appReport)
-	r.showPastSpikes(appReport)
+
+	if hasSpikeHistory(appReport) {
+		if err := r.showSpikeHistory(logger, appReport); err != nil {
+			return err
+		}
+	} else {
+		r.showPastSpikes(appReport)
+	}
+	r.showSpikeWindow(appReport)
+
+	if hasThrottlePreview(appReport) {
+		if err := r.showThrottlePreview(logger, appReport); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-func (r AppRenderer) showTable(logger lager.Logger, appReport reporter.ApplicationReport) error {
+func (r AppRenderer) showTable(logger lager.Logger, appReport reporter.ApplicationReport, crossedInstances map[string]bool) error {
+	headers := []string{"", terminal.Colorize("avg usage", color.Bold), terminal.Colorize("curr usage", color.Bold)}
+	if r.units != UnitsRatio {
+		headers = append(headers, terminal.Colorize("entitlement", color.Bold))
+	}
+	showUsageStats := hasUsageStats(appReport)
+	showUsageHistory := hasUsageHistory(appReport)
+	scale := sparklineScale(appReport)
+	if showUsageStats {
+		headers = append(headers,
+			terminal.Colorize("p50", color.Bold),
+			terminal.Colorize("p95", color.Bold),
+			terminal.Colorize("p99", color.Bold),
+			terminal.Colorize("max", color.Bold),
+		)
+	}
+	if showUsageHistory {
+		headers = append(headers, terminal.Colorize("history", color.Bold))
+	}
+
 	var rows [][]string
 	for _, report := range appReport.InstanceReports {
 		rowColor := noColor
-		instanceID := fmt.Sprintf("#%d", report.InstanceID)
-		avgEntitlementRatio := fmt.Sprintf("%.2f%%", report.CumulativeUsage.Value*100)
-		if report.CumulativeUsage.Value > 1 {
+		instanceID := instanceName(report)
+		if crossedInstances[instanceID] {
+			rowColor = color.FgMagenta
+		} else if r.thresholds.IsOver(report.CumulativeUsage.Value) {
 			rowColor = color.FgRed
-		} else if report.CumulativeUsage.Value > 0.95 {
+		} else if r.thresholds.IsNear(report.CumulativeUsage.Value) {
 			rowColor = color.FgYellow
 		}
-		currEntitlementRatio := fmt.Sprintf("%.2f%%", report.CurrentUsage.Value*100)
-		rows = append(rows, colorizeRow([]string{instanceID, avgEntitlementRatio, currEntitlementRatio}, rowColor))
+		row := append([]string{instanceID}, r.usageColumns(report)...)
+		if showUsageStats {
+			row = append(row, usageStatsColumns(report)...)
+		}
+		if showUsageHistory {
+			row = append(row, usageHistorySparkline(report, scale))
+		}
+		rows = append(rows, colorizeRow(row, rowColor))
 	}
 
-	err := r.display.ShowTable(logger, []string{"", terminal.Colorize("avg usage", color.Bold), terminal.Colorize("curr usage", color.Bold)}, rows)
+	err := r.display.ShowTable(logger, headers, rows)
 	if err != nil {
 		return err
 	}
@@ -71,14 +234,63 @@ func (r AppRenderer) showTable(logger lager.Logger, appReport reporter.Applicati
 	return nil
 }
 
+// usageColumns returns the average and current usage of the instance in the
+// configured units, followed by its entitlement unless only ratios are shown.
+// The average usage in cores is derived from the entitlement, which only
+// changes when the app is scaled.
+func (r AppRenderer) usageColumns(report reporter.InstanceReport) []string {
+	avgRatio := fmt.Sprintf("%.2f%%", report.CumulativeUsage.Value*100)
+	currRatio := fmt.Sprintf("%.2f%%", report.CurrentUsage.Value*100)
+	if r.units == UnitsRatio {
+		return []string{avgRatio, currRatio}
+	}
+
+	if report.CPURate == nil {
+		if r.units == UnitsBoth {
+			return []string{avgRatio, currRatio, "-"}
+		}
+		return []string{"-", "-", "-"}
+	}
+
+	avgCores := formatCores(report.CumulativeUsage.Value * report.CPURate.Entitlement)
+	currCores := formatCores(report.CPURate.Usage)
+	entitlement := formatCores(report.CPURate.Entitlement)
+	if r.units == UnitsBoth {
+		return []string{
+			fmt.Sprintf("%s (%s)", avgRatio, avgCores),
+			fmt.Sprintf("%s (%s)", currRatio, currCores),
+			entitlement,
+		}
+	}
+
+	return []string{avgCores, currCores, entitlement}
+}
+
+func formatCores(cores float64) string {
+	return fmt.Sprintf("%.3f cores", cores)
+}
+
+func (r AppRenderer) showCrossedInstances(appReport reporter.ApplicationReport, crossedInstances map[string]bool) {
+	var instanceIDs []string
+	for _, report := range appReport.InstanceReports {
+		if crossedInstances[instanceName(report)] {
+			instanceIDs = append(instanceIDs, instanceName(report))
+		}
+	}
+
+	if len(instanceIDs) > 0 {
+		r.display.ShowMessage(terminal.Colorize(fmt.Sprintf("WARNING: Instances %s went over their CPU entitlement since the last update.", strings.Join(instanceIDs, ", ")), color.FgMagenta))
+	}
+}
+
 func (r AppRenderer) showMessage(appReport reporter.ApplicationReport) {
 	var status string
 	var level string
 	for _, report := range appReport.InstanceReports {
-		if report.CumulativeUsage.Value > 1 {
+		if r.thresholds.IsOver(report.CumulativeUsage.Value) {
 			status = "over"
 			level = "WARNING"
-		} else if report.CumulativeUsage.Value > 0.95 {
+		} else if r.thresholds.IsNear(report.CumulativeUsage.Value) {
 			if status == "" {
 				status = "near"
 				level = "TIP"
@@ -86,27 +298,175 @@ func (r AppRenderer) showMessage(appReport reporter.ApplicationReport) {
 		}
 	}
 
+	if len(appReport.Recommendations) == 0 {
+		if status != "" {
+			r.display.ShowMessage(terminal.Colorize(fmt.Sprintf("%s: Some instances are %s their CPU entitlement. Consider scaling your memory or instances.", level, status), color.FgCyan))
+		}
+		return
+	}
+
 	if status != "" {
-		r.display.ShowMessage(terminal.Colorize(fmt.Sprintf("%s: Some instances are %s their CPU entitlement. Consider scaling your memory or instances.", level, status), color.FgCyan))
+		r.display.ShowMessage(terminal.Colorize(fmt.Sprintf("%s: Some instances are %s their CPU entitlement.", level, status), color.FgCyan))
+	}
+	for _, recommendation := range appReport.Recommendations {
+		r.display.ShowMessage(terminal.Colorize("TIP: "+recommendationMessage(recommendation), color.FgCyan))
+	}
+}
+
+// recommendationMessage tells by how much to scale a process, e.g. "To bring
+// the p95 usage below 90% of entitlement, scale memory from 512M to 768M, or
+// add 2 instances if load is spread evenly."
+func recommendationMessage(recommendation reporter.Recommendation) string {
+	usage := "the average usage"
+	if recommendation.UsesP95 {
+		usage = "the p95 usage"
 	}
+	if recommendation.ProcessType != "" && recommendation.ProcessType != cf.WebProcessType {
+		usage += fmt.Sprintf(" of the %s process", recommendation.ProcessType)
+	}
+
+	var suggestions []string
+	if recommendation.RecommendedMemoryInMB > recommendation.MemoryInMB {
+		suggestions = append(suggestions, fmt.Sprintf("scale memory from %s to %s",
+			formatMemory(recommendation.MemoryInMB), formatMemory(recommendation.RecommendedMemoryInMB)))
+	}
+	if recommendation.RecommendedInstances > recommendation.InstanceCount {
+		suggestions = append(suggestions, fmt.Sprintf("add %s if load is spread evenly",
+			pluralize(recommendation.RecommendedInstances-recommendation.InstanceCount, "instance")))
+	}
+	if len(suggestions) == 0 {
+		return fmt.Sprintf("Scale the memory or instances to bring %s below %.0f%% of entitlement.", usage, recommendation.Target*100)
+	}
+
+	return fmt.Sprintf("To bring %s below %.0f%% of entitlement, %s.", usage, recommendation.Target*100, strings.Join(suggestions, ", or "))
+}
+
+// formatMemory formats a memory limit the way the cf CLI accepts it.
+func formatMemory(memoryInMB int64) string {
+	if memoryInMB >= 1024 && memoryInMB%1024 == 0 {
+		return fmt.Sprintf("%dG", memoryInMB/1024)
+	}
+
+	return fmt.Sprintf("%dM", memoryInMB)
 }
 
 func (r AppRenderer) showPastSpikes(appReport reporter.ApplicationReport) {
 	var reportsWithSpikes []reporter.InstanceReport
 	for _, report := range appReport.InstanceReports {
-		if (report.LastSpike != reporter.LastSpike{}) && (report.CumulativeUsage.Value <= 1) {
+		if (report.LastSpike != reporter.LastSpike{}) && !r.thresholds.IsOver(report.CumulativeUsage.Value) {
 			reportsWithSpikes = append(reportsWithSpikes, report)
 		}
 	}
 
 	for _, reportWithSpike := range reportsWithSpikes {
 		r.display.ShowMessage(terminal.Colorize(
-			fmt.Sprintf("WARNING: Instance #%d was over entitlement from %s to %s", reportWithSpike.InstanceID, reportWithSpike.LastSpike.From.Format(DateFmt), reportWithSpike.LastSpike.To.Format(DateFmt)),
+			fmt.Sprintf("WARNING: Instance %s was over entitlement from %s to %s", instanceName(reportWithSpike), reportWithSpike.LastSpike.From.Format(DateFmt), reportWithSpike.LastSpike.To.Format(DateFmt)),
 			color.FgYellow,
 		))
 	}
 }
 
+func (r AppRenderer) showSpikeHistory(logger lager.Logger, appReport reporter.ApplicationReport) error {
+	var rows [][]string
+	var totals []string
+	for _, report := range appReport.InstanceReports {
+		if report.SpikeHistory == nil || len(report.SpikeHistory.Spikes) == 0 {
+			continue
+		}
+
+		instanceID := instanceName(report)
+		for _, spike := range report.SpikeHistory.Spikes {
+			rows = append(rows, []string{instanceID, spike.From.Format(DateFmt), spike.To.Format(DateFmt), spike.Duration().String()})
+		}
+		totals = append(totals, fmt.Sprintf("Instance %s was over entitlement %s for a total of %s",
+			instanceID, pluralize(len(report.SpikeHistory.Spikes), "time"), report.SpikeHistory.TotalDuration()))
+	}
+
+	if len(rows) == 0 {
+		r.display.ShowMessage("No instances of this application have been over entitlement.")
+		return nil
+	}
+
+	err := r.display.ShowTable(logger, []string{"", terminal.Colorize("spike start", color.Bold), terminal.Colorize("spike end", color.Bold), terminal.Colorize("duration", color.Bold)}, rows)
+	if err != nil {
+		return err
+	}
+
+	for _, total := range totals {
+		r.display.ShowMessage(terminal.Colorize(total, color.FgYellow))
+	}
+
+	return nil
+}
+
+// showThrottlePreview lists when each instance would have been deprioritized
+// under the rolling window policy.
+func (r AppRenderer) showThrottlePreview(logger lager.Logger, appReport reporter.ApplicationReport) error {
+	var window time.Duration
+	var rows [][]string
+	var totals []string
+	for _, report := range appReport.InstanceReports {
+		if report.ThrottlePreview == nil {
+			continue
+		}
+
+		window = report.ThrottlePreview.Window
+		if !report.ThrottlePreview.IsDeprioritized() {
+			continue
+		}
+
+		instanceID := instanceName(report)
+		for _, period := range report.ThrottlePreview.Periods {
+			rows = append(rows, []string{instanceID, period.From.Format(DateFmt), period.To.Format(DateFmt), period.Duration().String()})
+		}
+		totals = append(totals, fmt.Sprintf("Instance %s would have been deprioritized %s for a total of %s",
+			instanceID, pluralize(len(report.ThrottlePreview.Periods), "time"), report.ThrottlePreview.TotalDuration()))
+	}
+
+	if len(rows) == 0 {
+		r.display.ShowMessage("No instances of this application would have been deprioritized with a rolling window of %s.", window)
+		return nil
+	}
+
+	r.display.ShowMessage("\nInstances that would have been deprioritized with a rolling window of %s:\n", window)
+	err := r.display.ShowTable(logger, []string{"", terminal.Colorize("deprioritized from", color.Bold), terminal.Colorize("deprioritized until", color.Bold), terminal.Colorize("duration", color.Bold)}, rows)
+	if err != nil {
+		return err
+	}
+
+	for _, total := range totals {
+		r.display.ShowMessage(terminal.Colorize(total, color.FgYellow))
+	}
+
+	return nil
+}
+
+func (r AppRenderer) showSpikeWindow(appReport reporter.ApplicationReport) {
+	if appReport.SpikeWindow.IsTruncated() {
+		r.display.ShowMessage(terminal.Colorize(
+			fmt.Sprintf("NOTE: Log-cache only holds data for this application since %s, so spikes before then are not shown.", appReport.SpikeWindow.AvailableSince.Format(DateFmt)),
+			color.FgCyan,
+		))
+	}
+
+	if truncatedBefore, ok := spikeHistoryTruncatedBefore(appReport); ok {
+		r.display.ShowMessage(terminal.Colorize(
+			fmt.Sprintf("NOTE: This application has too many spikes to read them all, so spikes before %s are not shown.", truncatedBefore.Format(DateFmt)),
+			color.FgCyan,
+		))
+	}
+}
+
+func spikeHistoryTruncatedBefore(appReport reporter.ApplicationReport) (time.Time, bool) {
+	for _, report := range appReport.InstanceReports {
+		if report.SpikeHistory != nil && report.SpikeHistory.IsTruncated() {
+			return report.SpikeHistory.TruncatedBefore, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
 func (r AppRenderer) showAppInfoHeader(appReport reporter.ApplicationReport) {
 	r.display.ShowMessage("Showing CPU usage against entitlement for app %s in org %s / space %s as %s ...\n",
 		terminal.EntityNameColor(appReport.ApplicationName),
@@ -116,6 +476,115 @@ func (r AppRenderer) showAppInfoHeader(appReport reporter.ApplicationReport) {
 	)
 }
 
+func crossedEntitlement(previousReport, appReport reporter.ApplicationReport, thresholds reporter.Thresholds) map[string]bool {
+	previousUsages := map[string]float64{}
+	for _, report := range previousReport.InstanceReports {
+		previousUsages[instanceName(report)] = report.CurrentUsage.Value
+	}
+
+	crossedInstances := map[string]bool{}
+	for _, report := range appReport.InstanceReports {
+		previousUsage, ok := previousUsages[instanceName(report)]
+		if ok && !thresholds.IsOver(previousUsage) && thresholds.IsOver(report.CurrentUsage.Value) {
+			crossedInstances[instanceName(report)] = true
+		}
+	}
+
+	return crossedInstances
+}
+
+func (r AppRenderer) showUsageWindow(appReport reporter.ApplicationReport) {
+	if appReport.UsageWindow.IsZero() {
+		return
+	}
+
+	r.display.ShowMessage("Average usage between %s and %s:\n",
+		appReport.UsageWindow.Since.Format(DateFmt),
+		appReport.UsageWindow.Until.Format(DateFmt),
+	)
+}
+
+func hasSpikeHistory(appReport reporter.ApplicationReport) bool {
+	for _, report := range appReport.InstanceReports {
+		if report.SpikeHistory != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasThrottlePreview(appReport reporter.ApplicationReport) bool {
+	for _, report := range appReport.InstanceReports {
+		if report.ThrottlePreview != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasUsageStats(appReport reporter.ApplicationReport) bool {
+	for _, report := range appReport.InstanceReports {
+		if report.UsageStats != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func usageStatsColumns(report reporter.InstanceReport) []string {
+	if report.UsageStats == nil || report.UsageStats.SampleCount == 0 {
+		return []string{"-", "-", "-", "-"}
+	}
+
+	stats := report.UsageStats
+	return []string{
+		fmt.Sprintf("%.2f%%", stats.P50*100),
+		fmt.Sprintf("%.2f%%", stats.P95*100),
+		fmt.Sprintf("%.2f%%", stats.P99*100),
+		fmt.Sprintf("%.2f%%", stats.Max*100),
+	}
+}
+
+func hasUsageHistory(appReport reporter.ApplicationReport) bool {
+	for _, report := range appReport.InstanceReports {
+		if report.UsageHistory != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func usageHistorySparkline(report reporter.InstanceReport, scale float64) string {
+	if report.UsageHistory == nil {
+		return ""
+	}
+
+	return sparkline(report.UsageHistory.Samples, scale)
+}
+
+// instanceName is how an instance is referred to in the reports. Instances of
+// processes other than web are prefixed with their process type, as each
+// process numbers its instances from zero.
+func instanceName(report reporter.InstanceReport) string {
+	if report.ProcessType == "" || report.ProcessType == cf.WebProcessType {
+		return fmt.Sprintf("#%d", report.InstanceID)
+	}
+
+	return fmt.Sprintf("%s #%d", report.ProcessType, report.InstanceID)
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
 func colorizeRow(row []string, rowColor color.Attribute) []string {
 	if rowColor == noColor {
 		return row
@@ -49,11 +49,19 @@ var _ = Describe("Renderer", func() {
 
 	Describe("ShowMetrics", func() {
 		var (
-			appReport reporter.ApplicationReport
+			appReport       reporter.ApplicationReport
+			usageWindow     reporter.TimeWindow
+			spikeWindow     reporter.SpikeWindow
+			recommendations []reporter.Recommendation
 		)
+		BeforeEach(func() {
+			usageWindow = reporter.TimeWindow{}
+			spikeWindow = reporter.SpikeWindow{}
+			recommendations = nil
+		})
 		JustBeforeEach(func() {
-			appReport = reporter.ApplicationReport{ApplicationName: "myapp", Org: "theorg", Space: "thespace", Username: "theuser", InstanceReports: instanceReports}
-			Expect(renderer.ShowApplicationReport(logger, appReport)).To(Succeed())
+			appReport = reporter.ApplicationReport{ApplicationName: "myapp", Org: "theorg", Space: "thespace", Username: "theuser", UsageWindow: usageWindow, SpikeWindow: spikeWindow, InstanceReports: instanceReports, Recommendations: recommendations}
+			Expect(renderer.ShowApplicationReports(logger, []reporter.ApplicationReport{appReport})).To(Succeed())
 		})
 
 		It("shows a message with the application info", func() {
@@ -78,6 +86,70 @@ var _ = Describe("Renderer", func() {
 			}))
 		})
 
+		When("the application has several processes", func() {
+			BeforeEach(func() {
+				instanceReports[0].ProcessType = "web"
+				instanceReports[1].ProcessType = "worker"
+				instanceReports[1].InstanceID = 0
+			})
+
+			It("prefixes the instances of the other processes with their type", func() {
+				_, _, rows := display.ShowTableArgsForCall(0)
+				Expect(rows).To(Equal([][]string{
+					{"#123", "50.00%", "150.00%"},
+					{"worker #0", "75.00%", "175.00%"},
+				}))
+			})
+		})
+
+		When("the usage is shown in cores", func() {
+			BeforeEach(func() {
+				instanceReports[0].CPURate = &reporter.CPURate{Usage: 0.375, Entitlement: 0.25}
+				renderer = renderer.WithUnits(output.UnitsCores)
+			})
+
+			It("shows the usage and entitlement in cores", func() {
+				_, headers, rows := display.ShowTableArgsForCall(0)
+				Expect(headers).To(Equal([]string{"", bold("avg usage"), bold("curr usage"), bold("entitlement")}))
+				Expect(rows).To(Equal([][]string{
+					{"#123", "0.125 cores", "0.375 cores", "0.250 cores"},
+					{"#432", "-", "-", "-"},
+				}))
+			})
+		})
+
+		When("the usage is shown both as a ratio and in cores", func() {
+			BeforeEach(func() {
+				instanceReports[0].CPURate = &reporter.CPURate{Usage: 0.375, Entitlement: 0.25}
+				renderer = renderer.WithUnits(output.UnitsBoth)
+			})
+
+			It("shows the cores next to the ratios", func() {
+				_, headers, rows := display.ShowTableArgsForCall(0)
+				Expect(headers).To(Equal([]string{"", bold("avg usage"), bold("curr usage"), bold("entitlement")}))
+				Expect(rows).To(Equal([][]string{
+					{"#123", "50.00% (0.125 cores)", "150.00% (0.375 cores)", "0.250 cores"},
+					{"#432", "75.00%", "175.00%", "-"},
+				}))
+			})
+		})
+
+		When("the usage is averaged over a time window", func() {
+			BeforeEach(func() {
+				usageWindow = reporter.TimeWindow{
+					Since: time.Date(2019, 7, 30, 9, 0, 0, 0, time.UTC),
+					Until: time.Date(2019, 7, 31, 12, 0, 0, 0, time.UTC),
+				}
+			})
+
+			It("shows the time window after the application info", func() {
+				Expect(display.ShowMessageCallCount()).To(Equal(2))
+				message, values := display.ShowMessageArgsForCall(1)
+				Expect(message).To(Equal("Average usage between %s and %s:\n"))
+				Expect(values).To(Equal([]interface{}{"2019-07-30 09:00:00", "2019-07-31 12:00:00"}))
+			})
+		})
+
 		When("there are no instances of the application", func() {
 			BeforeEach(func() {
 				instanceReports = []reporter.InstanceReport{}
@@ -111,6 +183,47 @@ var _ = Describe("Renderer", func() {
 				message, _ := display.ShowMessageArgsForCall(1)
 				Expect(message).To(Equal(cyan("WARNING: Some instances are over their CPU entitlement. Consider scaling your memory or instances.")))
 			})
+
+			When("there are recommendations", func() {
+				BeforeEach(func() {
+					recommendations = []reporter.Recommendation{
+						{ProcessType: "web", Target: 0.9, UsesP95: true, Usage: 1.35, MemoryInMB: 512, RecommendedMemoryInMB: 768, InstanceCount: 2, RecommendedInstances: 4},
+						{ProcessType: "worker", Target: 0.9, Usage: 1.5, MemoryInMB: 1024, RecommendedMemoryInMB: 2048, InstanceCount: 1, RecommendedInstances: 1},
+						{ProcessType: "clock", Target: 0.9, Usage: 1.5, InstanceCount: 1, RecommendedInstances: 2},
+					}
+				})
+
+				It("replaces the generic tip with the recommendations", func() {
+					Expect(display.ShowMessageCallCount()).To(Equal(5))
+					message, _ := display.ShowMessageArgsForCall(1)
+					Expect(message).To(Equal(cyan("WARNING: Some instances are over their CPU entitlement.")))
+					message, _ = display.ShowMessageArgsForCall(2)
+					Expect(message).To(Equal(cyan("TIP: To bring the p95 usage below 90% of entitlement, scale memory from 512M to 768M, or add 2 instances if load is spread evenly.")))
+					message, _ = display.ShowMessageArgsForCall(3)
+					Expect(message).To(Equal(cyan("TIP: To bring the average usage of the worker process below 90% of entitlement, scale memory from 1G to 2G.")))
+					message, _ = display.ShowMessageArgsForCall(4)
+					Expect(message).To(Equal(cyan("TIP: To bring the average usage of the clock process below 90% of entitlement, add 1 instance if load is spread evenly.")))
+				})
+			})
+		})
+
+		When("the thresholds are changed", func() {
+			BeforeEach(func() {
+				renderer = renderer.WithThresholds(reporter.Thresholds{Over: 0.7, Near: 0.4})
+			})
+
+			It("highlights the rows using them", func() {
+				_, _, rows := display.ShowTableArgsForCall(0)
+				Expect(rows).To(Equal([][]string{
+					yellowRow("#123", "50.00%", "150.00%"),
+					redRow("#432", "75.00%", "175.00%"),
+				}))
+			})
+
+			It("prints a warning about overentitlement", func() {
+				message, _ := display.ShowMessageArgsForCall(1)
+				Expect(message).To(Equal(cyan("WARNING: Some instances are over their CPU entitlement. Consider scaling your memory or instances.")))
+			})
 		})
 
 		When("one of the instances is between 95% and 100% entitlement", func() {
@@ -209,6 +322,431 @@ var _ = Describe("Renderer", func() {
 				Expect(display.ShowMessageCallCount()).To(Equal(2))
 			})
 		})
+
+		When("the report includes usage stats", func() {
+			BeforeEach(func() {
+				instanceReports[0].UsageStats = &reporter.UsageStats{P50: 0.5, P95: 0.9, P99: 1.2, Max: 1.5, SampleCount: 30}
+				instanceReports[1].UsageStats = &reporter.UsageStats{}
+				instanceReports[1].UsageHistory = &reporter.UsageHistory{
+					Samples: []reporter.UsageSample{{Value: 1}},
+				}
+			})
+
+			It("shows the stats before the usage history", func() {
+				_, headers, rows := display.ShowTableArgsForCall(0)
+				Expect(headers).To(Equal([]string{"", bold("avg usage"), bold("curr usage"), bold("p50"), bold("p95"), bold("p99"), bold("max"), bold("history")}))
+				Expect(rows).To(Equal([][]string{
+					{"#123", "50.00%", "150.00%", "50.00%", "90.00%", "120.00%", "150.00%", ""},
+					{"#432", "75.00%", "175.00%", "-", "-", "-", "-", "█"},
+				}))
+			})
+		})
+
+		When("the report includes the usage history", func() {
+			BeforeEach(func() {
+				instanceReports[0].UsageHistory = &reporter.UsageHistory{
+					Samples: []reporter.UsageSample{
+						{Value: 0}, {Value: 0.5}, {Value: 1}, {Value: 2},
+					},
+				}
+				instanceReports[1].UsageHistory = &reporter.UsageHistory{
+					Samples: []reporter.UsageSample{
+						{Value: 0.25}, {Value: 0.25}, {Value: -1}, {Value: 1.5},
+					},
+				}
+			})
+
+			It("shows the usage history as sparklines scaled to the highest usage", func() {
+				_, headers, rows := display.ShowTableArgsForCall(0)
+				Expect(headers).To(Equal([]string{"", bold("avg usage"), bold("curr usage"), bold("history")}))
+				Expect(rows).To(Equal([][]string{
+					{"#123", "50.00%", "150.00%", "▁▃▅█"},
+					{"#432", "75.00%", "175.00%", "▂▂▁▆"},
+				}))
+			})
+
+			When("no instance used more than its entitlement", func() {
+				BeforeEach(func() {
+					instanceReports[0].UsageHistory.Samples = []reporter.UsageSample{{Value: 0.5}, {Value: 1}}
+					instanceReports[1].UsageHistory = &reporter.UsageHistory{}
+				})
+
+				It("scales the sparklines to the entitlement", func() {
+					_, _, rows := display.ShowTableArgsForCall(0)
+					Expect(rows).To(Equal([][]string{
+						{"#123", "50.00%", "150.00%", "▅█"},
+						{"#432", "75.00%", "175.00%", ""},
+					}))
+				})
+			})
+		})
+
+		When("log-cache only holds data for part of the spike window", func() {
+			BeforeEach(func() {
+				spikeWindow = reporter.SpikeWindow{
+					Since:          time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC),
+					AvailableSince: time.Date(2019, 7, 29, 8, 0, 0, 0, time.UTC),
+				}
+			})
+
+			It("says when the available data starts", func() {
+				Expect(display.ShowMessageCallCount()).To(Equal(2))
+				message, _ := display.ShowMessageArgsForCall(1)
+				Expect(message).To(Equal(cyan("NOTE: Log-cache only holds data for this application since 2019-07-29 08:00:00, so spikes before then are not shown.")))
+			})
+		})
+
+		When("log-cache holds data for the whole spike window", func() {
+			BeforeEach(func() {
+				spikeWindow = reporter.SpikeWindow{
+					Since:          time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC),
+					AvailableSince: time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC),
+				}
+			})
+
+			It("does not print a note", func() {
+				Expect(display.ShowMessageCallCount()).To(Equal(1))
+			})
+		})
+
+		When("the report includes a throttle preview", func() {
+			BeforeEach(func() {
+				instanceReports[0].ThrottlePreview = &reporter.ThrottlePreview{
+					Window: time.Hour,
+					Periods: []reporter.Period{
+						{From: time.Date(2019, 7, 29, 2, 0, 0, 0, time.UTC), To: time.Date(2019, 7, 29, 2, 30, 0, 0, time.UTC)},
+					},
+				}
+				instanceReports[1].ThrottlePreview = &reporter.ThrottlePreview{Window: time.Hour}
+			})
+
+			It("shows when the instances would have been deprioritized", func() {
+				Expect(display.ShowTableCallCount()).To(Equal(2))
+				_, headers, rows := display.ShowTableArgsForCall(1)
+				Expect(headers).To(Equal([]string{"", bold("deprioritized from"), bold("deprioritized until"), bold("duration")}))
+				Expect(rows).To(Equal([][]string{
+					{"#123", "2019-07-29 02:00:00", "2019-07-29 02:30:00", "30m0s"},
+				}))
+			})
+
+			It("prints the window and the totals", func() {
+				Expect(display.ShowMessageCallCount()).To(Equal(3))
+				message, values := display.ShowMessageArgsForCall(1)
+				Expect(message).To(Equal("\nInstances that would have been deprioritized with a rolling window of %s:\n"))
+				Expect(values).To(Equal([]interface{}{time.Hour}))
+				message, _ = display.ShowMessageArgsForCall(2)
+				Expect(message).To(Equal(yellow("Instance #123 would have been deprioritized 1 time for a total of 30m0s")))
+			})
+
+			When("no instance would have been deprioritized", func() {
+				BeforeEach(func() {
+					instanceReports[0].ThrottlePreview = &reporter.ThrottlePreview{Window: time.Hour}
+				})
+
+				It("prints a message about it", func() {
+					Expect(display.ShowTableCallCount()).To(Equal(1))
+					message, values := display.ShowMessageArgsForCall(1)
+					Expect(message).To(Equal("No instances of this application would have been deprioritized with a rolling window of %s."))
+					Expect(values).To(Equal([]interface{}{time.Hour}))
+				})
+			})
+		})
+
+		When("the report includes the spike history", func() {
+			BeforeEach(func() {
+				instanceReports[0].LastSpike = reporter.LastSpike{
+					From: time.Date(2019, 7, 30, 9, 0, 0, 0, time.UTC),
+					To:   time.Date(2019, 7, 30, 10, 0, 0, 0, time.UTC),
+				}
+				instanceReports[0].SpikeHistory = &reporter.SpikeHistory{
+					Spikes: []reporter.Spike{
+						{From: time.Date(2019, 7, 29, 2, 0, 0, 0, time.UTC), To: time.Date(2019, 7, 29, 2, 30, 0, 0, time.UTC)},
+						{From: time.Date(2019, 7, 30, 9, 0, 0, 0, time.UTC), To: time.Date(2019, 7, 30, 10, 0, 0, 0, time.UTC)},
+					},
+				}
+				instanceReports[1].SpikeHistory = &reporter.SpikeHistory{}
+			})
+
+			It("shows every spike in a second table", func() {
+				Expect(display.ShowTableCallCount()).To(Equal(2))
+				_, headers, rows := display.ShowTableArgsForCall(1)
+				Expect(headers).To(Equal([]string{"", bold("spike start"), bold("spike end"), bold("duration")}))
+				Expect(rows).To(Equal([][]string{
+					{"#123", "2019-07-29 02:00:00", "2019-07-29 02:30:00", "30m0s"},
+					{"#123", "2019-07-30 09:00:00", "2019-07-30 10:00:00", "1h0m0s"},
+				}))
+			})
+
+			It("prints the totals of the instances that spiked instead of the last spike", func() {
+				Expect(display.ShowMessageCallCount()).To(Equal(2))
+				message, _ := display.ShowMessageArgsForCall(1)
+				Expect(message).To(Equal(yellow("Instance #123 was over entitlement 2 times for a total of 1h30m0s")))
+			})
+
+			When("there were too many spikes to read them all", func() {
+				BeforeEach(func() {
+					truncatedBefore := time.Date(2019, 7, 29, 1, 0, 0, 0, time.UTC)
+					instanceReports[0].SpikeHistory.TruncatedBefore = truncatedBefore
+					instanceReports[1].SpikeHistory.TruncatedBefore = truncatedBefore
+				})
+
+				It("says which spikes are not shown", func() {
+					Expect(display.ShowMessageCallCount()).To(Equal(3))
+					message, _ := display.ShowMessageArgsForCall(2)
+					Expect(message).To(Equal(cyan("NOTE: This application has too many spikes to read them all, so spikes before 2019-07-29 01:00:00 are not shown.")))
+				})
+			})
+
+			When("no instance has spiked", func() {
+				BeforeEach(func() {
+					instanceReports[0].SpikeHistory = &reporter.SpikeHistory{}
+				})
+
+				It("prints a message about no spikes", func() {
+					Expect(display.ShowTableCallCount()).To(Equal(1))
+					Expect(display.ShowMessageCallCount()).To(Equal(2))
+					message, _ := display.ShowMessageArgsForCall(1)
+					Expect(message).To(Equal("No instances of this application have been over entitlement."))
+				})
+			})
+		})
+	})
+})
+
+var _ = Describe("Renderer updates", func() {
+	var (
+		display        *outputfakes.FakeAppDisplay
+		renderer       output.AppRenderer
+		previousReport reporter.ApplicationReport
+		appReport      reporter.ApplicationReport
+	)
+
+	BeforeEach(func() {
+		display = new(outputfakes.FakeAppDisplay)
+		renderer = output.NewAppRenderer(display)
+
+		previousReport = reporter.ApplicationReport{
+			ApplicationName: "myapp",
+			InstanceReports: []reporter.InstanceReport{
+				{InstanceID: 0, CurrentUsage: reporter.CurrentUsage{Value: 0.5}},
+				{InstanceID: 1, CurrentUsage: reporter.CurrentUsage{Value: 1.5}},
+				{InstanceID: 2, CurrentUsage: reporter.CurrentUsage{Value: 0.5}},
+			},
+		}
+		appReport = reporter.ApplicationReport{
+			ApplicationName: "myapp",
+			InstanceReports: []reporter.InstanceReport{
+				{InstanceID: 0, CurrentUsage: reporter.CurrentUsage{Value: 1.25}},
+				{InstanceID: 1, CurrentUsage: reporter.CurrentUsage{Value: 1.75}},
+				{InstanceID: 2, CurrentUsage: reporter.CurrentUsage{Value: 0.75}},
+				{InstanceID: 3, CurrentUsage: reporter.CurrentUsage{Value: 1.5}},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		Expect(renderer.ShowApplicationReportsUpdate(logger, []reporter.ApplicationReport{previousReport}, []reporter.ApplicationReport{appReport})).To(Succeed())
+	})
+
+	It("clears the display before showing the report", func() {
+		Expect(display.ClearCallCount()).To(Equal(1))
+		Expect(display.Invocations()).To(HaveKey("ShowTable"))
+	})
+
+	It("highlights the instances that went over entitlement since the previous report", func() {
+		Expect(display.ShowTableCallCount()).To(Equal(1))
+		_, _, rows := display.ShowTableArgsForCall(0)
+		Expect(rows).To(Equal([][]string{
+			magentaRow("#0", "0.00%", "125.00%"),
+			{"#1", "0.00%", "175.00%"},
+			{"#2", "0.00%", "75.00%"},
+			{"#3", "0.00%", "150.00%"},
+		}))
+	})
+
+	It("prints a warning about the instances that went over entitlement", func() {
+		message, _ := display.ShowMessageArgsForCall(1)
+		Expect(message).To(Equal(magenta("WARNING: Instances #0 went over their CPU entitlement since the last update.")))
+	})
+
+	When("no instance went over entitlement", func() {
+		BeforeEach(func() {
+			previousReport = appReport
+		})
+
+		It("does not highlight any row", func() {
+			_, _, rows := display.ShowTableArgsForCall(0)
+			Expect(rows).To(Equal([][]string{
+				{"#0", "0.00%", "125.00%"},
+				{"#1", "0.00%", "175.00%"},
+				{"#2", "0.00%", "75.00%"},
+				{"#3", "0.00%", "150.00%"},
+			}))
+			Expect(display.ShowMessageCallCount()).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("Renderer for multiple apps", func() {
+	var (
+		display    *outputfakes.FakeAppDisplay
+		renderer   output.AppRenderer
+		appReports []reporter.ApplicationReport
+	)
+
+	BeforeEach(func() {
+		display = new(outputfakes.FakeAppDisplay)
+		renderer = output.NewAppRenderer(display)
+
+		appReports = []reporter.ApplicationReport{
+			{
+				ApplicationName: "app-1",
+				InstanceReports: []reporter.InstanceReport{
+					{InstanceID: 0, CurrentUsage: reporter.CurrentUsage{Value: 1.5}},
+				},
+			},
+			{
+				ApplicationName: "app-2",
+				InstanceReports: []reporter.InstanceReport{
+					{InstanceID: 0, CurrentUsage: reporter.CurrentUsage{Value: 1.5}},
+				},
+			},
+		}
+	})
+
+	Describe("ShowApplicationReports", func() {
+		JustBeforeEach(func() {
+			Expect(renderer.ShowApplicationReports(logger, appReports)).To(Succeed())
+		})
+
+		It("shows a section for each app, separated by an empty line", func() {
+			Expect(display.ShowTableCallCount()).To(Equal(2))
+			Expect(display.ShowMessageCallCount()).To(Equal(3))
+
+			_, values := display.ShowMessageArgsForCall(0)
+			Expect(values[0]).To(Equal(terminal.EntityNameColor("app-1")))
+			message, _ := display.ShowMessageArgsForCall(1)
+			Expect(message).To(BeEmpty())
+			_, values = display.ShowMessageArgsForCall(2)
+			Expect(values[0]).To(Equal(terminal.EntityNameColor("app-2")))
+		})
+	})
+
+	Describe("ShowApplicationReportsUpdate", func() {
+		var previousReports []reporter.ApplicationReport
+
+		BeforeEach(func() {
+			previousReports = []reporter.ApplicationReport{
+				{
+					ApplicationName: "app-2",
+					InstanceReports: []reporter.InstanceReport{
+						{InstanceID: 0, CurrentUsage: reporter.CurrentUsage{Value: 0.5}},
+					},
+				},
+				{
+					ApplicationName: "app-1",
+					InstanceReports: []reporter.InstanceReport{
+						{InstanceID: 0, CurrentUsage: reporter.CurrentUsage{Value: 1.5}},
+					},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			Expect(renderer.ShowApplicationReportsUpdate(logger, previousReports, appReports)).To(Succeed())
+		})
+
+		It("clears the display once", func() {
+			Expect(display.ClearCallCount()).To(Equal(1))
+		})
+
+		It("compares each app with its own previous report", func() {
+			_, _, rows := display.ShowTableArgsForCall(0)
+			Expect(rows).To(Equal([][]string{{"#0", "0.00%", "150.00%"}}))
+			_, _, rows = display.ShowTableArgsForCall(1)
+			Expect(rows).To(Equal([][]string{magentaRow("#0", "0.00%", "150.00%")}))
+		})
+	})
+})
+
+var _ = Describe("Space Renderer", func() {
+	var (
+		display     *outputfakes.FakeAppDisplay
+		renderer    output.AppRenderer
+		spaceReport reporter.SpaceUsageReport
+	)
+
+	BeforeEach(func() {
+		display = new(outputfakes.FakeAppDisplay)
+		renderer = output.NewAppRenderer(display)
+
+		spaceReport = reporter.SpaceUsageReport{
+			Org:      "theorg",
+			Space:    "thespace",
+			Username: "theuser",
+			Apps: []reporter.AppUsageReport{
+				{
+					ApplicationName: "busy",
+					InstanceCount:   2,
+					AverageUsage:    1,
+					CurrentUsage:    1.1,
+					WorstInstance:   reporter.InstanceReport{InstanceID: 1, CumulativeUsage: reporter.CumulativeUsage{Value: 1.25}},
+				},
+				{
+					ApplicationName: "warm",
+					InstanceCount:   1,
+					AverageUsage:    0.97,
+					CurrentUsage:    0.9,
+					WorstInstance:   reporter.InstanceReport{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 0.97}},
+				},
+				{
+					ApplicationName: "idle",
+					InstanceCount:   1,
+					AverageUsage:    0.2,
+					CurrentUsage:    0.1,
+					WorstInstance:   reporter.InstanceReport{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 0.2}},
+				},
+				{ApplicationName: "stopped"},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		Expect(renderer.ShowSpaceReport(logger, spaceReport)).To(Succeed())
+	})
+
+	It("shows a message with the space info", func() {
+		message, values := display.ShowMessageArgsForCall(0)
+		Expect(message).To(Equal("Showing CPU usage against entitlement for apps in org %s / space %s as %s ...\n"))
+		Expect(values).To(Equal([]interface{}{
+			terminal.EntityNameColor("theorg"),
+			terminal.EntityNameColor("thespace"),
+			terminal.EntityNameColor("theuser"),
+		}))
+	})
+
+	It("shows a row per app, highlighting the apps with instances over or near entitlement", func() {
+		Expect(display.ShowTableCallCount()).To(Equal(1))
+		_, headers, rows := display.ShowTableArgsForCall(0)
+		Expect(headers).To(Equal([]string{bold("app"), bold("instances"), bold("avg usage"), bold("curr usage"), bold("worst instance")}))
+		Expect(rows).To(Equal([][]string{
+			redRow("busy", "2", "100.00%", "110.00%", "#1 (125.00%)"),
+			yellowRow("warm", "1", "97.00%", "90.00%", "#0 (97.00%)"),
+			{"idle", "1", "20.00%", "10.00%", "#0 (20.00%)"},
+			{"stopped", "0", "-", "-", "-"},
+		}))
+	})
+
+	When("there are no apps in the space", func() {
+		BeforeEach(func() {
+			spaceReport.Apps = nil
+		})
+
+		It("prints a message about no apps", func() {
+			Expect(display.ShowTableCallCount()).To(BeZero())
+			message, _ := display.ShowMessageArgsForCall(1)
+			Expect(message).To(Equal("There are no apps in this space."))
+		})
 	})
 })
 
@@ -240,3 +778,11 @@ func yellowRow(r ...string) []string {
 func redRow(r ...string) []string {
 	return colorizeRow(r, color.FgRed)
 }
+
+func magenta(s string) string {
+	return terminal.Colorize(s, color.FgMagenta)
+}
+
+func magentaRow(r ...string) []string {
+	return colorizeRow(r, color.FgMagenta)
+}
@@ -17,6 +17,10 @@ func (d TerminalDisplay) ShowMessage(message string, values ...interface{}) {
 	d.ui.Say(message, values...)
 }
 
+func (d TerminalDisplay) Clear() {
+	d.ui.Say("\033[H\033[2J")
+}
+
 func (d TerminalDisplay) ShowTable(logger lager.Logger, headers []string, rows [][]string) error {
 	logger = logger.Session("terminal-display-show-table")
 
@@ -0,0 +1,133 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+	"code.cloudfoundry.org/lager"
+)
+
+var oeiCSVHeader = []string{"org", "space", "app", "app_guid", "instance_count", "instance_id", "cumulative_usage", "over_entitlement", "last_spike_from", "last_spike_to", "tier", "deprioritized", "deprioritized_count", "deprioritized_seconds"}
+
+// OverEntitlementInstancesCSVRenderer writes one row per instance of every
+// app over or near entitlement, or that would have been deprioritized, so
+// that the result can be loaded into a spreadsheet as is. The tier of apps
+// that are only listed for being deprioritized is within, and the
+// deprioritized columns are only set when the report includes a throttle
+// preview.
+type OverEntitlementInstancesCSVRenderer struct {
+	writer io.Writer
+}
+
+func NewOverEntitlementInstancesCSVRenderer(writer io.Writer) *OverEntitlementInstancesCSVRenderer {
+	return &OverEntitlementInstancesCSVRenderer{writer: writer}
+}
+
+func (r *OverEntitlementInstancesCSVRenderer) Render(logger lager.Logger, report reporter.OEIReport) error {
+	return r.render(logger.Session("oei-csv-renderer"), []reporter.OEIReport{report})
+}
+
+// RenderAllOrgs writes the header once followed by the rows of every org.
+func (r *OverEntitlementInstancesCSVRenderer) RenderAllOrgs(logger lager.Logger, report reporter.AllOrgsOEIReport) error {
+	return r.render(logger.Session("oei-csv-renderer-all-orgs"), report.OrgReports)
+}
+
+func (r *OverEntitlementInstancesCSVRenderer) render(logger lager.Logger, reports []reporter.OEIReport) error {
+	csvWriter := csv.NewWriter(r.writer)
+	if err := csvWriter.Write(oeiCSVHeader); err != nil {
+		logger.Error("csv-write-failed", err)
+		return err
+	}
+
+	for _, report := range reports {
+		for _, spaceReport := range report.SpaceReports {
+			if err := writeOEICSVRows(csvWriter, report, spaceReport, spaceReport.Apps, "over"); err != nil {
+				logger.Error("csv-write-failed", err)
+				return err
+			}
+			if err := writeOEICSVRows(csvWriter, report, spaceReport, spaceReport.NearApps, "near"); err != nil {
+				logger.Error("csv-write-failed", err)
+				return err
+			}
+			if err := writeOEICSVRows(csvWriter, report, spaceReport, withinEntitlementApps(spaceReport), "within"); err != nil {
+				logger.Error("csv-write-failed", err)
+				return err
+			}
+		}
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		logger.Error("csv-flush-failed", err)
+		return err
+	}
+
+	return nil
+}
+
+func writeOEICSVRows(csvWriter *csv.Writer, report reporter.OEIReport, spaceReport reporter.SpaceReport, apps []reporter.OEIAppReport, tier string) error {
+	for _, app := range apps {
+		for _, instanceReport := range app.InstanceReports {
+			row := []string{
+				report.Org,
+				spaceReport.SpaceName,
+				app.Name,
+				app.Guid,
+				strconv.Itoa(app.InstanceCount),
+				strconv.Itoa(instanceReport.InstanceID),
+				strconv.FormatFloat(instanceReport.CumulativeUsage.Value, 'f', -1, 64),
+				strconv.FormatBool(report.Thresholds.IsOver(instanceReport.CumulativeUsage.Value)),
+			}
+			row = append(row, formatCSVSpike(instanceReport.LastSpike)...)
+			row = append(row, tier)
+			row = append(row, formatCSVThrottlePreview(instanceReport.ThrottlePreview)...)
+			if err := csvWriter.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// withinEntitlementApps returns the apps that would have been deprioritized
+// but are neither over nor near entitlement, as the rows of the others are
+// already written in their tier.
+func withinEntitlementApps(spaceReport reporter.SpaceReport) []reporter.OEIAppReport {
+	listed := map[string]bool{}
+	for _, app := range spaceReport.Apps {
+		listed[app.Guid] = true
+	}
+	for _, app := range spaceReport.NearApps {
+		listed[app.Guid] = true
+	}
+
+	var apps []reporter.OEIAppReport
+	for _, app := range spaceReport.ThrottledApps {
+		if !listed[app.Guid] {
+			apps = append(apps, app)
+		}
+	}
+	return apps
+}
+
+func formatCSVThrottlePreview(throttlePreview *reporter.ThrottlePreview) []string {
+	if throttlePreview == nil {
+		return []string{"", "", ""}
+	}
+	return []string{
+		strconv.FormatBool(throttlePreview.IsDeprioritized()),
+		strconv.Itoa(len(throttlePreview.Periods)),
+		strconv.FormatFloat(throttlePreview.TotalDuration().Seconds(), 'f', -1, 64),
+	}
+}
+
+func formatCSVSpike(spike reporter.LastSpike) []string {
+	if (spike == reporter.LastSpike{}) {
+		return []string{"", ""}
+	}
+	return []string{spike.From.Format(time.RFC3339), spike.To.Format(time.RFC3339)}
+}
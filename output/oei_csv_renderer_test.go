@@ -0,0 +1,223 @@
+package output_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/output"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("OEI CSV Renderer", func() {
+	var (
+		buffer    *gbytes.Buffer
+		report    reporter.OEIReport
+		renderErr error
+		renderer  *output.OverEntitlementInstancesCSVRenderer
+	)
+
+	BeforeEach(func() {
+		buffer = gbytes.NewBuffer()
+		report = reporter.OEIReport{
+			Org:        "org",
+			Username:   "user",
+			Thresholds: reporter.DefaultThresholds,
+			SpaceReports: []reporter.SpaceReport{
+				{
+					SpaceName: "space-1",
+					Apps: []reporter.OEIAppReport{
+						{
+							Name:          "app-1",
+							Guid:          "app-1-guid",
+							InstanceCount: 2,
+							InstanceReports: []reporter.InstanceReport{
+								{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 1.5}, LastSpike: reporter.LastSpike{
+									From: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC),
+									To:   time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC),
+								}},
+								{InstanceID: 1, CumulativeUsage: reporter.CumulativeUsage{Value: 0.5}},
+							},
+						},
+					},
+				},
+				{
+					SpaceName: "space-2",
+					Apps: []reporter.OEIAppReport{
+						{
+							Name:          "app, with a comma",
+							Guid:          "app-2-guid",
+							InstanceCount: 1,
+							InstanceReports: []reporter.InstanceReport{
+								{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 1.25}},
+							},
+						},
+					},
+				},
+			},
+		}
+		renderer = output.NewOverEntitlementInstancesCSVRenderer(buffer)
+	})
+
+	JustBeforeEach(func() {
+		renderErr = renderer.Render(logger, report)
+	})
+
+	It("writes a row per instance", func() {
+		Expect(renderErr).NotTo(HaveOccurred())
+		Expect(string(buffer.Contents())).To(Equal(
+			"org,space,app,app_guid,instance_count,instance_id,cumulative_usage,over_entitlement,last_spike_from,last_spike_to,tier,deprioritized,deprioritized_count,deprioritized_seconds\n" +
+				"org,space-1,app-1,app-1-guid,2,0,1.5,true,2020-01-01T10:00:00Z,2020-01-01T11:00:00Z,over,,,\n" +
+				"org,space-1,app-1,app-1-guid,2,1,0.5,false,,,over,,,\n" +
+				"org,space-2,\"app, with a comma\",app-2-guid,1,0,1.25,true,,,over,,,\n",
+		))
+	})
+
+	When("there are applications near entitlement", func() {
+		BeforeEach(func() {
+			report.SpaceReports[1].NearApps = []reporter.OEIAppReport{
+				{
+					Name:          "app-3",
+					Guid:          "app-3-guid",
+					InstanceCount: 1,
+					InstanceReports: []reporter.InstanceReport{
+						{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 0.97}},
+					},
+				},
+			}
+		})
+
+		It("writes their rows in the near tier", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(string(buffer.Contents())).To(HaveSuffix(
+				"org,space-2,\"app, with a comma\",app-2-guid,1,0,1.25,true,,,over,,,\n" +
+					"org,space-2,app-3,app-3-guid,1,0,0.97,false,,,near,,,\n",
+			))
+		})
+	})
+
+	When("there are applications that would have been deprioritized", func() {
+		BeforeEach(func() {
+			report.SpaceReports[1].ThrottledApps = []reporter.OEIAppReport{
+				{
+					Name:          "app-4",
+					Guid:          "app-4-guid",
+					InstanceCount: 1,
+					InstanceReports: []reporter.InstanceReport{
+						{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 0.8}, ThrottlePreview: &reporter.ThrottlePreview{
+							Window: time.Hour,
+							Periods: []reporter.Period{
+								{From: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC), To: time.Date(2020, 1, 1, 10, 15, 0, 0, time.UTC)},
+								{From: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC), To: time.Date(2020, 1, 1, 12, 5, 0, 0, time.UTC)},
+							},
+						}},
+					},
+				},
+			}
+		})
+
+		It("writes their rows in the within tier with the deprioritized periods", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(string(buffer.Contents())).To(HaveSuffix(
+				"org,space-2,app-4,app-4-guid,1,0,0.8,false,,,within,true,2,1200\n",
+			))
+		})
+
+		When("they are also over entitlement", func() {
+			BeforeEach(func() {
+				throttlePreview := &reporter.ThrottlePreview{
+					Window: time.Hour,
+					Periods: []reporter.Period{
+						{From: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC), To: time.Date(2020, 1, 1, 10, 30, 0, 0, time.UTC)},
+					},
+				}
+				report.SpaceReports[1].Apps[0].InstanceReports[0].ThrottlePreview = throttlePreview
+				report.SpaceReports[1].ThrottledApps = []reporter.OEIAppReport{report.SpaceReports[1].Apps[0]}
+			})
+
+			It("writes their rows once, in their tier", func() {
+				Expect(renderErr).NotTo(HaveOccurred())
+				Expect(string(buffer.Contents())).To(HaveSuffix(
+					"org,space-1,app-1,app-1-guid,2,1,0.5,false,,,over,,,\n" +
+						"org,space-2,\"app, with a comma\",app-2-guid,1,0,1.25,true,,,over,true,1,1800\n",
+				))
+			})
+		})
+	})
+
+	When("there are no applications over entitlement", func() {
+		BeforeEach(func() {
+			report = reporter.OEIReport{Org: "org", Username: "user"}
+		})
+
+		It("writes the header only", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(string(buffer.Contents())).To(Equal("org,space,app,app_guid,instance_count,instance_id,cumulative_usage,over_entitlement,last_spike_from,last_spike_to,tier,deprioritized,deprioritized_count,deprioritized_seconds\n"))
+		})
+	})
+
+	When("writing the report fails", func() {
+		BeforeEach(func() {
+			renderer = output.NewOverEntitlementInstancesCSVRenderer(failingWriter{})
+		})
+
+		It("returns the error", func() {
+			Expect(renderErr).To(MatchError("write-failed"))
+		})
+	})
+})
+
+var _ = Describe("OEI CSV Renderer for all orgs", func() {
+	var (
+		buffer    *gbytes.Buffer
+		report    reporter.AllOrgsOEIReport
+		renderErr error
+		renderer  *output.OverEntitlementInstancesCSVRenderer
+	)
+
+	BeforeEach(func() {
+		buffer = gbytes.NewBuffer()
+		report = reporter.AllOrgsOEIReport{
+			Username: "user",
+			OrgReports: []reporter.OEIReport{
+				{Org: "org-1", Username: "user", Thresholds: reporter.DefaultThresholds, SpaceReports: []reporter.SpaceReport{
+					{SpaceName: "space-1", Apps: []reporter.OEIAppReport{{
+						Name: "app-1", Guid: "app-1-guid", InstanceCount: 1,
+						InstanceReports: []reporter.InstanceReport{{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 1.5}}},
+					}}},
+				}},
+				{Org: "org-2", Username: "user", Thresholds: reporter.DefaultThresholds, SpaceReports: []reporter.SpaceReport{
+					{SpaceName: "space-1", Apps: []reporter.OEIAppReport{{
+						Name: "app-1", Guid: "app-2-guid", InstanceCount: 1,
+						InstanceReports: []reporter.InstanceReport{{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 2.5}}},
+					}}},
+				}},
+			},
+		}
+		renderer = output.NewOverEntitlementInstancesCSVRenderer(buffer)
+	})
+
+	JustBeforeEach(func() {
+		renderErr = renderer.RenderAllOrgs(logger, report)
+	})
+
+	It("writes the header once followed by the rows of every org", func() {
+		Expect(renderErr).NotTo(HaveOccurred())
+		Expect(string(buffer.Contents())).To(Equal(
+			"org,space,app,app_guid,instance_count,instance_id,cumulative_usage,over_entitlement,last_spike_from,last_spike_to,tier,deprioritized,deprioritized_count,deprioritized_seconds\n" +
+				"org-1,space-1,app-1,app-1-guid,1,0,1.5,true,,,over,,,\n" +
+				"org-2,space-1,app-1,app-2-guid,1,0,2.5,true,,,over,,,\n",
+		))
+	})
+
+	When("writing the report fails", func() {
+		BeforeEach(func() {
+			renderer = output.NewOverEntitlementInstancesCSVRenderer(failingWriter{})
+		})
+
+		It("returns the error", func() {
+			Expect(renderErr).To(MatchError("write-failed"))
+		})
+	})
+})
@@ -0,0 +1,166 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+	"code.cloudfoundry.org/lager"
+)
+
+// OverEntitlementInstancesJSONSchemaVersion is the version of the document
+// written by OverEntitlementInstancesJSONRenderer. It follows the same rules as
+// AppJSONSchemaVersion.
+const OverEntitlementInstancesJSONSchemaVersion = 1
+
+type OverEntitlementInstancesJSONRenderer struct {
+	writer io.Writer
+}
+
+type oeiReportJSON struct {
+	SchemaVersion int               `json:"schema_version"`
+	Org           string            `json:"org"`
+	Username      string            `json:"username"`
+	Thresholds    oeiThresholdsJSON `json:"thresholds"`
+	Spaces        []oeiSpaceJSON    `json:"spaces"`
+}
+
+type allOrgsOEIReportJSON struct {
+	SchemaVersion int               `json:"schema_version"`
+	Username      string            `json:"username"`
+	Thresholds    oeiThresholdsJSON `json:"thresholds"`
+	Orgs          []oeiOrgJSON      `json:"orgs"`
+}
+
+type oeiThresholdsJSON struct {
+	Over float64 `json:"over"`
+	Near float64 `json:"near"`
+}
+
+type oeiOrgJSON struct {
+	Org    string         `json:"org"`
+	Spaces []oeiSpaceJSON `json:"spaces"`
+}
+
+type oeiSpaceJSON struct {
+	Space         string       `json:"space"`
+	Apps          []oeiAppJSON `json:"apps"`
+	NearApps      []oeiAppJSON `json:"near_apps"`
+	ThrottledApps []oeiAppJSON `json:"throttled_apps,omitempty"`
+}
+
+type oeiAppJSON struct {
+	Name          string            `json:"name"`
+	Guid          string            `json:"guid"`
+	InstanceCount int               `json:"instance_count"`
+	Instances     []oeiInstanceJSON `json:"instances"`
+}
+
+type oeiInstanceJSON struct {
+	InstanceID      int                  `json:"instance_id"`
+	CumulativeUsage float64              `json:"cumulative_usage"`
+	OverEntitlement bool                 `json:"over_entitlement"`
+	LastSpike       *spikeJSON           `json:"last_spike,omitempty"`
+	ThrottlePreview *throttlePreviewJSON `json:"throttle_preview,omitempty"`
+}
+
+func NewOverEntitlementInstancesJSONRenderer(writer io.Writer) *OverEntitlementInstancesJSONRenderer {
+	return &OverEntitlementInstancesJSONRenderer{writer: writer}
+}
+
+func (r *OverEntitlementInstancesJSONRenderer) Render(logger lager.Logger, report reporter.OEIReport) error {
+	logger = logger.Session("oei-json-renderer")
+
+	err := json.NewEncoder(r.writer).Encode(toOEIReportJSON(report))
+	if err != nil {
+		logger.Error("json-encode-failed", err)
+		return err
+	}
+
+	return nil
+}
+
+// RenderAllOrgs writes a single document with the apps over entitlement in
+// every org.
+func (r *OverEntitlementInstancesJSONRenderer) RenderAllOrgs(logger lager.Logger, report reporter.AllOrgsOEIReport) error {
+	logger = logger.Session("oei-json-renderer-all-orgs")
+
+	orgs := make([]oeiOrgJSON, 0, len(report.OrgReports))
+	for _, orgReport := range report.OrgReports {
+		orgs = append(orgs, oeiOrgJSON{Org: orgReport.Org, Spaces: toOEISpacesJSON(orgReport.SpaceReports, report.Thresholds)})
+	}
+
+	err := json.NewEncoder(r.writer).Encode(allOrgsOEIReportJSON{
+		SchemaVersion: OverEntitlementInstancesJSONSchemaVersion,
+		Username:      report.Username,
+		Thresholds:    toOEIThresholdsJSON(report.Thresholds),
+		Orgs:          orgs,
+	})
+	if err != nil {
+		logger.Error("json-encode-failed", err)
+		return err
+	}
+
+	return nil
+}
+
+func toOEIReportJSON(report reporter.OEIReport) oeiReportJSON {
+	return oeiReportJSON{
+		SchemaVersion: OverEntitlementInstancesJSONSchemaVersion,
+		Org:           report.Org,
+		Username:      report.Username,
+		Thresholds:    toOEIThresholdsJSON(report.Thresholds),
+		Spaces:        toOEISpacesJSON(report.SpaceReports, report.Thresholds),
+	}
+}
+
+func toOEIThresholdsJSON(thresholds reporter.Thresholds) oeiThresholdsJSON {
+	return oeiThresholdsJSON{Over: thresholds.Over, Near: thresholds.Near}
+}
+
+func toOEISpacesJSON(spaceReports []reporter.SpaceReport, thresholds reporter.Thresholds) []oeiSpaceJSON {
+	spaces := make([]oeiSpaceJSON, 0, len(spaceReports))
+	for _, spaceReport := range spaceReports {
+		space := oeiSpaceJSON{
+			Space:    spaceReport.SpaceName,
+			Apps:     toOEIAppsJSON(spaceReport.Apps, thresholds),
+			NearApps: toOEIAppsJSON(spaceReport.NearApps, thresholds),
+		}
+		if spaceReport.ThrottledApps != nil {
+			space.ThrottledApps = toOEIAppsJSON(spaceReport.ThrottledApps, thresholds)
+		}
+		spaces = append(spaces, space)
+	}
+
+	return spaces
+}
+
+func toOEIAppsJSON(appReports []reporter.OEIAppReport, thresholds reporter.Thresholds) []oeiAppJSON {
+	apps := make([]oeiAppJSON, 0, len(appReports))
+	for _, app := range appReports {
+		instances := make([]oeiInstanceJSON, 0, len(app.InstanceReports))
+		for _, instanceReport := range app.InstanceReports {
+			instance := oeiInstanceJSON{
+				InstanceID:      instanceReport.InstanceID,
+				CumulativeUsage: instanceReport.CumulativeUsage.Value,
+				OverEntitlement: thresholds.IsOver(instanceReport.CumulativeUsage.Value),
+			}
+			if (instanceReport.LastSpike != reporter.LastSpike{}) {
+				instance.LastSpike = &spikeJSON{From: instanceReport.LastSpike.From, To: instanceReport.LastSpike.To}
+			}
+			if instanceReport.ThrottlePreview != nil {
+				instance.ThrottlePreview = toThrottlePreviewJSON(*instanceReport.ThrottlePreview)
+			}
+			instances = append(instances, instance)
+		}
+
+		apps = append(apps, oeiAppJSON{
+			Name:          app.Name,
+			Guid:          app.Guid,
+			InstanceCount: app.InstanceCount,
+			Instances:     instances,
+		})
+	}
+
+	return apps
+}
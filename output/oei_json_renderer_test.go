@@ -0,0 +1,280 @@
+package output_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/output"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("OEI JSON Renderer", func() {
+	var (
+		buffer    *gbytes.Buffer
+		report    reporter.OEIReport
+		renderErr error
+		renderer  *output.OverEntitlementInstancesJSONRenderer
+	)
+
+	BeforeEach(func() {
+		buffer = gbytes.NewBuffer()
+		report = reporter.OEIReport{
+			Org:        "org",
+			Username:   "user",
+			Thresholds: reporter.DefaultThresholds,
+			SpaceReports: []reporter.SpaceReport{
+				{
+					SpaceName: "space-1",
+					Apps: []reporter.OEIAppReport{
+						{
+							Name:          "app-1",
+							Guid:          "app-1-guid",
+							InstanceCount: 2,
+							InstanceReports: []reporter.InstanceReport{
+								{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 1.5}, LastSpike: reporter.LastSpike{
+									From: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC),
+									To:   time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC),
+								}},
+								{InstanceID: 1, CumulativeUsage: reporter.CumulativeUsage{Value: 0.5}},
+							},
+						},
+					},
+				},
+			},
+		}
+		renderer = output.NewOverEntitlementInstancesJSONRenderer(buffer)
+	})
+
+	JustBeforeEach(func() {
+		renderErr = renderer.Render(logger, report)
+	})
+
+	It("writes the report as JSON", func() {
+		Expect(renderErr).NotTo(HaveOccurred())
+		Expect(buffer.Contents()).To(MatchJSON(`{
+			"schema_version": 1,
+			"org": "org",
+			"username": "user",
+			"thresholds": {"over": 1, "near": 0.95},
+			"spaces": [
+				{
+					"space": "space-1",
+					"apps": [
+						{
+							"name": "app-1",
+							"guid": "app-1-guid",
+							"instance_count": 2,
+							"instances": [
+								{
+									"instance_id": 0,
+									"cumulative_usage": 1.5,
+									"over_entitlement": true,
+									"last_spike": {"from": "2020-01-01T10:00:00Z", "to": "2020-01-01T11:00:00Z"}
+								},
+								{"instance_id": 1, "cumulative_usage": 0.5, "over_entitlement": false}
+							]
+						}
+					],
+					"near_apps": []
+				}
+			]
+		}`))
+	})
+
+	When("there are applications near entitlement", func() {
+		BeforeEach(func() {
+			report.SpaceReports[0].NearApps = []reporter.OEIAppReport{
+				{
+					Name:          "app-2",
+					Guid:          "app-2-guid",
+					InstanceCount: 1,
+					InstanceReports: []reporter.InstanceReport{
+						{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 0.97}},
+					},
+				},
+			}
+		})
+
+		It("lists them separately", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+
+			var document struct {
+				Spaces []struct {
+					NearApps []json.RawMessage `json:"near_apps"`
+				} `json:"spaces"`
+			}
+			Expect(json.Unmarshal(buffer.Contents(), &document)).To(Succeed())
+			Expect(document.Spaces[0].NearApps).To(HaveLen(1))
+			Expect(document.Spaces[0].NearApps[0]).To(MatchJSON(`{
+				"name": "app-2",
+				"guid": "app-2-guid",
+				"instance_count": 1,
+				"instances": [{"instance_id": 0, "cumulative_usage": 0.97, "over_entitlement": false}]
+			}`))
+		})
+	})
+
+	When("there are applications that would have been deprioritized", func() {
+		BeforeEach(func() {
+			report.SpaceReports[0].ThrottledApps = []reporter.OEIAppReport{
+				{
+					Name:          "app-3",
+					Guid:          "app-3-guid",
+					InstanceCount: 1,
+					InstanceReports: []reporter.InstanceReport{
+						{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 0.8}, ThrottlePreview: &reporter.ThrottlePreview{
+							Window: time.Hour,
+							Periods: []reporter.Period{
+								{From: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC), To: time.Date(2020, 1, 1, 10, 15, 0, 0, time.UTC)},
+							},
+						}},
+					},
+				},
+			}
+		})
+
+		It("lists them separately with their throttle preview", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+
+			var document struct {
+				Spaces []struct {
+					ThrottledApps []json.RawMessage `json:"throttled_apps"`
+				} `json:"spaces"`
+			}
+			Expect(json.Unmarshal(buffer.Contents(), &document)).To(Succeed())
+			Expect(document.Spaces[0].ThrottledApps).To(HaveLen(1))
+			Expect(document.Spaces[0].ThrottledApps[0]).To(MatchJSON(`{
+				"name": "app-3",
+				"guid": "app-3-guid",
+				"instance_count": 1,
+				"instances": [{
+					"instance_id": 0,
+					"cumulative_usage": 0.8,
+					"over_entitlement": false,
+					"throttle_preview": {
+						"window_seconds": 3600,
+						"periods": [{"from": "2020-01-01T10:00:00Z", "to": "2020-01-01T10:15:00Z", "duration_seconds": 900}],
+						"count": 1,
+						"total_duration_seconds": 900
+					}
+				}]
+			}`))
+		})
+	})
+
+	When("there are no applications over entitlement", func() {
+		BeforeEach(func() {
+			report = reporter.OEIReport{Org: "org", Username: "user", Thresholds: reporter.DefaultThresholds}
+		})
+
+		It("writes an empty list of spaces", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(buffer.Contents()).To(MatchJSON(`{"schema_version": 1, "org": "org", "username": "user", "thresholds": {"over": 1, "near": 0.95}, "spaces": []}`))
+		})
+	})
+
+	When("writing the report fails", func() {
+		BeforeEach(func() {
+			renderer = output.NewOverEntitlementInstancesJSONRenderer(failingWriter{})
+		})
+
+		It("returns the error", func() {
+			Expect(renderErr).To(MatchError("write-failed"))
+		})
+	})
+})
+
+var _ = Describe("OEI JSON Renderer for all orgs", func() {
+	var (
+		buffer    *gbytes.Buffer
+		report    reporter.AllOrgsOEIReport
+		renderErr error
+		renderer  *output.OverEntitlementInstancesJSONRenderer
+	)
+
+	BeforeEach(func() {
+		buffer = gbytes.NewBuffer()
+		report = reporter.AllOrgsOEIReport{
+			Username:   "user",
+			Thresholds: reporter.DefaultThresholds,
+			OrgReports: []reporter.OEIReport{
+				{
+					Org:      "org-1",
+					Username: "user",
+					SpaceReports: []reporter.SpaceReport{
+						{
+							SpaceName: "space-1",
+							Apps: []reporter.OEIAppReport{
+								{
+									Name:          "app-1",
+									Guid:          "app-1-guid",
+									InstanceCount: 1,
+									InstanceReports: []reporter.InstanceReport{
+										{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 1.5}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		renderer = output.NewOverEntitlementInstancesJSONRenderer(buffer)
+	})
+
+	JustBeforeEach(func() {
+		renderErr = renderer.RenderAllOrgs(logger, report)
+	})
+
+	It("writes a single document grouped by org", func() {
+		Expect(renderErr).NotTo(HaveOccurred())
+		Expect(buffer.Contents()).To(MatchJSON(`{
+			"schema_version": 1,
+			"username": "user",
+			"thresholds": {"over": 1, "near": 0.95},
+			"orgs": [
+				{
+					"org": "org-1",
+					"spaces": [
+						{
+							"space": "space-1",
+							"apps": [
+								{
+									"name": "app-1",
+									"guid": "app-1-guid",
+									"instance_count": 1,
+									"instances": [{"instance_id": 0, "cumulative_usage": 1.5, "over_entitlement": true}]
+								}
+							],
+							"near_apps": []
+						}
+					]
+				}
+			]
+		}`))
+	})
+
+	When("there are no applications over entitlement", func() {
+		BeforeEach(func() {
+			report = reporter.AllOrgsOEIReport{Username: "user", Thresholds: reporter.DefaultThresholds}
+		})
+
+		It("writes an empty list of orgs", func() {
+			Expect(renderErr).NotTo(HaveOccurred())
+			Expect(buffer.Contents()).To(MatchJSON(`{"schema_version": 1, "username": "user", "thresholds": {"over": 1, "near": 0.95}, "orgs": []}`))
+		})
+	})
+
+	When("writing the report fails", func() {
+		BeforeEach(func() {
+			renderer = output.NewOverEntitlementInstancesJSONRenderer(failingWriter{})
+		})
+
+		It("returns the error", func() {
+			Expect(renderErr).To(MatchError("write-failed"))
+		})
+	})
+})
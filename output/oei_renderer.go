@@ -1,6 +1,10 @@
 package output
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"code.cloudfoundry.org/cli/cf/terminal"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
 	"code.cloudfoundry.org/lager"
@@ -27,7 +31,26 @@ func (r *OverEntitlementInstancesRenderer) Render(logger lager.Logger, report re
 	}
 
 	r.showReportHeader(report)
-	return r.display.ShowTable(logger, []string{"space", "app"}, buildOEITableRows(report))
+	return r.showTiers(logger, []string{"space", "app"}, buildOEITableRows(report, false), buildOEITableRows(report, true), buildThrottledTableRows(report))
+}
+
+// RenderAllOrgs shows the apps over entitlement in every org as a single
+// table, followed by the apps near entitlement.
+func (r *OverEntitlementInstancesRenderer) RenderAllOrgs(logger lager.Logger, report reporter.AllOrgsOEIReport) error {
+	if len(report.OrgReports) == 0 {
+		r.display.ShowMessage("No apps over entitlement in any org.\n")
+		return nil
+	}
+
+	r.display.ShowMessage("Showing over-entitlement apps in all orgs as %s...\n", terminal.EntityNameColor(report.Username))
+
+	var overRows, nearRows, throttledRows [][]string
+	for _, orgReport := range report.OrgReports {
+		overRows = append(overRows, prependColumn(orgReport.Org, buildOEITableRows(orgReport, false))...)
+		nearRows = append(nearRows, prependColumn(orgReport.Org, buildOEITableRows(orgReport, true))...)
+		throttledRows = append(throttledRows, prependColumn(orgReport.Org, buildThrottledTableRows(orgReport))...)
+	}
+	return r.showTiers(logger, []string{"org", "space", "app"}, overRows, nearRows, throttledRows)
 }
 
 func (r OverEntitlementInstancesRenderer) showReportHeader(report reporter.OEIReport) {
@@ -37,12 +60,89 @@ func (r OverEntitlementInstancesRenderer) showReportHeader(report reporter.OEIRe
 	)
 }
 
-func buildOEITableRows(report reporter.OEIReport) [][]string {
+// showTiers shows a table of the apps over entitlement, if any, followed by a
+// table of the apps near entitlement, if any, and a table of the apps that
+// would have been deprioritized, if any.
+func (r OverEntitlementInstancesRenderer) showTiers(logger lager.Logger, headers []string, overRows, nearRows, throttledRows [][]string) error {
+	if len(overRows) > 0 {
+		err := r.display.ShowTable(logger, append(append([]string{}, headers...), "instances over", "worst ratio"), overRows)
+		if err != nil {
+			return err
+		}
+	} else {
+		r.display.ShowMessage("No apps over entitlement.")
+	}
+
+	if len(nearRows) > 0 {
+		r.display.ShowMessage("\nApps near entitlement:\n")
+		err := r.display.ShowTable(logger, append(append([]string{}, headers...), "instances near", "worst ratio"), nearRows)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(throttledRows) == 0 {
+		return nil
+	}
+
+	r.display.ShowMessage("\nApps that would be deprioritized:\n")
+	return r.display.ShowTable(logger, append(append([]string{}, headers...), "instances deprioritized", "total duration"), throttledRows)
+}
+
+func buildOEITableRows(report reporter.OEIReport, near bool) [][]string {
 	var rows [][]string
 	for _, spaceReport := range report.SpaceReports {
-		for _, app := range spaceReport.Apps {
-			rows = append(rows, []string{spaceReport.SpaceName, app})
+		apps, threshold := spaceReport.Apps, report.Thresholds.Over
+		if near {
+			apps, threshold = spaceReport.NearApps, report.Thresholds.Near
+		}
+
+		for _, app := range apps {
+			rows = append(rows, []string{
+				spaceReport.SpaceName,
+				app.Name,
+				formatInstancesAbove(app, threshold),
+				fmt.Sprintf("%.2f%%", app.WorstInstanceReport().CumulativeUsage.Value*100),
+			})
 		}
 	}
 	return rows
 }
+
+func buildThrottledTableRows(report reporter.OEIReport) [][]string {
+	var rows [][]string
+	for _, spaceReport := range report.SpaceReports {
+		for _, app := range spaceReport.ThrottledApps {
+			var instances []string
+			var total time.Duration
+			for _, instanceReport := range app.DeprioritizedInstanceReports() {
+				instances = append(instances, fmt.Sprintf("#%d (%s)", instanceReport.InstanceID, pluralize(len(instanceReport.ThrottlePreview.Periods), "time")))
+				total += instanceReport.ThrottlePreview.TotalDuration()
+			}
+
+			rows = append(rows, []string{
+				spaceReport.SpaceName,
+				app.Name,
+				strings.Join(instances, ", "),
+				total.String(),
+			})
+		}
+	}
+	return rows
+}
+
+func formatInstancesAbove(app reporter.OEIAppReport, threshold float64) string {
+	var instances []string
+	for _, report := range app.InstanceReportsAbove(threshold) {
+		instances = append(instances, fmt.Sprintf("#%d (%.2f%%)", report.InstanceID, report.CumulativeUsage.Value*100))
+	}
+	return strings.Join(instances, ", ")
+}
+
+func prependColumn(value string, rows [][]string) [][]string {
+	var result [][]string
+	for _, row := range rows {
+		result = append(result, append([]string{value}, row...))
+	}
+	return result
+}
@@ -2,6 +2,7 @@ package output_test
 
 import (
 	"errors"
+	"time"
 
 	"code.cloudfoundry.org/cli/cf/terminal"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/output"
@@ -22,10 +23,24 @@ var _ = Describe("OEI Renderer", func() {
 	BeforeEach(func() {
 		display = new(outputfakes.FakeOverEntitlementInstancesDisplay)
 		spaceReports := []reporter.SpaceReport{
-			reporter.SpaceReport{SpaceName: "space-1", Apps: []string{"app-1-1", "app-1-2"}},
-			reporter.SpaceReport{SpaceName: "space-2", Apps: []string{"app-2-1"}},
+			reporter.SpaceReport{SpaceName: "space-1", Apps: []reporter.OEIAppReport{
+				{Name: "app-1-1", InstanceReports: []reporter.InstanceReport{
+					{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 1.5}},
+					{InstanceID: 1, CumulativeUsage: reporter.CumulativeUsage{Value: 0.5}},
+					{InstanceID: 2, CumulativeUsage: reporter.CumulativeUsage{Value: 1.25}},
+				}},
+				{Name: "app-1-2", InstanceReports: []reporter.InstanceReport{
+					{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 1.1}},
+				}},
+			}},
+			reporter.SpaceReport{SpaceName: "space-2", Apps: []reporter.OEIAppReport{
+				{Name: "app-2-1", InstanceReports: []reporter.InstanceReport{
+					{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 0.5}},
+					{InstanceID: 1, CumulativeUsage: reporter.CumulativeUsage{Value: 2}},
+				}},
+			}},
 		}
-		report = reporter.OEIReport{Org: "org", Username: "user", SpaceReports: spaceReports}
+		report = reporter.OEIReport{Org: "org", Username: "user", Thresholds: reporter.DefaultThresholds, SpaceReports: spaceReports}
 		renderer = output.NewOverEntitlementInstancesRenderer(display)
 	})
 
@@ -47,12 +62,80 @@ var _ = Describe("OEI Renderer", func() {
 	It("shows applications over entitlement", func() {
 		Expect(display.ShowTableCallCount()).To(Equal(1))
 		_, headers, rows := display.ShowTableArgsForCall(0)
-		Expect(headers).To(ConsistOf("space", "app"))
+		Expect(headers).To(Equal([]string{"space", "app", "instances over", "worst ratio"}))
 
-		Expect(len(rows)).To(Equal(3))
-		Expect(rows[0]).To(ConsistOf("space-1", "app-1-1"))
-		Expect(rows[1]).To(ConsistOf("space-1", "app-1-2"))
-		Expect(rows[2]).To(ConsistOf("space-2", "app-2-1"))
+		Expect(rows).To(Equal([][]string{
+			{"space-1", "app-1-1", "#0 (150.00%), #2 (125.00%)", "150.00%"},
+			{"space-1", "app-1-2", "#0 (110.00%)", "110.00%"},
+			{"space-2", "app-2-1", "#1 (200.00%)", "200.00%"},
+		}))
+	})
+
+	When("there are applications near entitlement", func() {
+		BeforeEach(func() {
+			report.SpaceReports[1].NearApps = []reporter.OEIAppReport{
+				{Name: "app-2-2", InstanceReports: []reporter.InstanceReport{
+					{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 0.97}},
+					{InstanceID: 1, CumulativeUsage: reporter.CumulativeUsage{Value: 0.5}},
+				}},
+			}
+		})
+
+		It("shows them in a separate table", func() {
+			Expect(display.ShowTableCallCount()).To(Equal(2))
+			Expect(display.ShowMessageCallCount()).To(Equal(2))
+			actualMsg, _ := display.ShowMessageArgsForCall(1)
+			Expect(actualMsg).To(Equal("\nApps near entitlement:\n"))
+
+			_, headers, rows := display.ShowTableArgsForCall(1)
+			Expect(headers).To(Equal([]string{"space", "app", "instances near", "worst ratio"}))
+			Expect(rows).To(Equal([][]string{
+				{"space-2", "app-2-2", "#0 (97.00%)", "97.00%"},
+			}))
+		})
+
+		When("no application is over entitlement", func() {
+			BeforeEach(func() {
+				report.SpaceReports = report.SpaceReports[1:]
+				report.SpaceReports[0].Apps = nil
+			})
+
+			It("says so and shows the applications near entitlement", func() {
+				Expect(display.ShowMessageCallCount()).To(Equal(3))
+				actualMsg, _ := display.ShowMessageArgsForCall(1)
+				Expect(actualMsg).To(Equal("No apps over entitlement."))
+
+				Expect(display.ShowTableCallCount()).To(Equal(1))
+				_, headers, _ := display.ShowTableArgsForCall(0)
+				Expect(headers).To(ContainElement("instances near"))
+			})
+		})
+	})
+
+	When("there are applications that would have been deprioritized", func() {
+		BeforeEach(func() {
+			period := reporter.Period{From: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC), To: time.Date(2020, 1, 1, 10, 15, 0, 0, time.UTC)}
+			report.SpaceReports[1].ThrottledApps = []reporter.OEIAppReport{
+				{Name: "app-2-3", InstanceReports: []reporter.InstanceReport{
+					{InstanceID: 0, ThrottlePreview: &reporter.ThrottlePreview{Window: time.Hour, Periods: []reporter.Period{period, period}}},
+					{InstanceID: 1, ThrottlePreview: &reporter.ThrottlePreview{Window: time.Hour}},
+					{InstanceID: 2, ThrottlePreview: &reporter.ThrottlePreview{Window: time.Hour, Periods: []reporter.Period{period}}},
+				}},
+			}
+		})
+
+		It("shows them in a separate table", func() {
+			Expect(display.ShowTableCallCount()).To(Equal(2))
+			Expect(display.ShowMessageCallCount()).To(Equal(2))
+			actualMsg, _ := display.ShowMessageArgsForCall(1)
+			Expect(actualMsg).To(Equal("\nApps that would be deprioritized:\n"))
+
+			_, headers, rows := display.ShowTableArgsForCall(1)
+			Expect(headers).To(Equal([]string{"space", "app", "instances deprioritized", "total duration"}))
+			Expect(rows).To(Equal([][]string{
+				{"space-2", "app-2-3", "#0 (2 times), #2 (1 time)", "45m0s"},
+			}))
+		})
 	})
 
 	When("there are no applications over entitlement", func() {
@@ -79,3 +162,82 @@ var _ = Describe("OEI Renderer", func() {
 	})
 
 })
+
+var _ = Describe("OEI Renderer for all orgs", func() {
+	var (
+		display   *outputfakes.FakeOverEntitlementInstancesDisplay
+		report    reporter.AllOrgsOEIReport
+		renderErr error
+		renderer  *output.OverEntitlementInstancesRenderer
+	)
+
+	BeforeEach(func() {
+		display = new(outputfakes.FakeOverEntitlementInstancesDisplay)
+		report = reporter.AllOrgsOEIReport{
+			Username: "user",
+			OrgReports: []reporter.OEIReport{
+				{Org: "org-1", Username: "user", Thresholds: reporter.DefaultThresholds, SpaceReports: []reporter.SpaceReport{
+					{SpaceName: "space-1", Apps: []reporter.OEIAppReport{
+						{Name: "app-1-1", InstanceReports: []reporter.InstanceReport{{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 1.5}}}},
+						{Name: "app-1-2", InstanceReports: []reporter.InstanceReport{{InstanceID: 1, CumulativeUsage: reporter.CumulativeUsage{Value: 1.1}}}},
+					}},
+				}},
+				{Org: "org-2", Username: "user", Thresholds: reporter.DefaultThresholds, SpaceReports: []reporter.SpaceReport{
+					{SpaceName: "space-1", Apps: []reporter.OEIAppReport{
+						{Name: "app-2-1", InstanceReports: []reporter.InstanceReport{{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 2}}}},
+					}},
+				}},
+			},
+		}
+		renderer = output.NewOverEntitlementInstancesRenderer(display)
+	})
+
+	JustBeforeEach(func() {
+		renderErr = renderer.RenderAllOrgs(logger, report)
+	})
+
+	It("succeeds", func() {
+		Expect(renderErr).NotTo(HaveOccurred())
+	})
+
+	It("shows report header", func() {
+		Expect(display.ShowMessageCallCount()).To(Equal(1))
+		actualMsg, actualMsgArgs := display.ShowMessageArgsForCall(0)
+		Expect(actualMsg).To(Equal("Showing over-entitlement apps in all orgs as %s...\n"))
+		Expect(actualMsgArgs).To(ConsistOf(terminal.EntityNameColor("user")))
+	})
+
+	It("shows applications over entitlement of every org in a single table", func() {
+		Expect(display.ShowTableCallCount()).To(Equal(1))
+		_, headers, rows := display.ShowTableArgsForCall(0)
+		Expect(headers).To(Equal([]string{"org", "space", "app", "instances over", "worst ratio"}))
+		Expect(rows).To(Equal([][]string{
+			{"org-1", "space-1", "app-1-1", "#0 (150.00%)", "150.00%"},
+			{"org-1", "space-1", "app-1-2", "#1 (110.00%)", "110.00%"},
+			{"org-2", "space-1", "app-2-1", "#0 (200.00%)", "200.00%"},
+		}))
+	})
+
+	When("there are no applications over entitlement", func() {
+		BeforeEach(func() {
+			report = reporter.AllOrgsOEIReport{Username: "user"}
+		})
+
+		It("shows a no applications over entitlement message", func() {
+			Expect(display.ShowMessageCallCount()).To(Equal(1))
+			actualMsg, _ := display.ShowMessageArgsForCall(0)
+			Expect(actualMsg).To(Equal("No apps over entitlement in any org.\n"))
+			Expect(display.ShowTableCallCount()).To(Equal(0))
+		})
+	})
+
+	When("showing the table errors", func() {
+		BeforeEach(func() {
+			display.ShowTableReturns(errors.New("table-error"))
+		})
+
+		It("returns the error", func() {
+			Expect(renderErr).To(MatchError("table-error"))
+		})
+	})
+})
@@ -9,6 +9,10 @@ import (
 )
 
 type FakeAppDisplay struct {
+	ClearStub        func()
+	clearMutex       sync.RWMutex
+	clearArgsForCall []struct {
+	}
 	ShowMessageStub        func(string, ...interface{})
 	showMessageMutex       sync.RWMutex
 	showMessageArgsForCall []struct {
@@ -32,15 +36,40 @@ type FakeAppDisplay struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeAppDisplay) Clear() {
+	fake.clearMutex.Lock()
+	fake.clearArgsForCall = append(fake.clearArgsForCall, struct {
+	}{})
+	stub := fake.ClearStub
+	fake.recordInvocation("Clear", []interface{}{})
+	fake.clearMutex.Unlock()
+	if stub != nil {
+		fake.ClearStub()
+	}
+}
+
+func (fake *FakeAppDisplay) ClearCallCount() int {
+	fake.clearMutex.RLock()
+	defer fake.clearMutex.RUnlock()
+	return len(fake.clearArgsForCall)
+}
+
+func (fake *FakeAppDisplay) ClearCalls(stub func()) {
+	fake.clearMutex.Lock()
+	defer fake.clearMutex.Unlock()
+	fake.ClearStub = stub
+}
+
 func (fake *FakeAppDisplay) ShowMessage(arg1 string, arg2 ...interface{}) {
 	fake.showMessageMutex.Lock()
 	fake.showMessageArgsForCall = append(fake.showMessageArgsForCall, struct {
 		arg1 string
 		arg2 []interface{}
 	}{arg1, arg2})
+	stub := fake.ShowMessageStub
 	fake.recordInvocation("ShowMessage", []interface{}{arg1, arg2})
 	fake.showMessageMutex.Unlock()
-	if fake.ShowMessageStub != nil {
+	if stub != nil {
 		fake.ShowMessageStub(arg1, arg2...)
 	}
 }
@@ -82,15 +111,16 @@ func (fake *FakeAppDisplay) ShowTable(arg1 lager.Logger, arg2 []string, arg3 [][
 		arg2 []string
 		arg3 [][]string
 	}{arg1, arg2Copy, arg3Copy})
+	stub := fake.ShowTableStub
+	fakeReturns := fake.showTableReturns
 	fake.recordInvocation("ShowTable", []interface{}{arg1, arg2Copy, arg3Copy})
 	fake.showTableMutex.Unlock()
-	if fake.ShowTableStub != nil {
-		return fake.ShowTableStub(arg1, arg2, arg3)
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.showTableReturns
 	return fakeReturns.result1
 }
 
@@ -139,6 +169,8 @@ func (fake *FakeAppDisplay) ShowTableReturnsOnCall(i int, result1 error) {
 func (fake *FakeAppDisplay) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.clearMutex.RLock()
+	defer fake.clearMutex.RUnlock()
 	fake.showMessageMutex.RLock()
 	defer fake.showMessageMutex.RUnlock()
 	fake.showTableMutex.RLock()
@@ -0,0 +1,45 @@
+package output
+
+import (
+	"math"
+	"strings"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+)
+
+var sparklineBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws one bar per sample, scaled so that max is drawn as a full
+// bar.
+func sparkline(samples []reporter.UsageSample, max float64) string {
+	var line strings.Builder
+	for _, sample := range samples {
+		bar := int(math.Round(sample.Value / max * float64(len(sparklineBars)-1)))
+		if bar < 0 {
+			bar = 0
+		}
+		if bar >= len(sparklineBars) {
+			bar = len(sparklineBars) - 1
+		}
+		line.WriteRune(sparklineBars[bar])
+	}
+
+	return line.String()
+}
+
+// sparklineScale is the highest usage among the instances, but at least the
+// entitlement, so that sparklines of idle apps do not look busy and all the
+// sparklines of a report share the same scale.
+func sparklineScale(appReport reporter.ApplicationReport) float64 {
+	scale := 1.0
+	for _, report := range appReport.InstanceReports {
+		if report.UsageHistory == nil {
+			continue
+		}
+		for _, sample := range report.UsageHistory.Samples {
+			scale = math.Max(scale, sample.Value)
+		}
+	}
+
+	return scale
+}
@@ -1,10 +1,12 @@
 package plugins
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/signal"
 	"regexp"
 	"time"
 
@@ -16,12 +18,19 @@ import (
 	"code.cloudfoundry.org/cpu-entitlement-plugin/httpclient"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/output"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/result"
 	"code.cloudfoundry.org/lager"
 	logcache "code.cloudfoundry.org/log-cache/pkg/client"
 	flags "github.com/jessevdk/go-flags"
 )
 
 const month time.Duration = 31 * 24 * time.Hour
+const defaultHistoryRange time.Duration = 30 * time.Minute
+const defaultStatsRange time.Duration = 24 * time.Hour
+
+// recommendationTarget is the ratio of the entitlement recommendations aim
+// to keep the usage of the busiest instance below.
+const recommendationTarget = 0.9
 
 type CPUEntitlementPlugin struct{}
 
@@ -34,7 +43,27 @@ func (p CPUEntitlementPlugin) Run(cli plugin.CliConnection, args []string) {
 	ui := terminal.NewUI(os.Stdin, os.Stdout, terminal.NewTeePrinter(os.Stdout), traceLogger)
 
 	opts := struct {
-		Debug bool `short:"d" long:"debug" description:"Show verbose debug information"`
+		Debug       bool          `short:"d" long:"debug" description:"Show verbose debug information"`
+		Output      string        `short:"o" long:"output" choice:"table" choice:"json" default:"table" description:"Output format"`
+		Watch       bool          `short:"w" long:"watch" description:"Keep refreshing the report"`
+		Interval    time.Duration `long:"interval" default:"10s" description:"Time between refreshes in watch mode"`
+		Since       string        `long:"since" description:"Average usage from this time on (a duration such as 6h, or a date)"`
+		Until       string        `long:"until" description:"Average usage up to this time (a duration such as 1h, or a date)"`
+		Spikes      bool          `long:"spikes" description:"List every spike of each instance in the spike window"`
+		SpikeWindow string        `long:"spike-window" description:"Look for spikes from this time on (a duration such as 72h, or a date)"`
+		History     bool          `long:"history" description:"Show the usage over time of each instance"`
+		Stats       bool          `long:"stats" description:"Show percentiles of the usage of each instance"`
+		Step        time.Duration `long:"step" default:"1m" description:"Time between samples of the usage history and stats"`
+		Space       bool          `long:"space" description:"Summarise the usage of every app in the targeted space"`
+		Recommend   bool          `long:"recommend" description:"Recommend how much to scale the memory or instances of busy apps"`
+		Units       string        `long:"units" choice:"ratio" choice:"cores" choice:"both" default:"ratio" description:"Show usage as a ratio of the entitlement, in cores, or both"`
+		Throttle    bool          `long:"throttle-preview" description:"Show when each instance would have been deprioritized under a rolling-window policy"`
+		Window      time.Duration `long:"window" default:"1h" description:"Rolling window of the throttle preview"`
+		Timeout     time.Duration `long:"timeout" default:"30s" description:"Give up on any request to log-cache or the Cloud Controller that takes longer than this"`
+		CACerts     []string      `long:"ca-cert" description:"Also trust the certificate authorities in this PEM file (can be repeated)"`
+		Retries     int           `long:"retries" default:"3" description:"Number of times to retry a request to log-cache that fails with a transient error"`
+		Concurrency int           `long:"concurrency" default:"10" description:"Number of apps to report on at the same time"`
+		ThresholdOpts
 	}{}
 
 	args, err := flags.ParseArgs(&opts, args)
@@ -58,44 +87,182 @@ func (p CPUEntitlementPlugin) Run(cli plugin.CliConnection, args []string) {
 		os.Exit(0)
 	}
 
-	if len(args) != 2 {
-		ui.Failed("Usage: cf cpu-entitlement <APP_NAME>")
+	if opts.Space {
+		if len(args) != 1 {
+			ui.Failed("Usage: cf cpu-entitlement --space")
+			os.Exit(1)
+		}
+		if opts.Watch {
+			ui.Failed("--watch cannot be used together with --space.")
+			os.Exit(1)
+		}
+		if opts.Recommend {
+			ui.Failed("--recommend cannot be used together with --space.")
+			os.Exit(1)
+		}
+		if opts.Units != string(output.UnitsRatio) {
+			ui.Failed("--units cannot be used together with --space.")
+			os.Exit(1)
+		}
+		if opts.Throttle {
+			ui.Failed("--throttle-preview cannot be used together with --space.")
+			os.Exit(1)
+		}
+	} else if len(args) < 2 {
+		ui.Failed("Usage: cf cpu-entitlement <APP_NAME>...")
+		os.Exit(1)
+	}
+
+	if opts.Watch && opts.Interval <= 0 {
+		ui.Failed("The watch interval must be positive.")
+		os.Exit(1)
+	}
+
+	if opts.Timeout <= 0 {
+		ui.Failed("The timeout must be positive.")
+		os.Exit(1)
+	}
+
+	if opts.Retries < 0 {
+		ui.Failed("The number of retries cannot be negative.")
+		os.Exit(1)
+	}
+
+	if opts.Concurrency < 1 {
+		ui.Failed("The concurrency must be at least 1.")
+		os.Exit(1)
+	}
+
+	if opts.Throttle && opts.Window <= 0 {
+		ui.Failed("The throttle preview window must be positive.")
 		os.Exit(1)
 	}
 
-	ui.Warn("Note: This feature is experimental.")
+	if (opts.History || opts.Stats || opts.Recommend || opts.Throttle) && opts.Step < time.Second {
+		ui.Failed("The step must be at least 1s.")
+		os.Exit(1)
+	}
 
-	logCacheURL, err := getLogCacheURL(cli)
+	thresholds, err := opts.Thresholds()
 	if err != nil {
 		ui.Failed(err.Error())
 		os.Exit(1)
 	}
 
-	sslIsDisabled, err := cli.IsSSLDisabled()
+	usageRange, err := ParseTimeRange(opts.Since, opts.Until, time.Now())
 	if err != nil {
 		ui.Failed(err.Error())
 		os.Exit(1)
 	}
-	cfClient := cf.NewClient(cli, fetchers.NewProcessInstanceIDFetcher(createLogClient(logCacheURL, cli.AccessToken, sslIsDisabled)))
-	lastSpikeFetcher := fetchers.NewLastSpikeFetcher(
-		createLogClient(logCacheURL, cli.AccessToken, sslIsDisabled),
-		time.Now().Add(-month),
-	)
 
-	currentUsageFetcher := fetchers.NewCurrentUsageFetcher(
-		createLogClient(logCacheURL, cli.AccessToken, sslIsDisabled),
-	)
+	if opts.SpikeWindow != "" {
+		if _, err = ParseTimeArg(opts.SpikeWindow, time.Now()); err != nil {
+			ui.Failed(err.Error())
+			os.Exit(1)
+		}
+	}
+	// spikeWindowAt returns the start of the spike window, which ends at now.
+	spikeWindowAt := func(now time.Time) time.Time {
+		if opts.SpikeWindow == "" {
+			return now.Add(-month)
+		}
+		since, _ := ParseTimeArg(opts.SpikeWindow, now)
+		return since
+	}
 
-	cumulativeUsageFetcher := fetchers.NewCumulativeUsageFetcher(
-		createLogClient(logCacheURL, cli.AccessToken, sslIsDisabled),
-	)
-	metricsReporter := reporter.NewAppReporter(cfClient, currentUsageFetcher, lastSpikeFetcher, cumulativeUsageFetcher)
-	display := output.NewTerminalDisplay(ui)
-	metricsRenderer := output.NewAppRenderer(display)
+	if opts.Output == "table" {
+		ui.Warn("Note: This feature is experimental.")
+	}
+
+	apiURL, err := getAPIURL(cli)
+	if err != nil {
+		ui.Failed(err.Error())
+		os.Exit(1)
+	}
+
+	logCacheURL, err := getLogCacheURL(apiURL)
+	if err != nil {
+		ui.Failed(err.Error())
+		os.Exit(1)
+	}
+
+	authClient, err := createAuthClient(cli, opts.CACerts, opts.Timeout)
+	if err != nil {
+		ui.Failed(err.Error())
+		os.Exit(1)
+	}
+
+	// The log-cache client is shared by all the fetchers, which run
+	// concurrently when reporting on several apps.
+	logClient := createFetchersLogClient(logger, logCacheURL, authClient, opts.Retries, opts.Timeout)
+	cfClient := createCFClient(cli, apiURL, authClient, fetchers.NewProcessInstanceIDFetcher(logClient))
+	currentUsageFetcher := fetchers.NewCurrentUsageFetcher(logClient)
+
+	// The reporter is created again on every refresh in watch mode, so that
+	// windows given as durations, such as --since 2h, and the history, stats
+	// and throttle preview ranges end at the time of the refresh.
+	newReporter := func(now time.Time) reporter.AppReporter {
+		usageWindow := usageRange.At(now)
+		spikeWindowSince := spikeWindowAt(now)
+		lastSpikeFetcher := fetchers.NewLastSpikeFetcher(logClient, spikeWindowSince)
+		cumulativeUsageFetcher := fetchers.NewCumulativeUsageFetcherWithWindow(logClient, usageWindow.Since, usageWindow.Until)
+		metricsReporter := reporter.NewAppReporter(cfClient, currentUsageFetcher, lastSpikeFetcher, cumulativeUsageFetcher).
+			WithUsageWindow(usageWindow).
+			WithConcurrency(opts.Concurrency)
+		if opts.Spikes {
+			metricsReporter = metricsReporter.WithSpikeHistoryFetcher(fetchers.NewSpikeHistoryFetcher(logClient, spikeWindowSince))
+		}
+		if opts.History {
+			historyRange := WindowOrLast(usageWindow, defaultHistoryRange, now)
+			metricsReporter = metricsReporter.WithUsageHistoryFetcher(fetchers.NewUsageHistoryFetcher(logClient, historyRange.Since, historyRange.Until, opts.Step))
+		}
+		statsRange := WindowOrLast(usageWindow, defaultStatsRange, now)
+		usageStatsFetcher := fetchers.NewUsageHistoryFetcher(logClient, statsRange.Since, statsRange.Until, opts.Step)
+		if opts.Stats {
+			metricsReporter = metricsReporter.WithUsageStatsFetcher(usageStatsFetcher)
+		}
+		if opts.Recommend {
+			metricsReporter = metricsReporter.WithRecommendations(recommendationTarget, usageStatsFetcher)
+		}
+		if opts.Throttle {
+			metricsReporter = metricsReporter.WithThrottlePreview(
+				newThrottleHistoryFetcher(logClient, WindowOrLast(usageWindow, defaultStatsRange, now), opts.Window, opts.Step),
+				reporter.ThrottleModel{Window: opts.Window, Threshold: thresholds.Over},
+			)
+		}
+		if opts.Units != string(output.UnitsRatio) {
+			metricsReporter = metricsReporter.WithCPURateFetcher(fetchers.NewCPURateFetcher(logClient))
+		}
+		if opts.Spikes || opts.SpikeWindow != "" {
+			metricsReporter = metricsReporter.WithSpikeWindow(spikeWindowSince, fetchers.NewRetentionFetcher(logClient))
+		}
+		return metricsReporter
+	}
+
+	var metricsRenderer OutputRenderer = output.NewAppRenderer(output.NewTerminalDisplay(ui)).
+		WithThresholds(thresholds).
+		WithUnits(output.Units(opts.Units))
+	if opts.Output == "json" {
+		metricsRenderer = output.NewAppJSONRenderer(os.Stdout)
+	}
+
+	appNames := args[1:]
+	runner := NewAppRunner(newReporter(time.Now()), metricsRenderer).
+		WithReporterFactory(func(now time.Time) Reporter { return newReporter(now) })
 
-	appName := args[1]
-	runner := NewAppRunner(metricsReporter, metricsRenderer)
-	res := runner.Run(logger, appName)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var res result.Result
+	if opts.Space {
+		res = runner.RunSpace(ctx, logger)
+	} else if opts.Watch {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		res = runner.Watch(ctx, logger, appNames, ticker.C)
+	} else {
+		res = runner.Run(ctx, logger, appNames)
+	}
 	if res.IsFailure {
 		if res.ErrorMessage != "" {
 			ui.Failed(res.ErrorMessage)
@@ -123,14 +290,44 @@ func (p CPUEntitlementPlugin) GetMetadata() plugin.PluginMetadata {
 				Alias:    "cpu",
 				HelpText: "See cpu usage per app",
 				UsageDetails: plugin.Usage{
-					Usage: "cf cpu-entitlement APP_NAME",
+					Usage: "cf cpu-entitlement (APP_NAME... | --space) [--output table|json] [--watch [--interval DURATION]] [--since TIME [--until TIME]] [--spikes] [--spike-window TIME] [--history] [--stats] [--step DURATION] [--recommend] [--units ratio|cores|both] [--throttle-preview [--window DURATION]] [--concurrency N] [--timeout DURATION] [--retries N] [--ca-cert FILE]... [--threshold PERCENT] [--near-threshold PERCENT]",
+					Options: map[string]string{
+						"output, -o":       "Output format: table (default) or json",
+						"space":            "Summarise the usage of every app in the targeted space, sorted by average usage",
+						"watch, -w":        "Keep refreshing the report until interrupted",
+						"interval":         "Time between refreshes in watch mode (default 10s)",
+						"since":            "Average usage from this time on, e.g. 6h or '2006-01-02 15:04:05' (default: since each instance started)",
+						"until":            "Average usage up to this time, e.g. 1h or '2006-01-02 15:04:05' (default: now)",
+						"spikes":           "List every spike of each instance in the spike window, with totals",
+						"spike-window":     "Look for spikes from this time on, e.g. 72h or '2006-01-02' (default: 1 month ago)",
+						"history":          "Show the usage over time of each instance, between --since and --until or in the last 30 minutes",
+						"stats":            "Show the p50, p95, p99 and max usage of each instance, between --since and --until or in the last 24 hours",
+						"step":             "Time between samples of the usage history and stats (default 1m)",
+						"units":            "Show usage as a ratio of the entitlement (default), in cores, or both",
+						"recommend":        "Recommend how much to scale the memory or instances to bring the p95 usage below 90% of entitlement",
+						"throttle-preview": "Show when and for how long each instance would have been deprioritized if its average usage over a rolling window were limited to the threshold, between --since and --until or in the last 24 hours",
+						"window":           "Rolling window of the throttle preview (default 1h)",
+						"concurrency":      "Number of apps to report on at the same time (default 10)",
+						"timeout":          "Give up on any request to log-cache or the Cloud Controller that takes longer than this (default 30s)",
+						"ca-cert":          "Also trust the certificate authorities in this PEM file, on top of those of the system and of SSL_CERT_FILE (can be repeated)",
+						"retries":          "Number of times to retry a request to log-cache that fails with a 429, 502, 503 or 504 status or a network error (default 3)",
+						"threshold":        thresholdUsage,
+						"near-threshold":   nearThresholdUsage,
+					},
 				},
 			},
 		},
 	}
 }
 
-func getLogCacheURL(cli plugin.CliConnection) (string, error) {
+// newThrottleHistoryFetcher fetches the usage history in the preview range,
+// plus a whole window before it, so that the preview can cover the start of
+// the range.
+func newThrottleHistoryFetcher(client fetchers.LogCacheClient, previewRange reporter.TimeWindow, window, step time.Duration) fetchers.UsageHistoryFetcher {
+	return fetchers.NewUsageHistoryFetcher(client, previewRange.Since.Add(-window), previewRange.Until, step)
+}
+
+func getAPIURL(cli plugin.CliConnection) (string, error) {
 	hasAPISet, err := cli.HasAPIEndpoint()
 	if err != nil {
 		return "", err
@@ -138,11 +335,10 @@ func getLogCacheURL(cli plugin.CliConnection) (string, error) {
 	if !hasAPISet {
 		return "", errors.New("No API endpoint set. Use 'cf login' or 'cf api' to target an endpoint.")
 	}
-	apiURL, err := cli.ApiEndpoint()
-	if err != nil {
-		return "", err
-	}
+	return cli.ApiEndpoint()
+}
 
+func getLogCacheURL(apiURL string) (string, error) {
 	re := regexp.MustCompile(`(https?://)[^.]+(\..*)`)
 	match := re.FindStringSubmatch(apiURL)
 	if len(match) != 3 {
@@ -153,13 +349,44 @@ func getLogCacheURL(cli plugin.CliConnection) (string, error) {
 
 }
 
-func createLogClient(logCacheURL string, accessTokenFunc func() (string, error), skipSSLValidation bool) *logcache.Client {
-	httpClient := httpclient.NewAuthClient(accessTokenFunc)
-	if skipSSLValidation {
-		httpClient.SkipSSLValidation()
+// createAuthClient returns the client shared by the clients of the Cloud
+// Controller and log-cache, so that they reuse connections and refresh the
+// token only once between them. It skips SSL validation when the cf CLI does.
+func createAuthClient(cli plugin.CliConnection, caCertFiles []string, requestTimeout time.Duration) (*httpclient.AuthClient, error) {
+	sslIsDisabled, err := cli.IsSSLDisabled()
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := httpclient.NewHTTPClient(httpclient.Config{
+		SkipSSLValidation: sslIsDisabled,
+		CACertFiles:       caCertFiles,
+		Timeout:           requestTimeout,
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	return httpclient.NewAuthClient(httpclient.NewTokenGetter(cli.AccessToken), httpClient), nil
+}
+
+func createLogClient(logCacheURL string, authClient *httpclient.AuthClient) *logcache.Client {
 	return logcache.NewClient(
 		logCacheURL,
-		logcache.WithHTTPClient(httpClient),
+		logcache.WithHTTPClient(httpclient.NewStatusClient(authClient)),
 	)
 }
+
+// createFetchersLogClient returns the log-cache client shared by the
+// fetchers. Failed requests are retried within the request timeout.
+func createFetchersLogClient(logger lager.Logger, logCacheURL string, authClient *httpclient.AuthClient, retries int, requestTimeout time.Duration) fetchers.LogCacheClient {
+	logClient := fetchers.NewRetryingLogCacheClient(createLogClient(logCacheURL, authClient), logger, retries)
+	return fetchers.NewTimeoutLogCacheClient(logClient, requestTimeout)
+}
+
+// createCFClient returns a client that calls the v3 API of the cloud
+// controller directly.
+func createCFClient(cli plugin.CliConnection, apiURL string, authClient *httpclient.AuthClient, processInstanceIDFetcher cf.ProcessInstanceIDFetcher) cf.Client {
+	v3Client := cf.NewV3Client(apiURL, authClient)
+	return cf.NewClient(cli, processInstanceIDFetcher).WithCloudController(v3Client)
+}
@@ -1,7 +1,12 @@
 package plugins
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"code.cloudfoundry.org/cli/cf/terminal"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/result"
 	"code.cloudfoundry.org/lager"
@@ -11,17 +16,24 @@ import (
 //go:generate counterfeiter . OutputRenderer
 
 type OutputRenderer interface {
-	ShowApplicationReport(logger lager.Logger, appReport reporter.ApplicationReport) error
+	ShowApplicationReports(logger lager.Logger, appReports []reporter.ApplicationReport) error
+	ShowApplicationReportsUpdate(logger lager.Logger, previousReports, appReports []reporter.ApplicationReport) error
+	ShowSpaceReport(logger lager.Logger, spaceReport reporter.SpaceUsageReport) error
 }
 
 //go:generate counterfeiter . Reporter
 
 type Reporter interface {
-	CreateApplicationReport(logger lager.Logger, appName string) (reporter.ApplicationReport, error)
+	CreateApplicationReports(ctx context.Context, logger lager.Logger, appNames []string) ([]reporter.ApplicationReport, error)
+	CreateSpaceReport(ctx context.Context, logger lager.Logger) (reporter.SpaceUsageReport, error)
 }
 
+// ReporterFactory creates a reporter whose time windows are relative to now.
+type ReporterFactory func(now time.Time) Reporter
+
 type AppRunner struct {
 	reporter        Reporter
+	newReporter     ReporterFactory
 	metricsRenderer OutputRenderer
 }
 
@@ -32,21 +44,44 @@ func NewAppRunner(reporter Reporter, metricsRenderer OutputRenderer) AppRunner {
 	}
 }
 
-func (r AppRunner) Run(logger lager.Logger, appName string) result.Result {
-	logger = logger.Session("run", lager.Data{"app": appName})
+// WithReporterFactory makes Watch create a new reporter on every tick, at the
+// time of the tick, so that windows such as the last 30 minutes of usage
+// history move forward between refreshes.
+func (r AppRunner) WithReporterFactory(newReporter ReporterFactory) AppRunner {
+	r.newReporter = newReporter
+	return r
+}
+
+func (r AppRunner) Run(ctx context.Context, logger lager.Logger, appNames []string) result.Result {
+	logger = logger.Session("run", lager.Data{"apps": appNames})
 	logger.Info("start")
 	defer logger.Info("end")
 
-	applicationReport, err := r.reporter.CreateApplicationReport(logger, appName)
+	applicationReports, res := r.createApplicationReports(ctx, logger, r.reporter, appNames)
+	if res.IsFailure {
+		return res
+	}
+
+	err := r.metricsRenderer.ShowApplicationReports(logger, applicationReports)
 	if err != nil {
-		if _, ok := err.(reporter.UnsupportedCFDeploymentError); ok {
-			return result.FailureFromError(err)
-		}
+		return result.FailureFromError(err)
+	}
 
-		return result.FailureFromError(err).WithWarning(bold("Your Cloud Foundry may not have enabled the CPU Entitlements feature. Please consult your operator."))
+	return result.Success()
+}
+
+// RunSpace renders a summary of every app in the targeted space.
+func (r AppRunner) RunSpace(ctx context.Context, logger lager.Logger) result.Result {
+	logger = logger.Session("run-space")
+	logger.Info("start")
+	defer logger.Info("end")
+
+	spaceReport, err := r.reporter.CreateSpaceReport(ctx, logger)
+	if err != nil {
+		return failureFromError(err)
 	}
 
-	err = r.metricsRenderer.ShowApplicationReport(logger, applicationReport)
+	err = r.metricsRenderer.ShowSpaceReport(logger, spaceReport)
 	if err != nil {
 		return result.FailureFromError(err)
 	}
@@ -54,6 +89,79 @@ func (r AppRunner) Run(logger lager.Logger, appName string) result.Result {
 	return result.Success()
 }
 
+// Watch renders fresh reports for the apps every time a tick is received,
+// until the ticks channel is closed or the context is cancelled. The log-cache
+// clients are reused across ticks, and so is the reporter unless the runner
+// has a reporter factory.
+func (r AppRunner) Watch(ctx context.Context, logger lager.Logger, appNames []string, ticks <-chan time.Time) result.Result {
+	logger = logger.Session("watch", lager.Data{"apps": appNames})
+	logger.Info("start")
+	defer logger.Info("end")
+
+	var previousReports []reporter.ApplicationReport
+	now := time.Now()
+	for {
+		applicationReports, res := r.createApplicationReports(ctx, logger, r.reporterAt(now), appNames)
+		if ctx.Err() != nil {
+			return result.Success()
+		}
+		if res.IsFailure {
+			return res
+		}
+
+		err := r.metricsRenderer.ShowApplicationReportsUpdate(logger, previousReports, applicationReports)
+		if err != nil {
+			return result.FailureFromError(err)
+		}
+		previousReports = applicationReports
+
+		select {
+		case <-ctx.Done():
+			return result.Success()
+		case tick, ok := <-ticks:
+			if !ok {
+				return result.Success()
+			}
+			now = tick
+		}
+	}
+}
+
+func (r AppRunner) reporterAt(now time.Time) Reporter {
+	if r.newReporter == nil {
+		return r.reporter
+	}
+	return r.newReporter(now)
+}
+
+func (r AppRunner) createApplicationReports(ctx context.Context, logger lager.Logger, appReporter Reporter, appNames []string) ([]reporter.ApplicationReport, result.Result) {
+	applicationReports, err := appReporter.CreateApplicationReports(ctx, logger, appNames)
+	if err != nil {
+		switch err.(type) {
+		case reporter.UnsupportedCFDeploymentError, reporter.NoMatchingAppsError:
+			return nil, result.FailureFromError(err)
+		}
+
+		var timeoutErr fetchers.TimeoutError
+		if errors.As(err, &timeoutErr) || errors.Is(err, context.Canceled) {
+			return nil, failureFromError(err)
+		}
+
+		return nil, result.FailureFromError(err).WithWarning(bold("Your Cloud Foundry may not have enabled the CPU Entitlements feature. Please consult your operator."))
+	}
+
+	return applicationReports, result.Success()
+}
+
+// failureFromError reports an interruption by the user as such, rather than
+// with the error of whichever request it cancelled.
+func failureFromError(err error) result.Result {
+	if errors.Is(err, context.Canceled) {
+		return result.Failure("Interrupted.")
+	}
+	return result.FailureFromError(err)
+}
+
 func bold(message string) string {
 	return terminal.Colorize(message, color.Bold)
 }
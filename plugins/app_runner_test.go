@@ -1,12 +1,16 @@
 package plugins_test
 
 import (
+	"context"
 	"errors"
+	"time"
 
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/plugins"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/plugins/pluginsfakes"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/result"
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
 
 	. "github.com/onsi/ginkgo"
@@ -53,23 +57,23 @@ var _ = Describe("App Runner", func() {
 			},
 		}
 
-		instanceReporter.CreateApplicationReportReturns(applicationReport, nil)
+		instanceReporter.CreateApplicationReportsReturns([]reporter.ApplicationReport{applicationReport}, nil)
 	})
 
 	JustBeforeEach(func() {
-		runResult = runner.Run(logger, "app-name")
+		runResult = runner.Run(context.Background(), logger, []string{"app-name", "other-app-*"})
 	})
 
 	It("prints the app CPU metrics", func() {
 		Expect(runResult.IsFailure).To(BeFalse())
 
-		Expect(instanceReporter.CreateApplicationReportCallCount()).To(Equal(1))
-		_, actualAppName := instanceReporter.CreateApplicationReportArgsForCall(0)
-		Expect(actualAppName).To(Equal("app-name"))
+		Expect(instanceReporter.CreateApplicationReportsCallCount()).To(Equal(1))
+		_, _, actualAppNames := instanceReporter.CreateApplicationReportsArgsForCall(0)
+		Expect(actualAppNames).To(Equal([]string{"app-name", "other-app-*"}))
 
-		Expect(outputRenderer.ShowApplicationReportCallCount()).To(Equal(1))
-		_, actualApplicationReport := outputRenderer.ShowApplicationReportArgsForCall(0)
-		Expect(actualApplicationReport).To(Equal(applicationReport))
+		Expect(outputRenderer.ShowApplicationReportsCallCount()).To(Equal(1))
+		_, actualApplicationReports := outputRenderer.ShowApplicationReportsArgsForCall(0)
+		Expect(actualApplicationReports).To(Equal([]reporter.ApplicationReport{applicationReport}))
 	})
 
 	It("logs start and end of function", func() {
@@ -79,7 +83,7 @@ var _ = Describe("App Runner", func() {
 
 	When("creating the reports fails with a unsupported cf-deployment error", func() {
 		BeforeEach(func() {
-			instanceReporter.CreateApplicationReportReturns(reporter.ApplicationReport{}, reporter.NewUnsupportedCFDeploymentError("app-name"))
+			instanceReporter.CreateApplicationReportsReturns(nil, reporter.NewUnsupportedCFDeploymentError("app-name"))
 		})
 
 		It("returns a failure", func() {
@@ -89,9 +93,21 @@ var _ = Describe("App Runner", func() {
 		})
 	})
 
+	When("an app name pattern matches no apps", func() {
+		BeforeEach(func() {
+			instanceReporter.CreateApplicationReportsReturns(nil, reporter.NewNoMatchingAppsError("other-app-*"))
+		})
+
+		It("returns a failure without a warning", func() {
+			Expect(runResult.IsFailure).To(BeTrue())
+			Expect(runResult.ErrorMessage).To(ContainSubstring("other-app-*"))
+			Expect(runResult.WarningMessage).To(BeEmpty())
+		})
+	})
+
 	When("creating the reports fails with a general error", func() {
 		BeforeEach(func() {
-			instanceReporter.CreateApplicationReportReturns(reporter.ApplicationReport{}, errors.New("reports error"))
+			instanceReporter.CreateApplicationReportsReturns(nil, errors.New("reports error"))
 		})
 
 		It("returns a failure", func() {
@@ -101,9 +117,249 @@ var _ = Describe("App Runner", func() {
 		})
 	})
 
+	When("a request to log-cache times out", func() {
+		BeforeEach(func() {
+			instanceReporter.CreateApplicationReportsReturns(nil, fetchers.TimeoutError{Fetch: "current usage", SourceID: "app-guid", Err: context.DeadlineExceeded})
+		})
+
+		It("returns a failure without a warning", func() {
+			Expect(runResult.IsFailure).To(BeTrue())
+			Expect(runResult.ErrorMessage).To(Equal("Timed out fetching the current usage of app-guid from log-cache"))
+			Expect(runResult.WarningMessage).To(BeEmpty())
+		})
+	})
+
+	When("the reports are interrupted", func() {
+		BeforeEach(func() {
+			instanceReporter.CreateApplicationReportsReturns(nil, context.Canceled)
+		})
+
+		It("returns a failure without a warning", func() {
+			Expect(runResult.IsFailure).To(BeTrue())
+			Expect(runResult.ErrorMessage).To(Equal("Interrupted."))
+			Expect(runResult.WarningMessage).To(BeEmpty())
+		})
+	})
+
 	When("rendering the app metrics fails", func() {
 		BeforeEach(func() {
-			outputRenderer.ShowApplicationReportReturns(errors.New("render error"))
+			outputRenderer.ShowApplicationReportsReturns(errors.New("render error"))
+		})
+
+		It("returns a failure", func() {
+			Expect(runResult.IsFailure).To(BeTrue())
+			Expect(runResult.ErrorMessage).To(Equal("render error"))
+		})
+	})
+})
+
+var _ = Describe("App Runner Watch", func() {
+	var (
+		instanceReporter *pluginsfakes.FakeReporter
+		outputRenderer   *pluginsfakes.FakeOutputRenderer
+
+		runner            plugins.AppRunner
+		runResult         result.Result
+		applicationReport reporter.ApplicationReport
+		updatedReport     reporter.ApplicationReport
+		ticks             chan time.Time
+		ctx               context.Context
+		logger            *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		instanceReporter = new(pluginsfakes.FakeReporter)
+		outputRenderer = new(pluginsfakes.FakeOutputRenderer)
+		logger = lagertest.NewTestLogger("app-runner-test")
+		runner = plugins.NewAppRunner(instanceReporter, outputRenderer)
+
+		applicationReport = reporter.ApplicationReport{
+			InstanceReports: []reporter.InstanceReport{
+				{InstanceID: 0, CurrentUsage: reporter.CurrentUsage{Value: 0.5}},
+			},
+		}
+		updatedReport = reporter.ApplicationReport{
+			InstanceReports: []reporter.InstanceReport{
+				{InstanceID: 0, CurrentUsage: reporter.CurrentUsage{Value: 1.5}},
+			},
+		}
+		instanceReporter.CreateApplicationReportsReturnsOnCall(0, []reporter.ApplicationReport{applicationReport}, nil)
+		instanceReporter.CreateApplicationReportsReturnsOnCall(1, []reporter.ApplicationReport{updatedReport}, nil)
+
+		ticks = make(chan time.Time, 1)
+		ticks <- time.Now()
+		close(ticks)
+		ctx = context.Background()
+	})
+
+	JustBeforeEach(func() {
+		runResult = runner.Watch(ctx, logger, []string{"app-name"}, ticks)
+	})
+
+	It("shows a new report on every tick until the ticks stop", func() {
+		Expect(runResult.IsFailure).To(BeFalse())
+
+		Expect(instanceReporter.CreateApplicationReportsCallCount()).To(Equal(2))
+		_, _, actualAppNames := instanceReporter.CreateApplicationReportsArgsForCall(1)
+		Expect(actualAppNames).To(Equal([]string{"app-name"}))
+
+		Expect(outputRenderer.ShowApplicationReportsUpdateCallCount()).To(Equal(2))
+		_, previousReports, currentReports := outputRenderer.ShowApplicationReportsUpdateArgsForCall(0)
+		Expect(previousReports).To(BeEmpty())
+		Expect(currentReports).To(Equal([]reporter.ApplicationReport{applicationReport}))
+
+		_, previousReports, currentReports = outputRenderer.ShowApplicationReportsUpdateArgsForCall(1)
+		Expect(previousReports).To(Equal([]reporter.ApplicationReport{applicationReport}))
+		Expect(currentReports).To(Equal([]reporter.ApplicationReport{updatedReport}))
+	})
+
+	It("logs start and end of function", func() {
+		Expect(logger).To(gbytes.Say("watch.start"))
+		Expect(logger).To(gbytes.Say("watch.end"))
+	})
+
+	When("creating a report fails", func() {
+		BeforeEach(func() {
+			instanceReporter.CreateApplicationReportsReturnsOnCall(1, nil, errors.New("reports error"))
+		})
+
+		It("stops and returns a failure", func() {
+			Expect(runResult.IsFailure).To(BeTrue())
+			Expect(runResult.ErrorMessage).To(Equal("reports error"))
+			Expect(outputRenderer.ShowApplicationReportsUpdateCallCount()).To(Equal(1))
+		})
+	})
+
+	When("rendering a report fails", func() {
+		BeforeEach(func() {
+			outputRenderer.ShowApplicationReportsUpdateReturns(errors.New("render error"))
+		})
+
+		It("stops and returns a failure", func() {
+			Expect(runResult.IsFailure).To(BeTrue())
+			Expect(runResult.ErrorMessage).To(Equal("render error"))
+			Expect(instanceReporter.CreateApplicationReportsCallCount()).To(Equal(1))
+		})
+	})
+
+	When("the runner has a reporter factory", func() {
+		var (
+			tickTime      time.Time
+			reporterTimes []time.Time
+		)
+
+		BeforeEach(func() {
+			tickTime = time.Date(2019, 7, 30, 10, 0, 0, 0, time.UTC)
+			ticks = make(chan time.Time, 1)
+			ticks <- tickTime
+			close(ticks)
+
+			reporterTimes = nil
+			runner = runner.WithReporterFactory(func(now time.Time) plugins.Reporter {
+				reporterTimes = append(reporterTimes, now)
+				return instanceReporter
+			})
+		})
+
+		It("creates a reporter at the time of every tick, so that its windows move forward", func() {
+			Expect(runResult.IsFailure).To(BeFalse())
+			Expect(reporterTimes).To(HaveLen(2))
+			Expect(reporterTimes[0]).To(BeTemporally("~", time.Now(), time.Minute))
+			Expect(reporterTimes[1]).To(Equal(tickTime))
+			Expect(instanceReporter.CreateApplicationReportsCallCount()).To(Equal(2))
+		})
+	})
+
+	When("the context is cancelled while waiting for a tick", func() {
+		BeforeEach(func() {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithCancel(context.Background())
+			ticks = make(chan time.Time)
+			outputRenderer.ShowApplicationReportsUpdateStub = func(lager.Logger, []reporter.ApplicationReport, []reporter.ApplicationReport) error {
+				cancel()
+				return nil
+			}
+		})
+
+		It("stops without a failure", func() {
+			Expect(runResult.IsFailure).To(BeFalse())
+			Expect(instanceReporter.CreateApplicationReportsCallCount()).To(Equal(1))
+		})
+	})
+
+	When("the context is cancelled while creating a report", func() {
+		BeforeEach(func() {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithCancel(context.Background())
+			instanceReporter.CreateApplicationReportsStub = func(context.Context, lager.Logger, []string) ([]reporter.ApplicationReport, error) {
+				cancel()
+				return nil, context.Canceled
+			}
+		})
+
+		It("stops without a failure or rendering", func() {
+			Expect(runResult.IsFailure).To(BeFalse())
+			Expect(outputRenderer.ShowApplicationReportsUpdateCallCount()).To(BeZero())
+		})
+	})
+})
+
+var _ = Describe("App Runner for spaces", func() {
+	var (
+		instanceReporter *pluginsfakes.FakeReporter
+		outputRenderer   *pluginsfakes.FakeOutputRenderer
+
+		runner      plugins.AppRunner
+		runResult   result.Result
+		spaceReport reporter.SpaceUsageReport
+		logger      *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		instanceReporter = new(pluginsfakes.FakeReporter)
+		outputRenderer = new(pluginsfakes.FakeOutputRenderer)
+		logger = lagertest.NewTestLogger("app-runner-test")
+		runner = plugins.NewAppRunner(instanceReporter, outputRenderer)
+
+		spaceReport = reporter.SpaceUsageReport{
+			Space: "the-space",
+			Apps:  []reporter.AppUsageReport{{ApplicationName: "app-1"}},
+		}
+		instanceReporter.CreateSpaceReportReturns(spaceReport, nil)
+	})
+
+	JustBeforeEach(func() {
+		runResult = runner.RunSpace(context.Background(), logger)
+	})
+
+	It("prints the summary of the space", func() {
+		Expect(runResult.IsFailure).To(BeFalse())
+
+		Expect(instanceReporter.CreateSpaceReportCallCount()).To(Equal(1))
+		Expect(outputRenderer.ShowSpaceReportCallCount()).To(Equal(1))
+		_, actualSpaceReport := outputRenderer.ShowSpaceReportArgsForCall(0)
+		Expect(actualSpaceReport).To(Equal(spaceReport))
+	})
+
+	It("logs start and end of function", func() {
+		Expect(logger).To(gbytes.Say("run-space.start"))
+		Expect(logger).To(gbytes.Say("run-space.end"))
+	})
+
+	When("creating the report fails", func() {
+		BeforeEach(func() {
+			instanceReporter.CreateSpaceReportReturns(reporter.SpaceUsageReport{}, errors.New("report error"))
+		})
+
+		It("returns a failure", func() {
+			Expect(runResult.IsFailure).To(BeTrue())
+			Expect(runResult.ErrorMessage).To(Equal("report error"))
+		})
+	})
+
+	When("rendering the report fails", func() {
+		BeforeEach(func() {
+			outputRenderer.ShowSpaceReportReturns(errors.New("render error"))
 		})
 
 		It("returns a failure", func() {
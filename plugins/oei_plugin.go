@@ -1,8 +1,12 @@
 package plugins
 
 import (
+	"context"
+	"errors"
 	"io/ioutil"
 	"os"
+	"os/signal"
+	"time"
 
 	"code.cloudfoundry.org/cli/cf/terminal"
 	"code.cloudfoundry.org/cli/cf/trace"
@@ -15,6 +19,10 @@ import (
 	flags "github.com/jessevdk/go-flags"
 )
 
+// oeiBatchSize is how many apps the usage of is fetched with a single
+// log-cache query. It keeps the query URL well within log-cache limits.
+const oeiBatchSize = 50
+
 type CPUEntitlementAdminPlugin struct{}
 
 func NewOverEntitlementInstancesPlugin() CPUEntitlementAdminPlugin {
@@ -26,7 +34,19 @@ func (p CPUEntitlementAdminPlugin) Run(cli plugin.CliConnection, args []string)
 	ui := terminal.NewUI(os.Stdin, os.Stdout, terminal.NewTeePrinter(os.Stdout), traceLogger)
 
 	opts := struct {
-		Debug bool `short:"d" long:"debug" description:"Show verbose debug information"`
+		Debug         bool          `short:"d" long:"debug" description:"Show verbose debug information"`
+		Output        string        `short:"o" long:"output" choice:"table" choice:"json" choice:"csv" default:"table" description:"Output format"`
+		AllOrgs       bool          `long:"all-orgs" description:"Report on every org you can see instead of the targeted org"`
+		Spaces        []string      `short:"s" long:"space" description:"Only report on this space (can be repeated)"`
+		SpacePatterns []string      `long:"space-pattern" description:"Only report on the spaces matching this glob pattern (can be repeated)"`
+		Concurrency   int           `long:"concurrency" default:"10" description:"Number of metrics requests to make at the same time"`
+		Timeout       time.Duration `long:"timeout" default:"30s" description:"Give up on any request to log-cache or the Cloud Controller that takes longer than this"`
+		CACerts       []string      `long:"ca-cert" description:"Also trust the certificate authorities in this PEM file (can be repeated)"`
+		Retries       int           `long:"retries" default:"3" description:"Number of times to retry a request to log-cache that fails with a transient error"`
+		Throttle      bool          `long:"throttle-preview" description:"Also list the apps that would have been deprioritized under a rolling-window policy"`
+		Window        time.Duration `long:"window" default:"1h" description:"Rolling window of the throttle preview"`
+		Step          time.Duration `long:"step" default:"1m" description:"Time between samples of the usage history of the throttle preview"`
+		ThresholdOpts
 	}{}
 
 	args, err := flags.ParseArgs(&opts, args)
@@ -49,27 +69,105 @@ func (p CPUEntitlementAdminPlugin) Run(cli plugin.CliConnection, args []string)
 		os.Exit(0)
 	}
 
-	logCacheURL, err := getLogCacheURL(cli)
+	thresholds, err := opts.Thresholds()
+	if err != nil {
+		ui.Failed(err.Error())
+		os.Exit(1)
+	}
+
+	if opts.Concurrency < 1 {
+		ui.Failed("The concurrency must be at least 1.")
+		os.Exit(1)
+	}
+
+	if opts.Timeout <= 0 {
+		ui.Failed("The timeout must be positive.")
+		os.Exit(1)
+	}
+
+	if opts.Retries < 0 {
+		ui.Failed("The number of retries cannot be negative.")
+		os.Exit(1)
+	}
+
+	if opts.Throttle && opts.Window <= 0 {
+		ui.Failed("The throttle preview window must be positive.")
+		os.Exit(1)
+	}
+
+	if opts.Throttle && opts.Step < time.Second {
+		ui.Failed("The step must be at least 1s.")
+		os.Exit(1)
+	}
+
+	spaceFilter := cf.SpaceFilter{Names: opts.Spaces, Patterns: opts.SpacePatterns}
+	if err = spaceFilter.Validate(); err != nil {
+		ui.Failed(err.Error())
+		os.Exit(1)
+	}
+
+	apiURL, err := getAPIURL(cli)
 	if err != nil {
 		ui.Failed(err.Error())
 		os.Exit(1)
 	}
 
-	ui.Warn("Note: This feature is experimental.")
+	logCacheURL, err := getLogCacheURL(apiURL)
+	if err != nil {
+		ui.Failed(err.Error())
+		os.Exit(1)
+	}
 
-	sslIsDisabled, err := cli.IsSSLDisabled()
+	if opts.Output == "table" {
+		ui.Warn("Note: This feature is experimental.")
+	}
+
+	authClient, err := createAuthClient(cli, opts.CACerts, opts.Timeout)
 	if err != nil {
 		ui.Failed(err.Error())
 		os.Exit(1)
 	}
 
-	fetcher := fetchers.NewCumulativeUsageFetcher(createLogClient(logCacheURL, cli.AccessToken, sslIsDisabled))
-	cfClient := cf.NewClient(cli, fetchers.NewProcessInstanceIDFetcher(createLogClient(logCacheURL, cli.AccessToken, sslIsDisabled)))
-	reporter := reporter.NewOverEntitlementInstances(cfClient, fetcher)
-	renderer := output.NewOverEntitlementInstancesRenderer(output.NewTerminalDisplay(ui))
-	runner := NewOverEntitlementInstancesRunner(reporter, renderer)
+	logClient := createFetchersLogClient(logger, logCacheURL, authClient, opts.Retries, opts.Timeout)
+	batchFetcher := fetchers.NewBatchCumulativeUsageFetcher(logClient)
+	lastSpikeFetcher := fetchers.NewLastSpikeFetcher(logClient, time.Now().Add(-month))
+	cfClient := createCFClient(cli, apiURL, authClient, fetchers.NewProcessInstanceIDFetcher(logClient)).
+		WithSpaceFilter(spaceFilter).
+		WithConcurrency(opts.Concurrency)
+	oeiReporter := reporter.NewOverEntitlementInstances(cfClient, batchFetcher, oeiBatchSize).
+		WithLastSpikeFetcher(lastSpikeFetcher).
+		WithThresholds(thresholds).
+		WithConcurrency(opts.Concurrency)
+	if opts.Throttle {
+		oeiReporter = oeiReporter.WithThrottlePreview(
+			newThrottleHistoryFetcher(logClient, WindowOrLast(reporter.TimeWindow{}, defaultStatsRange, time.Now()), opts.Window, opts.Step),
+			reporter.ThrottleModel{Window: opts.Window, Threshold: thresholds.Over},
+		)
+	}
+
+	var renderer OverEntitlementInstancesRenderer
+	switch opts.Output {
+	case "json":
+		renderer = output.NewOverEntitlementInstancesJSONRenderer(os.Stdout)
+	case "csv":
+		renderer = output.NewOverEntitlementInstancesCSVRenderer(os.Stdout)
+	default:
+		renderer = output.NewOverEntitlementInstancesRenderer(output.NewTerminalDisplay(ui))
+	}
+	runner := NewOverEntitlementInstancesRunner(oeiReporter, renderer)
 
-	err = runner.Run(logger)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if opts.AllOrgs {
+		err = runner.RunAllOrgs(ctx, logger)
+	} else {
+		err = runner.Run(ctx, logger)
+	}
+	if errors.Is(err, context.Canceled) {
+		ui.Failed("Interrupted.")
+		os.Exit(1)
+	}
 	if err != nil {
 		ui.Failed(err.Error())
 		os.Exit(1)
@@ -90,7 +188,22 @@ func (p CPUEntitlementAdminPlugin) GetMetadata() plugin.PluginMetadata {
 				Alias:    "oei",
 				HelpText: "See which instances are over entitlement",
 				UsageDetails: plugin.Usage{
-					Usage: "cf over-entitlement-instances",
+					Usage: "cf over-entitlement-instances [--output table|json|csv] [--all-orgs] [--space SPACE]... [--space-pattern PATTERN]... [--concurrency N] [--timeout DURATION] [--retries N] [--ca-cert FILE]... [--throttle-preview [--window DURATION] [--step DURATION]] [--threshold PERCENT] [--near-threshold PERCENT]",
+					Options: map[string]string{
+						"output, -o":       "Output format: table (default), json or csv",
+						"all-orgs":         "Report on every org you can see instead of the targeted org",
+						"space, -s":        "Only report on this space; can be repeated",
+						"space-pattern":    "Only report on the spaces matching this glob pattern, e.g. 'team-a-*'; can be repeated",
+						"concurrency":      "Number of metrics requests to make at the same time (default 10)",
+						"timeout":          "Give up on any request to log-cache or the Cloud Controller that takes longer than this (default 30s)",
+						"ca-cert":          "Also trust the certificate authorities in this PEM file, on top of those of the system and of SSL_CERT_FILE (can be repeated)",
+						"retries":          "Number of times to retry a request to log-cache that fails with a 429, 502, 503 or 504 status or a network error (default 3)",
+						"throttle-preview": "Also list the apps with an instance that would have been deprioritized in the last 24 hours if its average usage over a rolling window were limited to the threshold",
+						"window":           "Rolling window of the throttle preview (default 1h)",
+						"step":             "Time between samples of the usage history of the throttle preview (default 1m)",
+						"threshold":        thresholdUsage,
+						"near-threshold":   nearThresholdUsage,
+					},
 				},
 			},
 		},
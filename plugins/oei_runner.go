@@ -1,6 +1,8 @@
 package plugins
 
 import (
+	"context"
+
 	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
 	"code.cloudfoundry.org/lager"
 )
@@ -8,13 +10,15 @@ import (
 //go:generate counterfeiter . OverEntitlementInstancesReporter
 
 type OverEntitlementInstancesReporter interface {
-	OverEntitlementInstances(logger lager.Logger) (reporter.OEIReport, error)
+	OverEntitlementInstances(ctx context.Context, logger lager.Logger) (reporter.OEIReport, error)
+	OverEntitlementInstancesInAllOrgs(ctx context.Context, logger lager.Logger) (reporter.AllOrgsOEIReport, error)
 }
 
 //go:generate counterfeiter . OverEntitlementInstancesRenderer
 
 type OverEntitlementInstancesRenderer interface {
 	Render(lager.Logger, reporter.OEIReport) error
+	RenderAllOrgs(lager.Logger, reporter.AllOrgsOEIReport) error
 }
 
 type OverEntitlementInstancesRunner struct {
@@ -29,12 +33,12 @@ func NewOverEntitlementInstancesRunner(oeiReporter OverEntitlementInstancesRepor
 	}
 }
 
-func (r *OverEntitlementInstancesRunner) Run(logger lager.Logger) error {
+func (r *OverEntitlementInstancesRunner) Run(ctx context.Context, logger lager.Logger) error {
 	logger = logger.Session("run")
 	logger.Info("start")
 	defer logger.Info("end")
 
-	report, err := r.reporter.OverEntitlementInstances(logger)
+	report, err := r.reporter.OverEntitlementInstances(ctx, logger)
 	if err != nil {
 		return err
 	}
@@ -46,3 +50,16 @@ func (r *OverEntitlementInstancesRunner) Run(logger lager.Logger) error {
 
 	return nil
 }
+
+func (r *OverEntitlementInstancesRunner) RunAllOrgs(ctx context.Context, logger lager.Logger) error {
+	logger = logger.Session("run-all-orgs")
+	logger.Info("start")
+	defer logger.Info("end")
+
+	report, err := r.reporter.OverEntitlementInstancesInAllOrgs(ctx, logger)
+	if err != nil {
+		return err
+	}
+
+	return r.renderer.RenderAllOrgs(logger, report)
+}
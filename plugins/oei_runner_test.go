@@ -1,6 +1,7 @@
 package plugins_test
 
 import (
+	"context"
 	"errors"
 
 	"code.cloudfoundry.org/cpu-entitlement-plugin/plugins"
@@ -22,6 +23,7 @@ var _ = Describe("Runner", func() {
 		err    error
 		report reporter.OEIReport
 		logger lager.Logger
+		ctx    context.Context
 	)
 
 	BeforeEach(func() {
@@ -32,14 +34,14 @@ var _ = Describe("Runner", func() {
 			SpaceReports: []reporter.SpaceReport{
 				{
 					SpaceName: "space-1",
-					Apps: []string{
-						"app-1",
-						"app-2",
+					Apps: []reporter.OEIAppReport{
+						{Name: "app-1"},
+						{Name: "app-2"},
 					},
 				}, {
 					SpaceName: "space-2",
-					Apps: []string{
-						"app-1",
+					Apps: []reporter.OEIAppReport{
+						{Name: "app-1"},
 					},
 				},
 			},
@@ -49,14 +51,17 @@ var _ = Describe("Runner", func() {
 
 		runner = plugins.NewOverEntitlementInstancesRunner(fakeReporter, fakeRenderer)
 		logger = lagertest.NewTestLogger("test-oei")
+		ctx = context.WithValue(context.Background(), "test-key", "test-value")
 	})
 
 	JustBeforeEach(func() {
-		err = runner.Run(logger)
+		err = runner.Run(ctx, logger)
 	})
 
 	It("collects reports and renders them", func() {
 		Expect(err).NotTo(HaveOccurred())
+		actualCtx, _ := fakeReporter.OverEntitlementInstancesArgsForCall(0)
+		Expect(actualCtx).To(Equal(ctx))
 		Expect(fakeRenderer.RenderCallCount()).To(Equal(1))
 		_, actualReport := fakeRenderer.RenderArgsForCall(0)
 		Expect(actualReport).To(Equal(report))
@@ -87,3 +92,74 @@ var _ = Describe("Runner", func() {
 		})
 	})
 })
+
+var _ = Describe("Runner for all orgs", func() {
+	var (
+		fakeReporter *pluginsfakes.FakeOverEntitlementInstancesReporter
+		fakeRenderer *pluginsfakes.FakeOverEntitlementInstancesRenderer
+
+		runner *plugins.OverEntitlementInstancesRunner
+		err    error
+		report reporter.AllOrgsOEIReport
+		logger lager.Logger
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		fakeReporter = new(pluginsfakes.FakeOverEntitlementInstancesReporter)
+		fakeRenderer = new(pluginsfakes.FakeOverEntitlementInstancesRenderer)
+
+		report = reporter.AllOrgsOEIReport{
+			Username: "user",
+			OrgReports: []reporter.OEIReport{
+				{Org: "org-1", SpaceReports: []reporter.SpaceReport{{SpaceName: "space-1", Apps: []reporter.OEIAppReport{{Name: "app-1"}}}}},
+				{Org: "org-2", SpaceReports: []reporter.SpaceReport{{SpaceName: "space-1", Apps: []reporter.OEIAppReport{{Name: "app-1"}}}}},
+			},
+		}
+
+		fakeReporter.OverEntitlementInstancesInAllOrgsReturns(report, nil)
+
+		runner = plugins.NewOverEntitlementInstancesRunner(fakeReporter, fakeRenderer)
+		logger = lagertest.NewTestLogger("test-oei")
+		ctx = context.WithValue(context.Background(), "test-key", "test-value")
+	})
+
+	JustBeforeEach(func() {
+		err = runner.RunAllOrgs(ctx, logger)
+	})
+
+	It("collects the report of every org and renders it", func() {
+		Expect(err).NotTo(HaveOccurred())
+		actualCtx, _ := fakeReporter.OverEntitlementInstancesInAllOrgsArgsForCall(0)
+		Expect(actualCtx).To(Equal(ctx))
+		Expect(fakeReporter.OverEntitlementInstancesCallCount()).To(Equal(0))
+		Expect(fakeRenderer.RenderAllOrgsCallCount()).To(Equal(1))
+		_, actualReport := fakeRenderer.RenderAllOrgsArgsForCall(0)
+		Expect(actualReport).To(Equal(report))
+	})
+
+	It("logs start and end of function", func() {
+		Expect(logger).To(gbytes.Say("run-all-orgs.start"))
+		Expect(logger).To(gbytes.Say("run-all-orgs.end"))
+	})
+
+	When("the reporter fails", func() {
+		BeforeEach(func() {
+			fakeReporter.OverEntitlementInstancesInAllOrgsReturns(reporter.AllOrgsOEIReport{}, errors.New("reporter-err"))
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError("reporter-err"))
+		})
+	})
+
+	When("the renderer fails", func() {
+		BeforeEach(func() {
+			fakeRenderer.RenderAllOrgsReturns(errors.New("renderer-err"))
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError("renderer-err"))
+		})
+	})
+})
@@ -10,79 +10,245 @@ import (
 )
 
 type FakeOutputRenderer struct {
-	ShowApplicationReportStub        func(lager.Logger, reporter.ApplicationReport) error
-	showApplicationReportMutex       sync.RWMutex
-	showApplicationReportArgsForCall []struct {
+	ShowApplicationReportsStub        func(lager.Logger, []reporter.ApplicationReport) error
+	showApplicationReportsMutex       sync.RWMutex
+	showApplicationReportsArgsForCall []struct {
 		arg1 lager.Logger
-		arg2 reporter.ApplicationReport
+		arg2 []reporter.ApplicationReport
 	}
-	showApplicationReportReturns struct {
+	showApplicationReportsReturns struct {
 		result1 error
 	}
-	showApplicationReportReturnsOnCall map[int]struct {
+	showApplicationReportsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ShowApplicationReportsUpdateStub        func(lager.Logger, []reporter.ApplicationReport, []reporter.ApplicationReport) error
+	showApplicationReportsUpdateMutex       sync.RWMutex
+	showApplicationReportsUpdateArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 []reporter.ApplicationReport
+		arg3 []reporter.ApplicationReport
+	}
+	showApplicationReportsUpdateReturns struct {
+		result1 error
+	}
+	showApplicationReportsUpdateReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ShowSpaceReportStub        func(lager.Logger, reporter.SpaceUsageReport) error
+	showSpaceReportMutex       sync.RWMutex
+	showSpaceReportArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 reporter.SpaceUsageReport
+	}
+	showSpaceReportReturns struct {
+		result1 error
+	}
+	showSpaceReportReturnsOnCall map[int]struct {
 		result1 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeOutputRenderer) ShowApplicationReport(arg1 lager.Logger, arg2 reporter.ApplicationReport) error {
-	fake.showApplicationReportMutex.Lock()
-	ret, specificReturn := fake.showApplicationReportReturnsOnCall[len(fake.showApplicationReportArgsForCall)]
-	fake.showApplicationReportArgsForCall = append(fake.showApplicationReportArgsForCall, struct {
+func (fake *FakeOutputRenderer) ShowApplicationReports(arg1 lager.Logger, arg2 []reporter.ApplicationReport) error {
+	var arg2Copy []reporter.ApplicationReport
+	if arg2 != nil {
+		arg2Copy = make([]reporter.ApplicationReport, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.showApplicationReportsMutex.Lock()
+	ret, specificReturn := fake.showApplicationReportsReturnsOnCall[len(fake.showApplicationReportsArgsForCall)]
+	fake.showApplicationReportsArgsForCall = append(fake.showApplicationReportsArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 []reporter.ApplicationReport
+	}{arg1, arg2Copy})
+	stub := fake.ShowApplicationReportsStub
+	fakeReturns := fake.showApplicationReportsReturns
+	fake.recordInvocation("ShowApplicationReports", []interface{}{arg1, arg2Copy})
+	fake.showApplicationReportsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeOutputRenderer) ShowApplicationReportsCallCount() int {
+	fake.showApplicationReportsMutex.RLock()
+	defer fake.showApplicationReportsMutex.RUnlock()
+	return len(fake.showApplicationReportsArgsForCall)
+}
+
+func (fake *FakeOutputRenderer) ShowApplicationReportsCalls(stub func(lager.Logger, []reporter.ApplicationReport) error) {
+	fake.showApplicationReportsMutex.Lock()
+	defer fake.showApplicationReportsMutex.Unlock()
+	fake.ShowApplicationReportsStub = stub
+}
+
+func (fake *FakeOutputRenderer) ShowApplicationReportsArgsForCall(i int) (lager.Logger, []reporter.ApplicationReport) {
+	fake.showApplicationReportsMutex.RLock()
+	defer fake.showApplicationReportsMutex.RUnlock()
+	argsForCall := fake.showApplicationReportsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeOutputRenderer) ShowApplicationReportsReturns(result1 error) {
+	fake.showApplicationReportsMutex.Lock()
+	defer fake.showApplicationReportsMutex.Unlock()
+	fake.ShowApplicationReportsStub = nil
+	fake.showApplicationReportsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOutputRenderer) ShowApplicationReportsReturnsOnCall(i int, result1 error) {
+	fake.showApplicationReportsMutex.Lock()
+	defer fake.showApplicationReportsMutex.Unlock()
+	fake.ShowApplicationReportsStub = nil
+	if fake.showApplicationReportsReturnsOnCall == nil {
+		fake.showApplicationReportsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.showApplicationReportsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOutputRenderer) ShowApplicationReportsUpdate(arg1 lager.Logger, arg2 []reporter.ApplicationReport, arg3 []reporter.ApplicationReport) error {
+	var arg2Copy []reporter.ApplicationReport
+	if arg2 != nil {
+		arg2Copy = make([]reporter.ApplicationReport, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	var arg3Copy []reporter.ApplicationReport
+	if arg3 != nil {
+		arg3Copy = make([]reporter.ApplicationReport, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.showApplicationReportsUpdateMutex.Lock()
+	ret, specificReturn := fake.showApplicationReportsUpdateReturnsOnCall[len(fake.showApplicationReportsUpdateArgsForCall)]
+	fake.showApplicationReportsUpdateArgsForCall = append(fake.showApplicationReportsUpdateArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 []reporter.ApplicationReport
+		arg3 []reporter.ApplicationReport
+	}{arg1, arg2Copy, arg3Copy})
+	stub := fake.ShowApplicationReportsUpdateStub
+	fakeReturns := fake.showApplicationReportsUpdateReturns
+	fake.recordInvocation("ShowApplicationReportsUpdate", []interface{}{arg1, arg2Copy, arg3Copy})
+	fake.showApplicationReportsUpdateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeOutputRenderer) ShowApplicationReportsUpdateCallCount() int {
+	fake.showApplicationReportsUpdateMutex.RLock()
+	defer fake.showApplicationReportsUpdateMutex.RUnlock()
+	return len(fake.showApplicationReportsUpdateArgsForCall)
+}
+
+func (fake *FakeOutputRenderer) ShowApplicationReportsUpdateCalls(stub func(lager.Logger, []reporter.ApplicationReport, []reporter.ApplicationReport) error) {
+	fake.showApplicationReportsUpdateMutex.Lock()
+	defer fake.showApplicationReportsUpdateMutex.Unlock()
+	fake.ShowApplicationReportsUpdateStub = stub
+}
+
+func (fake *FakeOutputRenderer) ShowApplicationReportsUpdateArgsForCall(i int) (lager.Logger, []reporter.ApplicationReport, []reporter.ApplicationReport) {
+	fake.showApplicationReportsUpdateMutex.RLock()
+	defer fake.showApplicationReportsUpdateMutex.RUnlock()
+	argsForCall := fake.showApplicationReportsUpdateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeOutputRenderer) ShowApplicationReportsUpdateReturns(result1 error) {
+	fake.showApplicationReportsUpdateMutex.Lock()
+	defer fake.showApplicationReportsUpdateMutex.Unlock()
+	fake.ShowApplicationReportsUpdateStub = nil
+	fake.showApplicationReportsUpdateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOutputRenderer) ShowApplicationReportsUpdateReturnsOnCall(i int, result1 error) {
+	fake.showApplicationReportsUpdateMutex.Lock()
+	defer fake.showApplicationReportsUpdateMutex.Unlock()
+	fake.ShowApplicationReportsUpdateStub = nil
+	if fake.showApplicationReportsUpdateReturnsOnCall == nil {
+		fake.showApplicationReportsUpdateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.showApplicationReportsUpdateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOutputRenderer) ShowSpaceReport(arg1 lager.Logger, arg2 reporter.SpaceUsageReport) error {
+	fake.showSpaceReportMutex.Lock()
+	ret, specificReturn := fake.showSpaceReportReturnsOnCall[len(fake.showSpaceReportArgsForCall)]
+	fake.showSpaceReportArgsForCall = append(fake.showSpaceReportArgsForCall, struct {
 		arg1 lager.Logger
-		arg2 reporter.ApplicationReport
+		arg2 reporter.SpaceUsageReport
 	}{arg1, arg2})
-	fake.recordInvocation("ShowApplicationReport", []interface{}{arg1, arg2})
-	fake.showApplicationReportMutex.Unlock()
-	if fake.ShowApplicationReportStub != nil {
-		return fake.ShowApplicationReportStub(arg1, arg2)
+	stub := fake.ShowSpaceReportStub
+	fakeReturns := fake.showSpaceReportReturns
+	fake.recordInvocation("ShowSpaceReport", []interface{}{arg1, arg2})
+	fake.showSpaceReportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.showApplicationReportReturns
 	return fakeReturns.result1
 }
 
-func (fake *FakeOutputRenderer) ShowApplicationReportCallCount() int {
-	fake.showApplicationReportMutex.RLock()
-	defer fake.showApplicationReportMutex.RUnlock()
-	return len(fake.showApplicationReportArgsForCall)
+func (fake *FakeOutputRenderer) ShowSpaceReportCallCount() int {
+	fake.showSpaceReportMutex.RLock()
+	defer fake.showSpaceReportMutex.RUnlock()
+	return len(fake.showSpaceReportArgsForCall)
 }
 
-func (fake *FakeOutputRenderer) ShowApplicationReportCalls(stub func(lager.Logger, reporter.ApplicationReport) error) {
-	fake.showApplicationReportMutex.Lock()
-	defer fake.showApplicationReportMutex.Unlock()
-	fake.ShowApplicationReportStub = stub
+func (fake *FakeOutputRenderer) ShowSpaceReportCalls(stub func(lager.Logger, reporter.SpaceUsageReport) error) {
+	fake.showSpaceReportMutex.Lock()
+	defer fake.showSpaceReportMutex.Unlock()
+	fake.ShowSpaceReportStub = stub
 }
 
-func (fake *FakeOutputRenderer) ShowApplicationReportArgsForCall(i int) (lager.Logger, reporter.ApplicationReport) {
-	fake.showApplicationReportMutex.RLock()
-	defer fake.showApplicationReportMutex.RUnlock()
-	argsForCall := fake.showApplicationReportArgsForCall[i]
+func (fake *FakeOutputRenderer) ShowSpaceReportArgsForCall(i int) (lager.Logger, reporter.SpaceUsageReport) {
+	fake.showSpaceReportMutex.RLock()
+	defer fake.showSpaceReportMutex.RUnlock()
+	argsForCall := fake.showSpaceReportArgsForCall[i]
 	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeOutputRenderer) ShowApplicationReportReturns(result1 error) {
-	fake.showApplicationReportMutex.Lock()
-	defer fake.showApplicationReportMutex.Unlock()
-	fake.ShowApplicationReportStub = nil
-	fake.showApplicationReportReturns = struct {
+func (fake *FakeOutputRenderer) ShowSpaceReportReturns(result1 error) {
+	fake.showSpaceReportMutex.Lock()
+	defer fake.showSpaceReportMutex.Unlock()
+	fake.ShowSpaceReportStub = nil
+	fake.showSpaceReportReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeOutputRenderer) ShowApplicationReportReturnsOnCall(i int, result1 error) {
-	fake.showApplicationReportMutex.Lock()
-	defer fake.showApplicationReportMutex.Unlock()
-	fake.ShowApplicationReportStub = nil
-	if fake.showApplicationReportReturnsOnCall == nil {
-		fake.showApplicationReportReturnsOnCall = make(map[int]struct {
+func (fake *FakeOutputRenderer) ShowSpaceReportReturnsOnCall(i int, result1 error) {
+	fake.showSpaceReportMutex.Lock()
+	defer fake.showSpaceReportMutex.Unlock()
+	fake.ShowSpaceReportStub = nil
+	if fake.showSpaceReportReturnsOnCall == nil {
+		fake.showSpaceReportReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.showApplicationReportReturnsOnCall[i] = struct {
+	fake.showSpaceReportReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
@@ -90,8 +256,12 @@ func (fake *FakeOutputRenderer) ShowApplicationReportReturnsOnCall(i int, result
 func (fake *FakeOutputRenderer) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.showApplicationReportMutex.RLock()
-	defer fake.showApplicationReportMutex.RUnlock()
+	fake.showApplicationReportsMutex.RLock()
+	defer fake.showApplicationReportsMutex.RUnlock()
+	fake.showApplicationReportsUpdateMutex.RLock()
+	defer fake.showApplicationReportsUpdateMutex.RUnlock()
+	fake.showSpaceReportMutex.RLock()
+	defer fake.showSpaceReportMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -22,6 +22,18 @@ type FakeOverEntitlementInstancesRenderer struct {
 	renderReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RenderAllOrgsStub        func(lager.Logger, reporter.AllOrgsOEIReport) error
+	renderAllOrgsMutex       sync.RWMutex
+	renderAllOrgsArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 reporter.AllOrgsOEIReport
+	}
+	renderAllOrgsReturns struct {
+		result1 error
+	}
+	renderAllOrgsReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -33,15 +45,16 @@ func (fake *FakeOverEntitlementInstancesRenderer) Render(arg1 lager.Logger, arg2
 		arg1 lager.Logger
 		arg2 reporter.OEIReport
 	}{arg1, arg2})
+	stub := fake.RenderStub
+	fakeReturns := fake.renderReturns
 	fake.recordInvocation("Render", []interface{}{arg1, arg2})
 	fake.renderMutex.Unlock()
-	if fake.RenderStub != nil {
-		return fake.RenderStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.renderReturns
 	return fakeReturns.result1
 }
 
@@ -87,11 +100,75 @@ func (fake *FakeOverEntitlementInstancesRenderer) RenderReturnsOnCall(i int, res
 	}{result1}
 }
 
+func (fake *FakeOverEntitlementInstancesRenderer) RenderAllOrgs(arg1 lager.Logger, arg2 reporter.AllOrgsOEIReport) error {
+	fake.renderAllOrgsMutex.Lock()
+	ret, specificReturn := fake.renderAllOrgsReturnsOnCall[len(fake.renderAllOrgsArgsForCall)]
+	fake.renderAllOrgsArgsForCall = append(fake.renderAllOrgsArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 reporter.AllOrgsOEIReport
+	}{arg1, arg2})
+	stub := fake.RenderAllOrgsStub
+	fakeReturns := fake.renderAllOrgsReturns
+	fake.recordInvocation("RenderAllOrgs", []interface{}{arg1, arg2})
+	fake.renderAllOrgsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeOverEntitlementInstancesRenderer) RenderAllOrgsCallCount() int {
+	fake.renderAllOrgsMutex.RLock()
+	defer fake.renderAllOrgsMutex.RUnlock()
+	return len(fake.renderAllOrgsArgsForCall)
+}
+
+func (fake *FakeOverEntitlementInstancesRenderer) RenderAllOrgsCalls(stub func(lager.Logger, reporter.AllOrgsOEIReport) error) {
+	fake.renderAllOrgsMutex.Lock()
+	defer fake.renderAllOrgsMutex.Unlock()
+	fake.RenderAllOrgsStub = stub
+}
+
+func (fake *FakeOverEntitlementInstancesRenderer) RenderAllOrgsArgsForCall(i int) (lager.Logger, reporter.AllOrgsOEIReport) {
+	fake.renderAllOrgsMutex.RLock()
+	defer fake.renderAllOrgsMutex.RUnlock()
+	argsForCall := fake.renderAllOrgsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeOverEntitlementInstancesRenderer) RenderAllOrgsReturns(result1 error) {
+	fake.renderAllOrgsMutex.Lock()
+	defer fake.renderAllOrgsMutex.Unlock()
+	fake.RenderAllOrgsStub = nil
+	fake.renderAllOrgsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOverEntitlementInstancesRenderer) RenderAllOrgsReturnsOnCall(i int, result1 error) {
+	fake.renderAllOrgsMutex.Lock()
+	defer fake.renderAllOrgsMutex.Unlock()
+	fake.RenderAllOrgsStub = nil
+	if fake.renderAllOrgsReturnsOnCall == nil {
+		fake.renderAllOrgsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.renderAllOrgsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeOverEntitlementInstancesRenderer) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.renderMutex.RLock()
 	defer fake.renderMutex.RUnlock()
+	fake.renderAllOrgsMutex.RLock()
+	defer fake.renderAllOrgsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -2,6 +2,7 @@
 package pluginsfakes
 
 import (
+	"context"
 	"sync"
 
 	"code.cloudfoundry.org/cpu-entitlement-plugin/plugins"
@@ -10,10 +11,11 @@ import (
 )
 
 type FakeOverEntitlementInstancesReporter struct {
-	OverEntitlementInstancesStub        func(lager.Logger) (reporter.OEIReport, error)
+	OverEntitlementInstancesStub        func(context.Context, lager.Logger) (reporter.OEIReport, error)
 	overEntitlementInstancesMutex       sync.RWMutex
 	overEntitlementInstancesArgsForCall []struct {
-		arg1 lager.Logger
+		arg1 context.Context
+		arg2 lager.Logger
 	}
 	overEntitlementInstancesReturns struct {
 		result1 reporter.OEIReport
@@ -23,25 +25,41 @@ type FakeOverEntitlementInstancesReporter struct {
 		result1 reporter.OEIReport
 		result2 error
 	}
+	OverEntitlementInstancesInAllOrgsStub        func(context.Context, lager.Logger) (reporter.AllOrgsOEIReport, error)
+	overEntitlementInstancesInAllOrgsMutex       sync.RWMutex
+	overEntitlementInstancesInAllOrgsArgsForCall []struct {
+		arg1 context.Context
+		arg2 lager.Logger
+	}
+	overEntitlementInstancesInAllOrgsReturns struct {
+		result1 reporter.AllOrgsOEIReport
+		result2 error
+	}
+	overEntitlementInstancesInAllOrgsReturnsOnCall map[int]struct {
+		result1 reporter.AllOrgsOEIReport
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstances(arg1 lager.Logger) (reporter.OEIReport, error) {
+func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstances(arg1 context.Context, arg2 lager.Logger) (reporter.OEIReport, error) {
 	fake.overEntitlementInstancesMutex.Lock()
 	ret, specificReturn := fake.overEntitlementInstancesReturnsOnCall[len(fake.overEntitlementInstancesArgsForCall)]
 	fake.overEntitlementInstancesArgsForCall = append(fake.overEntitlementInstancesArgsForCall, struct {
-		arg1 lager.Logger
-	}{arg1})
-	fake.recordInvocation("OverEntitlementInstances", []interface{}{arg1})
+		arg1 context.Context
+		arg2 lager.Logger
+	}{arg1, arg2})
+	stub := fake.OverEntitlementInstancesStub
+	fakeReturns := fake.overEntitlementInstancesReturns
+	fake.recordInvocation("OverEntitlementInstances", []interface{}{arg1, arg2})
 	fake.overEntitlementInstancesMutex.Unlock()
-	if fake.OverEntitlementInstancesStub != nil {
-		return fake.OverEntitlementInstancesStub(arg1)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.overEntitlementInstancesReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -51,17 +69,17 @@ func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstancesCallCo
 	return len(fake.overEntitlementInstancesArgsForCall)
 }
 
-func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstancesCalls(stub func(lager.Logger) (reporter.OEIReport, error)) {
+func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstancesCalls(stub func(context.Context, lager.Logger) (reporter.OEIReport, error)) {
 	fake.overEntitlementInstancesMutex.Lock()
 	defer fake.overEntitlementInstancesMutex.Unlock()
 	fake.OverEntitlementInstancesStub = stub
 }
 
-func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstancesArgsForCall(i int) lager.Logger {
+func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstancesArgsForCall(i int) (context.Context, lager.Logger) {
 	fake.overEntitlementInstancesMutex.RLock()
 	defer fake.overEntitlementInstancesMutex.RUnlock()
 	argsForCall := fake.overEntitlementInstancesArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstancesReturns(result1 reporter.OEIReport, result2 error) {
@@ -90,11 +108,78 @@ func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstancesReturn
 	}{result1, result2}
 }
 
+func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstancesInAllOrgs(arg1 context.Context, arg2 lager.Logger) (reporter.AllOrgsOEIReport, error) {
+	fake.overEntitlementInstancesInAllOrgsMutex.Lock()
+	ret, specificReturn := fake.overEntitlementInstancesInAllOrgsReturnsOnCall[len(fake.overEntitlementInstancesInAllOrgsArgsForCall)]
+	fake.overEntitlementInstancesInAllOrgsArgsForCall = append(fake.overEntitlementInstancesInAllOrgsArgsForCall, struct {
+		arg1 context.Context
+		arg2 lager.Logger
+	}{arg1, arg2})
+	stub := fake.OverEntitlementInstancesInAllOrgsStub
+	fakeReturns := fake.overEntitlementInstancesInAllOrgsReturns
+	fake.recordInvocation("OverEntitlementInstancesInAllOrgs", []interface{}{arg1, arg2})
+	fake.overEntitlementInstancesInAllOrgsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstancesInAllOrgsCallCount() int {
+	fake.overEntitlementInstancesInAllOrgsMutex.RLock()
+	defer fake.overEntitlementInstancesInAllOrgsMutex.RUnlock()
+	return len(fake.overEntitlementInstancesInAllOrgsArgsForCall)
+}
+
+func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstancesInAllOrgsCalls(stub func(context.Context, lager.Logger) (reporter.AllOrgsOEIReport, error)) {
+	fake.overEntitlementInstancesInAllOrgsMutex.Lock()
+	defer fake.overEntitlementInstancesInAllOrgsMutex.Unlock()
+	fake.OverEntitlementInstancesInAllOrgsStub = stub
+}
+
+func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstancesInAllOrgsArgsForCall(i int) (context.Context, lager.Logger) {
+	fake.overEntitlementInstancesInAllOrgsMutex.RLock()
+	defer fake.overEntitlementInstancesInAllOrgsMutex.RUnlock()
+	argsForCall := fake.overEntitlementInstancesInAllOrgsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstancesInAllOrgsReturns(result1 reporter.AllOrgsOEIReport, result2 error) {
+	fake.overEntitlementInstancesInAllOrgsMutex.Lock()
+	defer fake.overEntitlementInstancesInAllOrgsMutex.Unlock()
+	fake.OverEntitlementInstancesInAllOrgsStub = nil
+	fake.overEntitlementInstancesInAllOrgsReturns = struct {
+		result1 reporter.AllOrgsOEIReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOverEntitlementInstancesReporter) OverEntitlementInstancesInAllOrgsReturnsOnCall(i int, result1 reporter.AllOrgsOEIReport, result2 error) {
+	fake.overEntitlementInstancesInAllOrgsMutex.Lock()
+	defer fake.overEntitlementInstancesInAllOrgsMutex.Unlock()
+	fake.OverEntitlementInstancesInAllOrgsStub = nil
+	if fake.overEntitlementInstancesInAllOrgsReturnsOnCall == nil {
+		fake.overEntitlementInstancesInAllOrgsReturnsOnCall = make(map[int]struct {
+			result1 reporter.AllOrgsOEIReport
+			result2 error
+		})
+	}
+	fake.overEntitlementInstancesInAllOrgsReturnsOnCall[i] = struct {
+		result1 reporter.AllOrgsOEIReport
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeOverEntitlementInstancesReporter) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.overEntitlementInstancesMutex.RLock()
 	defer fake.overEntitlementInstancesMutex.RUnlock()
+	fake.overEntitlementInstancesInAllOrgsMutex.RLock()
+	defer fake.overEntitlementInstancesInAllOrgsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -2,6 +2,7 @@
 package pluginsfakes
 
 import (
+	"context"
 	"sync"
 
 	"code.cloudfoundry.org/cpu-entitlement-plugin/plugins"
@@ -10,84 +11,171 @@ import (
 )
 
 type FakeReporter struct {
-	CreateApplicationReportStub        func(lager.Logger, string) (reporter.ApplicationReport, error)
-	createApplicationReportMutex       sync.RWMutex
-	createApplicationReportArgsForCall []struct {
-		arg1 lager.Logger
-		arg2 string
-	}
-	createApplicationReportReturns struct {
-		result1 reporter.ApplicationReport
+	CreateApplicationReportsStub        func(context.Context, lager.Logger, []string) ([]reporter.ApplicationReport, error)
+	createApplicationReportsMutex       sync.RWMutex
+	createApplicationReportsArgsForCall []struct {
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 []string
+	}
+	createApplicationReportsReturns struct {
+		result1 []reporter.ApplicationReport
+		result2 error
+	}
+	createApplicationReportsReturnsOnCall map[int]struct {
+		result1 []reporter.ApplicationReport
+		result2 error
+	}
+	CreateSpaceReportStub        func(context.Context, lager.Logger) (reporter.SpaceUsageReport, error)
+	createSpaceReportMutex       sync.RWMutex
+	createSpaceReportArgsForCall []struct {
+		arg1 context.Context
+		arg2 lager.Logger
+	}
+	createSpaceReportReturns struct {
+		result1 reporter.SpaceUsageReport
 		result2 error
 	}
-	createApplicationReportReturnsOnCall map[int]struct {
-		result1 reporter.ApplicationReport
+	createSpaceReportReturnsOnCall map[int]struct {
+		result1 reporter.SpaceUsageReport
 		result2 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeReporter) CreateApplicationReport(arg1 lager.Logger, arg2 string) (reporter.ApplicationReport, error) {
-	fake.createApplicationReportMutex.Lock()
-	ret, specificReturn := fake.createApplicationReportReturnsOnCall[len(fake.createApplicationReportArgsForCall)]
-	fake.createApplicationReportArgsForCall = append(fake.createApplicationReportArgsForCall, struct {
-		arg1 lager.Logger
-		arg2 string
+func (fake *FakeReporter) CreateApplicationReports(arg1 context.Context, arg2 lager.Logger, arg3 []string) ([]reporter.ApplicationReport, error) {
+	var arg3Copy []string
+	if arg3 != nil {
+		arg3Copy = make([]string, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.createApplicationReportsMutex.Lock()
+	ret, specificReturn := fake.createApplicationReportsReturnsOnCall[len(fake.createApplicationReportsArgsForCall)]
+	fake.createApplicationReportsArgsForCall = append(fake.createApplicationReportsArgsForCall, struct {
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 []string
+	}{arg1, arg2, arg3Copy})
+	stub := fake.CreateApplicationReportsStub
+	fakeReturns := fake.createApplicationReportsReturns
+	fake.recordInvocation("CreateApplicationReports", []interface{}{arg1, arg2, arg3Copy})
+	fake.createApplicationReportsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeReporter) CreateApplicationReportsCallCount() int {
+	fake.createApplicationReportsMutex.RLock()
+	defer fake.createApplicationReportsMutex.RUnlock()
+	return len(fake.createApplicationReportsArgsForCall)
+}
+
+func (fake *FakeReporter) CreateApplicationReportsCalls(stub func(context.Context, lager.Logger, []string) ([]reporter.ApplicationReport, error)) {
+	fake.createApplicationReportsMutex.Lock()
+	defer fake.createApplicationReportsMutex.Unlock()
+	fake.CreateApplicationReportsStub = stub
+}
+
+func (fake *FakeReporter) CreateApplicationReportsArgsForCall(i int) (context.Context, lager.Logger, []string) {
+	fake.createApplicationReportsMutex.RLock()
+	defer fake.createApplicationReportsMutex.RUnlock()
+	argsForCall := fake.createApplicationReportsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeReporter) CreateApplicationReportsReturns(result1 []reporter.ApplicationReport, result2 error) {
+	fake.createApplicationReportsMutex.Lock()
+	defer fake.createApplicationReportsMutex.Unlock()
+	fake.CreateApplicationReportsStub = nil
+	fake.createApplicationReportsReturns = struct {
+		result1 []reporter.ApplicationReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeReporter) CreateApplicationReportsReturnsOnCall(i int, result1 []reporter.ApplicationReport, result2 error) {
+	fake.createApplicationReportsMutex.Lock()
+	defer fake.createApplicationReportsMutex.Unlock()
+	fake.CreateApplicationReportsStub = nil
+	if fake.createApplicationReportsReturnsOnCall == nil {
+		fake.createApplicationReportsReturnsOnCall = make(map[int]struct {
+			result1 []reporter.ApplicationReport
+			result2 error
+		})
+	}
+	fake.createApplicationReportsReturnsOnCall[i] = struct {
+		result1 []reporter.ApplicationReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeReporter) CreateSpaceReport(arg1 context.Context, arg2 lager.Logger) (reporter.SpaceUsageReport, error) {
+	fake.createSpaceReportMutex.Lock()
+	ret, specificReturn := fake.createSpaceReportReturnsOnCall[len(fake.createSpaceReportArgsForCall)]
+	fake.createSpaceReportArgsForCall = append(fake.createSpaceReportArgsForCall, struct {
+		arg1 context.Context
+		arg2 lager.Logger
 	}{arg1, arg2})
-	fake.recordInvocation("CreateApplicationReport", []interface{}{arg1, arg2})
-	fake.createApplicationReportMutex.Unlock()
-	if fake.CreateApplicationReportStub != nil {
-		return fake.CreateApplicationReportStub(arg1, arg2)
+	stub := fake.CreateSpaceReportStub
+	fakeReturns := fake.createSpaceReportReturns
+	fake.recordInvocation("CreateSpaceReport", []interface{}{arg1, arg2})
+	fake.createSpaceReportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.createApplicationReportReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeReporter) CreateApplicationReportCallCount() int {
-	fake.createApplicationReportMutex.RLock()
-	defer fake.createApplicationReportMutex.RUnlock()
-	return len(fake.createApplicationReportArgsForCall)
+func (fake *FakeReporter) CreateSpaceReportCallCount() int {
+	fake.createSpaceReportMutex.RLock()
+	defer fake.createSpaceReportMutex.RUnlock()
+	return len(fake.createSpaceReportArgsForCall)
 }
 
-func (fake *FakeReporter) CreateApplicationReportCalls(stub func(lager.Logger, string) (reporter.ApplicationReport, error)) {
-	fake.createApplicationReportMutex.Lock()
-	defer fake.createApplicationReportMutex.Unlock()
-	fake.CreateApplicationReportStub = stub
+func (fake *FakeReporter) CreateSpaceReportCalls(stub func(context.Context, lager.Logger) (reporter.SpaceUsageReport, error)) {
+	fake.createSpaceReportMutex.Lock()
+	defer fake.createSpaceReportMutex.Unlock()
+	fake.CreateSpaceReportStub = stub
 }
 
-func (fake *FakeReporter) CreateApplicationReportArgsForCall(i int) (lager.Logger, string) {
-	fake.createApplicationReportMutex.RLock()
-	defer fake.createApplicationReportMutex.RUnlock()
-	argsForCall := fake.createApplicationReportArgsForCall[i]
+func (fake *FakeReporter) CreateSpaceReportArgsForCall(i int) (context.Context, lager.Logger) {
+	fake.createSpaceReportMutex.RLock()
+	defer fake.createSpaceReportMutex.RUnlock()
+	argsForCall := fake.createSpaceReportArgsForCall[i]
 	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeReporter) CreateApplicationReportReturns(result1 reporter.ApplicationReport, result2 error) {
-	fake.createApplicationReportMutex.Lock()
-	defer fake.createApplicationReportMutex.Unlock()
-	fake.CreateApplicationReportStub = nil
-	fake.createApplicationReportReturns = struct {
-		result1 reporter.ApplicationReport
+func (fake *FakeReporter) CreateSpaceReportReturns(result1 reporter.SpaceUsageReport, result2 error) {
+	fake.createSpaceReportMutex.Lock()
+	defer fake.createSpaceReportMutex.Unlock()
+	fake.CreateSpaceReportStub = nil
+	fake.createSpaceReportReturns = struct {
+		result1 reporter.SpaceUsageReport
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeReporter) CreateApplicationReportReturnsOnCall(i int, result1 reporter.ApplicationReport, result2 error) {
-	fake.createApplicationReportMutex.Lock()
-	defer fake.createApplicationReportMutex.Unlock()
-	fake.CreateApplicationReportStub = nil
-	if fake.createApplicationReportReturnsOnCall == nil {
-		fake.createApplicationReportReturnsOnCall = make(map[int]struct {
-			result1 reporter.ApplicationReport
+func (fake *FakeReporter) CreateSpaceReportReturnsOnCall(i int, result1 reporter.SpaceUsageReport, result2 error) {
+	fake.createSpaceReportMutex.Lock()
+	defer fake.createSpaceReportMutex.Unlock()
+	fake.CreateSpaceReportStub = nil
+	if fake.createSpaceReportReturnsOnCall == nil {
+		fake.createSpaceReportReturnsOnCall = make(map[int]struct {
+			result1 reporter.SpaceUsageReport
 			result2 error
 		})
 	}
-	fake.createApplicationReportReturnsOnCall[i] = struct {
-		result1 reporter.ApplicationReport
+	fake.createSpaceReportReturnsOnCall[i] = struct {
+		result1 reporter.SpaceUsageReport
 		result2 error
 	}{result1, result2}
 }
@@ -95,8 +183,10 @@ func (fake *FakeReporter) CreateApplicationReportReturnsOnCall(i int, result1 re
 func (fake *FakeReporter) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.createApplicationReportMutex.RLock()
-	defer fake.createApplicationReportMutex.RUnlock()
+	fake.createApplicationReportsMutex.RLock()
+	defer fake.createApplicationReportsMutex.RUnlock()
+	fake.createSpaceReportMutex.RLock()
+	defer fake.createSpaceReportMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
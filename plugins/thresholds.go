@@ -0,0 +1,32 @@
+package plugins
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+)
+
+const (
+	thresholdUsage     = "Usage, as a percentage of the entitlement, above which an instance is over entitlement (default 100)"
+	nearThresholdUsage = "Usage, as a percentage of the entitlement, above which an instance is near entitlement (default 95)"
+)
+
+// ThresholdOpts are the flags shared by both commands to decide when an
+// instance is over or near its entitlement. They are percentages of the
+// entitlement.
+type ThresholdOpts struct {
+	Threshold     float64 `long:"threshold" default:"100" description:"Usage, as a percentage of the entitlement, above which an instance is over entitlement"`
+	NearThreshold float64 `long:"near-threshold" default:"95" description:"Usage, as a percentage of the entitlement, above which an instance is near entitlement"`
+}
+
+func (o ThresholdOpts) Thresholds() (reporter.Thresholds, error) {
+	if o.Threshold <= 0 || o.NearThreshold <= 0 {
+		return reporter.Thresholds{}, errors.New("The thresholds must be positive.")
+	}
+
+	if o.NearThreshold > o.Threshold {
+		return reporter.Thresholds{}, errors.New("The near threshold cannot be higher than the threshold.")
+	}
+
+	return reporter.Thresholds{Over: o.Threshold / 100, Near: o.NearThreshold / 100}, nil
+}
@@ -0,0 +1,43 @@
+package plugins_test
+
+import (
+	"code.cloudfoundry.org/cpu-entitlement-plugin/plugins"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+	flags "github.com/jessevdk/go-flags"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ThresholdOpts", func() {
+	var opts struct {
+		plugins.ThresholdOpts
+	}
+
+	BeforeEach(func() {
+		opts.ThresholdOpts = plugins.ThresholdOpts{}
+	})
+
+	parse := func(args ...string) (reporter.Thresholds, error) {
+		_, err := flags.ParseArgs(&opts, args)
+		Expect(err).NotTo(HaveOccurred())
+		return opts.Thresholds()
+	}
+
+	It("defaults to 100% and 95% of the entitlement", func() {
+		Expect(parse()).To(Equal(reporter.DefaultThresholds))
+	})
+
+	It("converts the percentages to ratios", func() {
+		Expect(parse("--threshold", "80", "--near-threshold", "60")).To(Equal(reporter.Thresholds{Over: 0.8, Near: 0.6}))
+	})
+
+	It("fails when a threshold is not positive", func() {
+		_, err := parse("--threshold", "0")
+		Expect(err).To(MatchError("The thresholds must be positive."))
+	})
+
+	It("fails when the near threshold is higher than the threshold", func() {
+		_, err := parse("--threshold", "80")
+		Expect(err).To(MatchError("The near threshold cannot be higher than the threshold."))
+	})
+})
@@ -0,0 +1,98 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+)
+
+var timeArgLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+// ParseTimeArg parses a command line time argument. It accepts either a
+// duration, which is taken to mean that long before now (e.g. "6h"), or an
+// absolute date. Dates without a time zone are in the local time zone.
+func ParseTimeArg(value string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+
+	for _, layout := range timeArgLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("Invalid time %q: use a duration such as 6h or a date such as 2006-01-02 15:04:05", value)
+}
+
+// WindowOrLast returns the given window, or the last d up to now if it is
+// zero.
+func WindowOrLast(window reporter.TimeWindow, d time.Duration, now time.Time) reporter.TimeWindow {
+	if !window.IsZero() {
+		return window
+	}
+
+	return reporter.TimeWindow{Since: now.Add(-d), Until: now}
+}
+
+// TimeRange is the --since and --until arguments. Either may be a duration,
+// so the range is resolved against the time of each report, and moves
+// forward with it in watch mode.
+type TimeRange struct {
+	since string
+	until string
+}
+
+// ParseTimeRange checks the --since and --until arguments. --until defaults
+// to now, and can only be given together with --since.
+func ParseTimeRange(since, until string, now time.Time) (TimeRange, error) {
+	if since == "" {
+		if until != "" {
+			return TimeRange{}, errors.New("--until can only be used together with --since")
+		}
+		return TimeRange{}, nil
+	}
+
+	timeRange := TimeRange{since: since, until: until}
+	window, err := timeRange.resolve(now)
+	if err != nil {
+		return TimeRange{}, err
+	}
+
+	if !window.Since.Before(window.Until) {
+		return TimeRange{}, errors.New("--since must be before --until")
+	}
+
+	return timeRange, nil
+}
+
+// At returns the window the range covers at the given time, or the zero
+// window when --since was not given.
+func (r TimeRange) At(now time.Time) reporter.TimeWindow {
+	window, _ := r.resolve(now)
+	return window
+}
+
+func (r TimeRange) resolve(now time.Time) (reporter.TimeWindow, error) {
+	if r.since == "" {
+		return reporter.TimeWindow{}, nil
+	}
+
+	window := reporter.TimeWindow{Until: now}
+	var err error
+	window.Since, err = ParseTimeArg(r.since, now)
+	if err != nil {
+		return reporter.TimeWindow{}, err
+	}
+
+	if r.until != "" {
+		window.Until, err = ParseTimeArg(r.until, now)
+		if err != nil {
+			return reporter.TimeWindow{}, err
+		}
+	}
+
+	return window, nil
+}
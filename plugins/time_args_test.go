@@ -0,0 +1,98 @@
+package plugins_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/plugins"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseTimeArg", func() {
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Date(2019, 7, 31, 12, 0, 0, 0, time.UTC)
+	})
+
+	It("parses durations as the time that long ago", func() {
+		Expect(plugins.ParseTimeArg("6h", now)).To(Equal(now.Add(-6 * time.Hour)))
+		Expect(plugins.ParseTimeArg("90m", now)).To(Equal(now.Add(-90 * time.Minute)))
+	})
+
+	It("parses RFC3339 dates", func() {
+		Expect(plugins.ParseTimeArg("2019-07-30T09:00:00Z", now)).To(BeTemporally("==", time.Date(2019, 7, 30, 9, 0, 0, 0, time.UTC)))
+	})
+
+	It("parses local dates with and without a time", func() {
+		Expect(plugins.ParseTimeArg("2019-07-30 09:00:00", now)).To(Equal(time.Date(2019, 7, 30, 9, 0, 0, 0, time.Local)))
+		Expect(plugins.ParseTimeArg("2019-07-30", now)).To(Equal(time.Date(2019, 7, 30, 0, 0, 0, 0, time.Local)))
+	})
+
+	It("fails on anything else", func() {
+		_, err := plugins.ParseTimeArg("yesterday", now)
+		Expect(err).To(MatchError(ContainSubstring(`Invalid time "yesterday"`)))
+	})
+})
+
+var _ = Describe("WindowOrLast", func() {
+	It("returns the last duration up to now, which moves forward with now", func() {
+		now := time.Date(2019, 7, 31, 12, 0, 0, 0, time.UTC)
+		Expect(plugins.WindowOrLast(reporter.TimeWindow{}, 30*time.Minute, now)).To(Equal(reporter.TimeWindow{Since: now.Add(-30 * time.Minute), Until: now}))
+
+		later := now.Add(10 * time.Second)
+		Expect(plugins.WindowOrLast(reporter.TimeWindow{}, 30*time.Minute, later)).To(Equal(reporter.TimeWindow{Since: later.Add(-30 * time.Minute), Until: later}))
+	})
+
+	It("returns a given window as it is", func() {
+		window := reporter.TimeWindow{Since: time.Date(2019, 7, 30, 9, 0, 0, 0, time.UTC), Until: time.Date(2019, 7, 30, 11, 0, 0, 0, time.UTC)}
+		Expect(plugins.WindowOrLast(window, 30*time.Minute, time.Now())).To(Equal(window))
+	})
+})
+
+var _ = Describe("ParseTimeRange", func() {
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Date(2019, 7, 31, 12, 0, 0, 0, time.UTC)
+	})
+
+	It("resolves durations against the time of each report", func() {
+		timeRange, err := plugins.ParseTimeRange("2h", "", now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timeRange.At(now)).To(Equal(reporter.TimeWindow{Since: now.Add(-2 * time.Hour), Until: now}))
+
+		later := now.Add(10 * time.Second)
+		Expect(timeRange.At(later)).To(Equal(reporter.TimeWindow{Since: later.Add(-2 * time.Hour), Until: later}))
+	})
+
+	It("keeps dates as they are", func() {
+		timeRange, err := plugins.ParseTimeRange("2019-07-30T09:00:00Z", "2019-07-30T11:00:00Z", now)
+		Expect(err).NotTo(HaveOccurred())
+		window := timeRange.At(now.Add(time.Hour))
+		Expect(window.Since).To(BeTemporally("==", time.Date(2019, 7, 30, 9, 0, 0, 0, time.UTC)))
+		Expect(window.Until).To(BeTemporally("==", time.Date(2019, 7, 30, 11, 0, 0, 0, time.UTC)))
+	})
+
+	It("is empty without --since", func() {
+		timeRange, err := plugins.ParseTimeRange("", "", now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timeRange.At(now).IsZero()).To(BeTrue())
+	})
+
+	It("refuses --until without --since", func() {
+		_, err := plugins.ParseTimeRange("", "1h", now)
+		Expect(err).To(MatchError("--until can only be used together with --since"))
+	})
+
+	It("refuses --since after --until", func() {
+		_, err := plugins.ParseTimeRange("1h", "2h", now)
+		Expect(err).To(MatchError("--since must be before --until"))
+	})
+
+	It("refuses invalid times", func() {
+		_, err := plugins.ParseTimeRange("yesterday", "", now)
+		Expect(err).To(MatchError(ContainSubstring(`Invalid time "yesterday"`)))
+	})
+})
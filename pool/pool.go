@@ -0,0 +1,63 @@
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultSize is the number of workers used unless configured otherwise.
+const DefaultSize = 10
+
+// Run calls work for every index in [0, n), with at most size calls running
+// at the same time. Callers keep results in order by storing them at their
+// index. Run stops starting new work as soon as a call fails or ctx is done,
+// waits for the calls in flight, and returns the first error, or ctx.Err().
+func Run(ctx context.Context, size, n int, work func(ctx context.Context, i int) error) error {
+	if size < 1 {
+		size = 1
+	}
+	if size > n {
+		size = n
+	}
+
+	workCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		firstErr  error
+		errOnce   sync.Once
+		waitGroup sync.WaitGroup
+	)
+
+	indices := make(chan int)
+	for w := 0; w < size; w++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for i := range indices {
+				if err := work(workCtx, i); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for i := 0; i < n && workCtx.Err() == nil; i++ {
+		select {
+		case <-workCtx.Done():
+			break dispatch
+		case indices <- i:
+		}
+	}
+	close(indices)
+	waitGroup.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,125 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/pool"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Run", func() {
+	It("calls the work for every index", func() {
+		results := make([]int, 100)
+		err := pool.Run(context.Background(), 7, len(results), func(ctx context.Context, i int) error {
+			results[i] = i * i
+			return nil
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		for i, result := range results {
+			Expect(result).To(Equal(i * i))
+		}
+	})
+
+	It("does nothing when there is no work", func() {
+		Expect(pool.Run(context.Background(), 5, 0, func(ctx context.Context, i int) error {
+			Fail("unexpected call")
+			return nil
+		})).To(Succeed())
+	})
+
+	It("runs at most size calls at the same time", func() {
+		var running, maxRunning int32
+		err := pool.Run(context.Background(), 3, 30, func(ctx context.Context, i int) error {
+			current := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(atomic.LoadInt32(&maxRunning)).To(BeNumerically("<=", 3))
+		Expect(atomic.LoadInt32(&maxRunning)).To(BeNumerically(">", 1))
+	})
+
+	It("treats a size below one as one", func() {
+		var calls int32
+		Expect(pool.Run(context.Background(), 0, 5, func(ctx context.Context, i int) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		})).To(Succeed())
+		Expect(calls).To(BeEquivalentTo(5))
+	})
+
+	When("a call fails", func() {
+		It("returns the error and stops starting new work", func() {
+			var calls int32
+			err := pool.Run(context.Background(), 1, 10, func(ctx context.Context, i int) error {
+				atomic.AddInt32(&calls, 1)
+				if i == 2 {
+					return errors.New("work-error")
+				}
+				return nil
+			})
+
+			Expect(err).To(MatchError("work-error"))
+			Expect(atomic.LoadInt32(&calls)).To(BeNumerically("<=", 4))
+		})
+
+		It("cancels the context of the calls in flight", func() {
+			err := pool.Run(context.Background(), 2, 2, func(ctx context.Context, i int) error {
+				if i == 0 {
+					return errors.New("work-error")
+				}
+				<-ctx.Done()
+				return ctx.Err()
+			})
+
+			Expect(err).To(MatchError("work-error"))
+		})
+	})
+
+	When("the context is cancelled", func() {
+		It("stops starting new work and returns the context error", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			var (
+				mutex sync.Mutex
+				calls []int
+			)
+			err := pool.Run(ctx, 1, 10, func(ctx context.Context, i int) error {
+				mutex.Lock()
+				calls = append(calls, i)
+				mutex.Unlock()
+				if i == 1 {
+					cancel()
+				}
+				return nil
+			})
+
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(len(calls)).To(BeNumerically("<=", 3))
+		})
+
+		It("does not start any work when already cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := pool.Run(ctx, 3, 10, func(ctx context.Context, i int) error {
+				Fail("unexpected call")
+				return nil
+			})
+			Expect(err).To(MatchError(context.Canceled))
+		})
+	})
+})
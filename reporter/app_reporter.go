@@ -1,12 +1,16 @@
 package reporter
 
 import (
+	"context"
 	"fmt"
+	"path"
 	"sort"
+	"strings"
 	"time"
 
 	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/pool"
 	"code.cloudfoundry.org/lager"
 )
 
@@ -22,23 +26,54 @@ func NewUnsupportedCFDeploymentError(appName string) error {
 	return UnsupportedCFDeploymentError{message: fmt.Sprintf("Could not find any CPU data for app %s. Make sure that you are using cf-deployment version >= v5.5.0.", appName)}
 }
 
+type NoMatchingAppsError struct {
+	message string
+}
+
+func (e NoMatchingAppsError) Error() string {
+	return e.message
+}
+
+func NewNoMatchingAppsError(pattern string) error {
+	return NoMatchingAppsError{message: fmt.Sprintf("No apps in the targeted space match %s.", pattern)}
+}
+
 type AppReporter struct {
 	currentUsageFetcher    InstanceDataFetcher
 	lastSpikeFetcher       InstanceDataFetcher
 	cumulativeUsageFetcher InstanceDataFetcher
+	spikeHistoryFetcher    InstanceDataFetcher
+	usageHistoryFetcher    InstanceDataFetcher
+	usageStatsFetcher      InstanceDataFetcher
+	cpuRateFetcher         InstanceDataFetcher
+	throttleFetcher        InstanceDataFetcher
+	throttleModel          ThrottleModel
 	cfClient               AppReporterCloudFoundryClient
+	usageWindow            TimeWindow
+	spikeWindowSince       time.Time
+	retentionFetcher       RetentionFetcher
+	recommendationTarget   float64
+	recommendationStats    InstanceDataFetcher
+	concurrency            int
 }
 
 //go:generate counterfeiter . InstanceDataFetcher
 
 type InstanceDataFetcher interface {
-	FetchInstanceData(logger lager.Logger, appGUID string, appInstances map[int]cf.Instance) (map[int]interface{}, error)
+	FetchInstanceData(ctx context.Context, logger lager.Logger, appGUID string, appInstances map[int]cf.Instance) (map[int]interface{}, error)
+}
+
+//go:generate counterfeiter . RetentionFetcher
+
+type RetentionFetcher interface {
+	FetchOldestTimestamp(ctx context.Context, logger lager.Logger, appGUID string, since time.Time) (time.Time, error)
 }
 
 //go:generate counterfeiter . AppReporterCloudFoundryClient
 
 type AppReporterCloudFoundryClient interface {
-	GetApplication(logger lager.Logger, appName string) (cf.Application, error)
+	GetApplication(ctx context.Context, logger lager.Logger, appName string) (cf.Application, error)
+	GetApplicationNames(ctx context.Context, logger lager.Logger) ([]string, error)
 	GetCurrentOrg(logger lager.Logger) (string, error)
 	GetCurrentSpace(logger lager.Logger) (string, error)
 	Username(logger lager.Logger) (string, error)
@@ -49,14 +84,62 @@ type ApplicationReport struct {
 	Username        string
 	Space           string
 	ApplicationName string
+	UsageWindow     TimeWindow
+	SpikeWindow     SpikeWindow
 	InstanceReports []InstanceReport
+	Recommendations []Recommendation
+}
+
+// TimeWindow is the period over which the cumulative usage is averaged. The
+// zero value means since each instance started.
+type TimeWindow struct {
+	Since time.Time
+	Until time.Time
+}
+
+func (w TimeWindow) IsZero() bool {
+	return w.Since.IsZero() && w.Until.IsZero()
+}
+
+// metricsInterval is the longest time between two envelopes of a running app.
+// Data starting within this long after the requested time covers the whole
+// spike window.
+const metricsInterval = time.Minute
+
+// SpikeWindow is the period spikes are looked up in. AvailableSince is later
+// than Since when log-cache does not hold data for the whole period. The zero
+// value means the window has not been checked.
+type SpikeWindow struct {
+	Since          time.Time
+	AvailableSince time.Time
 }
 
+func (w SpikeWindow) IsTruncated() bool {
+	return w.AvailableSince.After(w.Since)
+}
+
+// InstanceReport is the report of an instance of the process of the given
+// type. ProcessType is empty in over-entitlement reports, which only cover
+// web processes.
 type InstanceReport struct {
+	ProcessType     string
 	InstanceID      int
 	CumulativeUsage CumulativeUsage
 	CurrentUsage    CurrentUsage
 	LastSpike       LastSpike
+	SpikeHistory    *SpikeHistory
+	UsageHistory    *UsageHistory
+	UsageStats      *UsageStats
+	CPURate         *CPURate
+	ThrottlePreview *ThrottlePreview
+}
+
+// CPURate is the CPU time an instance currently uses and is entitled to per
+// second, in cores. It is only set on instance reports when the reporter has
+// a CPU rate fetcher.
+type CPURate struct {
+	Usage       float64
+	Entitlement float64
 }
 
 type LastSpike struct {
@@ -64,6 +147,49 @@ type LastSpike struct {
 	To   time.Time
 }
 
+// SpikeHistory is only set on instance reports when the reporter has a spike
+// history fetcher.
+type SpikeHistory struct {
+	Spikes []Spike
+	// TruncatedBefore is set when there were too many spikes to read them
+	// all, and spikes before then may be missing.
+	TruncatedBefore time.Time
+}
+
+type Spike struct {
+	From time.Time
+	To   time.Time
+}
+
+func (s Spike) Duration() time.Duration {
+	return s.To.Sub(s.From)
+}
+
+// IsTruncated tells whether spikes before TruncatedBefore may be missing.
+func (h SpikeHistory) IsTruncated() bool {
+	return !h.TruncatedBefore.IsZero()
+}
+
+// TotalDuration is the time the instance spent over entitlement.
+func (h SpikeHistory) TotalDuration() time.Duration {
+	var total time.Duration
+	for _, spike := range h.Spikes {
+		total += spike.Duration()
+	}
+	return total
+}
+
+// UsageHistory is only set on instance reports when the reporter has a usage
+// history fetcher.
+type UsageHistory struct {
+	Samples []UsageSample
+}
+
+type UsageSample struct {
+	Time  time.Time
+	Value float64
+}
+
 type CurrentUsage struct {
 	Value float64
 }
@@ -78,15 +204,168 @@ func NewAppReporter(cfClient AppReporterCloudFoundryClient, currentUsageFetcher,
 		currentUsageFetcher:    currentUsageFetcher,
 		lastSpikeFetcher:       lastSpikeFetcher,
 		cumulativeUsageFetcher: cumulativeUsageFetcher,
+		concurrency:            pool.DefaultSize,
+	}
+}
+
+// WithConcurrency sets how many apps the reports of are created at the same
+// time.
+func (r AppReporter) WithConcurrency(concurrency int) AppReporter {
+	r.concurrency = concurrency
+	return r
+}
+
+// WithUsageWindow records the window the cumulative usage fetcher averages
+// over in the reports.
+func (r AppReporter) WithUsageWindow(window TimeWindow) AppReporter {
+	r.usageWindow = window
+	return r
+}
+
+// WithSpikeHistoryFetcher makes the reporter include every spike of each
+// instance in the reports, rather than only the last one.
+func (r AppReporter) WithSpikeHistoryFetcher(spikeHistoryFetcher InstanceDataFetcher) AppReporter {
+	r.spikeHistoryFetcher = spikeHistoryFetcher
+	return r
+}
+
+// WithSpikeWindow makes the reporter check whether log-cache holds data for
+// the whole period spikes are looked up in.
+func (r AppReporter) WithSpikeWindow(since time.Time, retentionFetcher RetentionFetcher) AppReporter {
+	r.spikeWindowSince = since
+	r.retentionFetcher = retentionFetcher
+	return r
+}
+
+// WithUsageHistoryFetcher makes the reporter include the usage of each
+// instance over time in the reports.
+func (r AppReporter) WithUsageHistoryFetcher(usageHistoryFetcher InstanceDataFetcher) AppReporter {
+	r.usageHistoryFetcher = usageHistoryFetcher
+	return r
+}
+
+// WithUsageStatsFetcher makes the reporter include percentiles of the usage
+// samples returned by the given usage history fetcher in the reports.
+func (r AppReporter) WithUsageStatsFetcher(usageStatsFetcher InstanceDataFetcher) AppReporter {
+	r.usageStatsFetcher = usageStatsFetcher
+	return r
+}
+
+// WithCPURateFetcher makes the reporter include the usage and entitlement of
+// each instance in cores in the reports.
+func (r AppReporter) WithCPURateFetcher(cpuRateFetcher InstanceDataFetcher) AppReporter {
+	r.cpuRateFetcher = cpuRateFetcher
+	return r
+}
+
+// WithThrottlePreview makes the reporter include when each instance would
+// have been deprioritized by the model, given the usage samples returned by
+// the given usage history fetcher.
+func (r AppReporter) WithThrottlePreview(usageHistoryFetcher InstanceDataFetcher, model ThrottleModel) AppReporter {
+	r.throttleFetcher = usageHistoryFetcher
+	r.throttleModel = model
+	return r
+}
+
+// WithRecommendations makes the reporter recommend how to scale the processes
+// whose busiest instance uses more than target of its entitlement. The
+// recommendations are based on percentiles of the usage samples returned by
+// the given usage history fetcher, if any. These percentiles are only
+// included in the reports when a usage stats fetcher is set as well.
+func (r AppReporter) WithRecommendations(target float64, usageStatsFetcher InstanceDataFetcher) AppReporter {
+	r.recommendationTarget = target
+	r.recommendationStats = usageStatsFetcher
+	return r
+}
+
+// CreateApplicationReports creates a report for each of the given apps, in
+// the same order. Names containing glob characters are patterns, which are
+// replaced by the names of the matching apps in the targeted space. The
+// reports are created concurrently, and as soon as one fails the others are
+// cancelled and its error is returned.
+func (r AppReporter) CreateApplicationReports(ctx context.Context, logger lager.Logger, appNames []string) ([]ApplicationReport, error) {
+	logger = logger.Session("create-application-reports", lager.Data{"apps": appNames})
+	logger.Info("start")
+	defer logger.Info("end")
+
+	appNames, err := r.resolveAppNames(ctx, logger, appNames)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]ApplicationReport, len(appNames))
+	err = pool.Run(ctx, r.concurrency, len(appNames), func(ctx context.Context, i int) error {
+		var err error
+		reports[i], err = r.CreateApplicationReport(ctx, logger, appNames[i])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+func (r AppReporter) resolveAppNames(ctx context.Context, logger lager.Logger, patterns []string) ([]string, error) {
+	var spaceAppNames []string
+	var appNames []string
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		if !isGlob(pattern) {
+			if !seen[pattern] {
+				seen[pattern] = true
+				appNames = append(appNames, pattern)
+			}
+			continue
+		}
+
+		if spaceAppNames == nil {
+			var err error
+			spaceAppNames, err = r.cfClient.GetApplicationNames(ctx, logger)
+			if err != nil {
+				return nil, err
+			}
+			sort.Strings(spaceAppNames)
+		}
+
+		matched := false
+		for _, appName := range spaceAppNames {
+			ok, err := path.Match(pattern, appName)
+			if err != nil {
+				logger.Error("invalid-app-name-pattern", err, lager.Data{"pattern": pattern})
+				return nil, fmt.Errorf("Invalid app name pattern %s: %s", pattern, err.Error())
+			}
+			if !ok {
+				continue
+			}
+
+			matched = true
+			if !seen[appName] {
+				seen[appName] = true
+				appNames = append(appNames, appName)
+			}
+		}
+
+		if !matched {
+			err := NewNoMatchingAppsError(pattern)
+			logger.Error("no-matching-apps", err)
+			return nil, err
+		}
 	}
+
+	return appNames, nil
 }
 
-func (r AppReporter) CreateApplicationReport(logger lager.Logger, appName string) (ApplicationReport, error) {
+func isGlob(appName string) bool {
+	return strings.ContainsAny(appName, "*?[")
+}
+
+func (r AppReporter) CreateApplicationReport(ctx context.Context, logger lager.Logger, appName string) (ApplicationReport, error) {
 	logger = logger.Session("create-application-report", lager.Data{"app": appName})
 	logger.Info("start")
 	defer logger.Info("end")
 
-	application, err := r.cfClient.GetApplication(logger, appName)
+	application, err := r.cfClient.GetApplication(ctx, logger, appName)
 	if err != nil {
 		return ApplicationReport{}, err
 	}
@@ -106,23 +385,81 @@ func (r AppReporter) CreateApplicationReport(logger lager.Logger, appName string
 		return ApplicationReport{}, err
 	}
 
-	if len(application.Instances) == 0 {
+	processes := application.AllProcesses()
+	if !hasInstances(processes) {
 		logger.Info("no-instances-found-for-app")
-		return ApplicationReport{Org: org, Space: space, Username: user, ApplicationName: appName}, nil
+		return ApplicationReport{Org: org, Space: space, Username: user, ApplicationName: appName, UsageWindow: r.usageWindow}, nil
 	}
 
-	latestReports := map[int]InstanceReport{}
-
-	currentUsagePerInstance, err := r.currentUsageFetcher.FetchInstanceData(logger, application.Guid, application.Instances)
+	spikeWindow, err := r.fetchSpikeWindow(ctx, logger, application)
 	if err != nil {
 		return ApplicationReport{}, err
 	}
-	if len(currentUsagePerInstance) == 0 {
+
+	var instanceReports []InstanceReport
+	var recommendations []Recommendation
+	hasCurrentUsage := false
+	for _, process := range processes {
+		if len(process.Instances) == 0 {
+			continue
+		}
+
+		processReports, processHasCurrentUsage, err := r.createProcessInstanceReports(ctx, logger, process)
+		if err != nil {
+			return ApplicationReport{}, err
+		}
+		hasCurrentUsage = hasCurrentUsage || processHasCurrentUsage
+
+		if r.recommendationTarget > 0 {
+			if recommendation, ok := recommend(process, processReports, r.recommendationTarget); ok {
+				recommendations = append(recommendations, recommendation)
+			}
+		}
+
+		if r.usageStatsFetcher == nil {
+			for i := range processReports {
+				processReports[i].UsageStats = nil
+			}
+		}
+		instanceReports = append(instanceReports, processReports...)
+	}
+
+	if !hasCurrentUsage {
 		err = NewUnsupportedCFDeploymentError(appName)
 		logger.Error("no-current-usage-data-found", err)
 		return ApplicationReport{}, err
 	}
 
+	return ApplicationReport{Org: org, Space: space, Username: user, ApplicationName: appName, UsageWindow: r.usageWindow, SpikeWindow: spikeWindow, InstanceReports: instanceReports, Recommendations: recommendations}, nil
+}
+
+func hasInstances(processes []cf.Process) bool {
+	for _, process := range processes {
+		if len(process.Instances) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// createProcessInstanceReports creates the reports of the instances of a
+// process, sorted by instance ID. It also tells whether any current usage was
+// found, as there is none on deployments that do not emit CPU entitlement
+// metrics.
+func (r AppReporter) createProcessInstanceReports(ctx context.Context, logger lager.Logger, process cf.Process) ([]InstanceReport, bool, error) {
+	logger = logger.Session("create-process-instance-reports", lager.Data{"process-type": process.Type, "process-guid": process.Guid})
+
+	latestReports := map[int]InstanceReport{}
+
+	currentUsagePerInstance, err := r.currentUsageFetcher.FetchInstanceData(ctx, logger, process.Guid, process.Instances)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(currentUsagePerInstance) == 0 {
+		return nil, false, nil
+	}
+
 	for instanceID, instanceData := range currentUsagePerInstance {
 		currentInstanceData, ok := instanceData.(fetchers.CurrentInstanceData)
 		if !ok {
@@ -136,9 +473,9 @@ func (r AppReporter) CreateApplicationReport(logger lager.Logger, appName string
 		latestReports[instanceID] = currentReport
 	}
 
-	lastSpikePerInstance, err := r.lastSpikeFetcher.FetchInstanceData(logger, application.Guid, application.Instances)
+	lastSpikePerInstance, err := r.lastSpikeFetcher.FetchInstanceData(ctx, logger, process.Guid, process.Instances)
 	if err != nil {
-		return ApplicationReport{}, err
+		return nil, false, err
 	}
 
 	for instanceID, data := range lastSpikePerInstance {
@@ -156,9 +493,9 @@ func (r AppReporter) CreateApplicationReport(logger lager.Logger, appName string
 		latestReports[instanceID] = currentReport
 	}
 
-	cumulativeUsagePerInstance, err := r.cumulativeUsageFetcher.FetchInstanceData(logger, application.Guid, application.Instances)
+	cumulativeUsagePerInstance, err := r.cumulativeUsageFetcher.FetchInstanceData(ctx, logger, process.Guid, process.Instances)
 	if err != nil {
-		return ApplicationReport{}, err
+		return nil, false, err
 	}
 
 	for instanceID, data := range cumulativeUsagePerInstance {
@@ -174,9 +511,226 @@ func (r AppReporter) CreateApplicationReport(logger lager.Logger, appName string
 		}
 		latestReports[instanceID] = currentReport
 	}
+
+	if r.spikeHistoryFetcher != nil {
+		err = r.addSpikeHistory(ctx, logger, process, latestReports)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if r.usageHistoryFetcher != nil {
+		err = r.addUsageHistory(ctx, logger, process, latestReports)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if usageStatsFetcher := r.statsFetcher(); usageStatsFetcher != nil {
+		err = r.addUsageStats(ctx, logger, usageStatsFetcher, process, latestReports)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if r.cpuRateFetcher != nil {
+		err = r.addCPURates(ctx, logger, process, latestReports)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if r.throttleFetcher != nil {
+		err = r.addThrottlePreviews(ctx, logger, process, latestReports)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
 	instanceReports := buildReportsSlice(latestReports)
+	for i := range instanceReports {
+		instanceReports[i].ProcessType = process.Type
+	}
+
+	return instanceReports, true, nil
+}
+
+func (r AppReporter) fetchSpikeWindow(ctx context.Context, logger lager.Logger, application cf.Application) (SpikeWindow, error) {
+	if r.retentionFetcher == nil {
+		return SpikeWindow{}, nil
+	}
+
+	oldest, err := r.retentionFetcher.FetchOldestTimestamp(ctx, logger, application.Guid, r.spikeWindowSince)
+	if err != nil {
+		return SpikeWindow{}, err
+	}
+
+	spikeWindow := SpikeWindow{Since: r.spikeWindowSince, AvailableSince: r.spikeWindowSince}
+	if oldest.After(r.spikeWindowSince.Add(metricsInterval)) {
+		spikeWindow.AvailableSince = oldest
+	}
+
+	return spikeWindow, nil
+}
+
+func (r AppReporter) addSpikeHistory(ctx context.Context, logger lager.Logger, process cf.Process, latestReports map[int]InstanceReport) error {
+	spikeHistoryPerInstance, err := r.spikeHistoryFetcher.FetchInstanceData(ctx, logger, process.Guid, process.Instances)
+	if err != nil {
+		return err
+	}
+
+	// Spikes are read for the whole app, so when the read is truncated, the
+	// history of every instance is.
+	var truncatedBefore time.Time
+	for _, data := range spikeHistoryPerInstance {
+		if spikeHistoryInstanceData, ok := data.(fetchers.SpikeHistoryInstanceData); ok && spikeHistoryInstanceData.TruncatedBefore.After(truncatedBefore) {
+			truncatedBefore = spikeHistoryInstanceData.TruncatedBefore
+		}
+	}
+
+	for instanceID, report := range latestReports {
+		report.SpikeHistory = &SpikeHistory{TruncatedBefore: truncatedBefore}
+		latestReports[instanceID] = report
+	}
+
+	for instanceID, data := range spikeHistoryPerInstance {
+		spikeHistoryInstanceData, ok := data.(fetchers.SpikeHistoryInstanceData)
+		if !ok {
+			logger.Info("spike-history-reporter-returned-wrong-type",
+				lager.Data{"instance-data": data})
+			continue
+		}
+
+		var spikes []Spike
+		for _, spike := range spikeHistoryInstanceData.Spikes {
+			spikes = append(spikes, Spike{From: spike.From, To: spike.To})
+		}
+
+		currentReport := getOrCreateInstanceReport(latestReports, instanceID)
+		currentReport.SpikeHistory = &SpikeHistory{Spikes: spikes, TruncatedBefore: truncatedBefore}
+		latestReports[instanceID] = currentReport
+	}
+
+	return nil
+}
+
+func (r AppReporter) addUsageHistory(ctx context.Context, logger lager.Logger, process cf.Process, latestReports map[int]InstanceReport) error {
+	samplesPerInstance, err := fetchUsageSamples(ctx, logger, r.usageHistoryFetcher, process)
+	if err != nil {
+		return err
+	}
+
+	for instanceID, report := range latestReports {
+		report.UsageHistory = &UsageHistory{}
+		latestReports[instanceID] = report
+	}
+
+	for instanceID, samples := range samplesPerInstance {
+		currentReport := getOrCreateInstanceReport(latestReports, instanceID)
+		currentReport.UsageHistory = &UsageHistory{Samples: samples}
+		latestReports[instanceID] = currentReport
+	}
+
+	return nil
+}
+
+// statsFetcher returns the fetcher of the samples the usage stats are computed
+// from. The stats are fetched when they are shown or when the recommendations
+// are based on them, but only once when both are requested.
+func (r AppReporter) statsFetcher() InstanceDataFetcher {
+	if r.usageStatsFetcher != nil {
+		return r.usageStatsFetcher
+	}
+	return r.recommendationStats
+}
+
+func (r AppReporter) addUsageStats(ctx context.Context, logger lager.Logger, usageStatsFetcher InstanceDataFetcher, process cf.Process, latestReports map[int]InstanceReport) error {
+	samplesPerInstance, err := fetchUsageSamples(ctx, logger, usageStatsFetcher, process)
+	if err != nil {
+		return err
+	}
+
+	for instanceID, report := range latestReports {
+		report.UsageStats = &UsageStats{}
+		latestReports[instanceID] = report
+	}
+
+	for instanceID, samples := range samplesPerInstance {
+		usageStats := computeUsageStats(samples)
+		currentReport := getOrCreateInstanceReport(latestReports, instanceID)
+		currentReport.UsageStats = &usageStats
+		latestReports[instanceID] = currentReport
+	}
+
+	return nil
+}
+
+func (r AppReporter) addThrottlePreviews(ctx context.Context, logger lager.Logger, process cf.Process, latestReports map[int]InstanceReport) error {
+	samplesPerInstance, err := fetchUsageSamples(ctx, logger, r.throttleFetcher, process)
+	if err != nil {
+		return err
+	}
+
+	for instanceID, report := range latestReports {
+		report.ThrottlePreview = &ThrottlePreview{Window: r.throttleModel.Window}
+		latestReports[instanceID] = report
+	}
+
+	for instanceID, samples := range samplesPerInstance {
+		throttlePreview := r.throttleModel.Preview(samples)
+		currentReport := getOrCreateInstanceReport(latestReports, instanceID)
+		currentReport.ThrottlePreview = &throttlePreview
+		latestReports[instanceID] = currentReport
+	}
+
+	return nil
+}
+
+func (r AppReporter) addCPURates(ctx context.Context, logger lager.Logger, process cf.Process, latestReports map[int]InstanceReport) error {
+	cpuRatePerInstance, err := r.cpuRateFetcher.FetchInstanceData(ctx, logger, process.Guid, process.Instances)
+	if err != nil {
+		return err
+	}
+
+	for instanceID, data := range cpuRatePerInstance {
+		cpuRateInstanceData, ok := data.(fetchers.CPURateInstanceData)
+		if !ok {
+			logger.Info("cpu-rate-reporter-returned-wrong-type",
+				lager.Data{"instance-data": data})
+			continue
+		}
+
+		currentReport := getOrCreateInstanceReport(latestReports, instanceID)
+		currentReport.CPURate = &CPURate{Usage: cpuRateInstanceData.Usage, Entitlement: cpuRateInstanceData.Entitlement}
+		latestReports[instanceID] = currentReport
+	}
+
+	return nil
+}
+
+func fetchUsageSamples(ctx context.Context, logger lager.Logger, usageHistoryFetcher InstanceDataFetcher, process cf.Process) (map[int][]UsageSample, error) {
+	usageHistoryPerInstance, err := usageHistoryFetcher.FetchInstanceData(ctx, logger, process.Guid, process.Instances)
+	if err != nil {
+		return nil, err
+	}
+
+	samplesPerInstance := map[int][]UsageSample{}
+	for instanceID, data := range usageHistoryPerInstance {
+		usageHistoryInstanceData, ok := data.(fetchers.UsageHistoryInstanceData)
+		if !ok {
+			logger.Info("usage-history-reporter-returned-wrong-type",
+				lager.Data{"instance-data": data})
+			continue
+		}
+
+		var samples []UsageSample
+		for _, sample := range usageHistoryInstanceData.Samples {
+			samples = append(samples, UsageSample{Time: sample.Time, Value: sample.Usage})
+		}
+		samplesPerInstance[instanceID] = samples
+	}
 
-	return ApplicationReport{Org: org, Space: space, Username: user, ApplicationName: appName, InstanceReports: instanceReports}, nil
+	return samplesPerInstance, nil
 }
 
 func getOrCreateInstanceReport(reports map[int]InstanceReport, instanceID int) InstanceReport {
@@ -1,7 +1,9 @@
 package reporter_test
 
 import (
+	"context"
 	"errors"
+	"sync/atomic"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -57,7 +59,7 @@ var _ = Describe("Reporter", func() {
 	})
 
 	JustBeforeEach(func() {
-		reports, err = instanceReporter.CreateApplicationReport(logger, appName)
+		reports, err = instanceReporter.CreateApplicationReport(context.Background(), logger, appName)
 	})
 
 	Describe("Report", func() {
@@ -104,6 +106,20 @@ var _ = Describe("Reporter", func() {
 			})
 		})
 
+		It("does not report a usage window", func() {
+			Expect(reports.UsageWindow.IsZero()).To(BeTrue())
+		})
+
+		When("the reporter has a usage window", func() {
+			BeforeEach(func() {
+				instanceReporter = instanceReporter.WithUsageWindow(reporter.TimeWindow{Since: time.Unix(5, 0), Until: time.Unix(10, 0)})
+			})
+
+			It("reports the usage window", func() {
+				Expect(reports.UsageWindow).To(Equal(reporter.TimeWindow{Since: time.Unix(5, 0), Until: time.Unix(10, 0)}))
+			})
+		})
+
 		It("reports the org", func() {
 			Expect(reports.Org).To(Equal("the-org"))
 		})
@@ -211,6 +227,78 @@ var _ = Describe("Reporter", func() {
 		})
 	})
 
+	Describe("Processes", func() {
+		var workerInstances map[int]cf.Instance
+
+		BeforeEach(func() {
+			workerInstances = map[int]cf.Instance{0: cf.Instance{InstanceID: 0, ProcessInstanceID: "worker-0"}}
+			cfClient.GetApplicationReturns(cf.Application{Name: appName, Guid: appGuid, Instances: appInstances, Processes: []cf.Process{
+				{Type: "web", Guid: appGuid, Instances: appInstances},
+				{Type: "worker", Guid: "worker-guid", Instances: workerInstances},
+			}}, nil)
+			currentUsageFetcher.FetchInstanceDataStub = func(_ context.Context, logger lager.Logger, guid string, instances map[int]cf.Instance) (map[int]interface{}, error) {
+				usage := 0.5
+				if guid == "worker-guid" {
+					usage = 0.8
+				}
+				return map[int]interface{}{0: fetchers.CurrentInstanceData{InstanceID: 0, Usage: usage}}, nil
+			}
+		})
+
+		It("fetches the usage of every process by its guid", func() {
+			Expect(currentUsageFetcher.FetchInstanceDataCallCount()).To(Equal(2))
+			_, _, guid, instances := currentUsageFetcher.FetchInstanceDataArgsForCall(0)
+			Expect(guid).To(Equal(appGuid))
+			Expect(instances).To(Equal(appInstances))
+			_, _, guid, instances = currentUsageFetcher.FetchInstanceDataArgsForCall(1)
+			Expect(guid).To(Equal("worker-guid"))
+			Expect(instances).To(Equal(workerInstances))
+		})
+
+		It("reports the instances of every process, grouped by process type", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reports.InstanceReports).To(HaveLen(2))
+			Expect(reports.InstanceReports[0].ProcessType).To(Equal("web"))
+			Expect(reports.InstanceReports[0].CurrentUsage.Value).To(Equal(0.5))
+			Expect(reports.InstanceReports[1].ProcessType).To(Equal("worker"))
+			Expect(reports.InstanceReports[1].InstanceID).To(Equal(0))
+			Expect(reports.InstanceReports[1].CurrentUsage.Value).To(Equal(0.8))
+		})
+
+		When("only the worker process has instances", func() {
+			BeforeEach(func() {
+				cfClient.GetApplicationReturns(cf.Application{Name: appName, Guid: appGuid, Processes: []cf.Process{
+					{Type: "web", Guid: appGuid, Instances: map[int]cf.Instance{}},
+					{Type: "worker", Guid: "worker-guid", Instances: workerInstances},
+				}}, nil)
+			})
+
+			It("reports the worker instances", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(currentUsageFetcher.FetchInstanceDataCallCount()).To(Equal(1))
+				Expect(reports.InstanceReports).To(HaveLen(1))
+				Expect(reports.InstanceReports[0].ProcessType).To(Equal("worker"))
+			})
+		})
+
+		When("a process has no current usage data", func() {
+			BeforeEach(func() {
+				currentUsageFetcher.FetchInstanceDataStub = func(_ context.Context, logger lager.Logger, guid string, instances map[int]cf.Instance) (map[int]interface{}, error) {
+					if guid == "worker-guid" {
+						return map[int]interface{}{}, nil
+					}
+					return map[int]interface{}{0: fetchers.CurrentInstanceData{InstanceID: 0, Usage: 0.5}}, nil
+				}
+			})
+
+			It("reports the other processes", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reports.InstanceReports).To(HaveLen(1))
+				Expect(reports.InstanceReports[0].ProcessType).To(Equal("web"))
+			})
+		})
+	})
+
 	Describe("Cumulative CPU usage", func() {
 		BeforeEach(func() {
 			cumulativeUsageFetcher.FetchInstanceDataReturns(map[int]interface{}{
@@ -227,7 +315,7 @@ var _ = Describe("Reporter", func() {
 
 		It("fetches the usage data correctly", func() {
 			Expect(cumulativeUsageFetcher.FetchInstanceDataCallCount()).To(Equal(1))
-			_, actualAppGuid, actualAppInstances := cumulativeUsageFetcher.FetchInstanceDataArgsForCall(0)
+			_, _, actualAppGuid, actualAppInstances := cumulativeUsageFetcher.FetchInstanceDataArgsForCall(0)
 			Expect(actualAppGuid).To(Equal(appGuid))
 			Expect(actualAppInstances).To(Equal(appInstances))
 		})
@@ -297,7 +385,7 @@ var _ = Describe("Reporter", func() {
 	Describe("Last spike", func() {
 		It("fetches the spike data correctly", func() {
 			Expect(lastSpikeFetcher.FetchInstanceDataCallCount()).To(Equal(1))
-			_, actualAppGuid, actualAppInstances := cumulativeUsageFetcher.FetchInstanceDataArgsForCall(0)
+			_, _, actualAppGuid, actualAppInstances := cumulativeUsageFetcher.FetchInstanceDataArgsForCall(0)
 			Expect(actualAppGuid).To(Equal(appGuid))
 			Expect(actualAppInstances).To(Equal(appInstances))
 		})
@@ -368,7 +456,7 @@ var _ = Describe("Reporter", func() {
 
 		It("fetches the usage data correctly", func() {
 			Expect(currentUsageFetcher.FetchInstanceDataCallCount()).To(Equal(1))
-			_, actualAppGuid, actualAppInstances := currentUsageFetcher.FetchInstanceDataArgsForCall(0)
+			_, _, actualAppGuid, actualAppInstances := currentUsageFetcher.FetchInstanceDataArgsForCall(0)
 			Expect(actualAppGuid).To(Equal(appGuid))
 			Expect(actualAppInstances).To(Equal(appInstances))
 		})
@@ -415,4 +503,644 @@ var _ = Describe("Reporter", func() {
 			Expect(reports.InstanceReports[1].CurrentUsage.Value).To(Equal(1.7))
 		})
 	})
+
+	Describe("Spike history", func() {
+		var spikeHistoryFetcher *reporterfakes.FakeInstanceDataFetcher
+
+		BeforeEach(func() {
+			currentUsageFetcher.FetchInstanceDataReturns(map[int]interface{}{
+				0: fetchers.CurrentInstanceData{InstanceID: 0, Usage: 0.5},
+				1: fetchers.CurrentInstanceData{InstanceID: 1, Usage: 0.5},
+			}, nil)
+
+			spikeHistoryFetcher = new(reporterfakes.FakeInstanceDataFetcher)
+			spikeHistoryFetcher.FetchInstanceDataReturns(map[int]interface{}{
+				0: fetchers.SpikeHistoryInstanceData{
+					InstanceID: 0,
+					Spikes: []fetchers.Spike{
+						{From: time.Unix(5, 0), To: time.Unix(10, 0)},
+						{From: time.Unix(20, 0), To: time.Unix(30, 0)},
+					},
+				},
+			}, nil)
+		})
+
+		It("does not report spike history by default", func() {
+			Expect(reports.InstanceReports[0].SpikeHistory).To(BeNil())
+			Expect(reports.InstanceReports[1].SpikeHistory).To(BeNil())
+		})
+
+		When("the reporter has a spike history fetcher", func() {
+			BeforeEach(func() {
+				instanceReporter = instanceReporter.WithSpikeHistoryFetcher(spikeHistoryFetcher)
+			})
+
+			It("fetches the spike history of the application", func() {
+				Expect(spikeHistoryFetcher.FetchInstanceDataCallCount()).To(Equal(1))
+				_, _, actualAppGuid, actualAppInstances := spikeHistoryFetcher.FetchInstanceDataArgsForCall(0)
+				Expect(actualAppGuid).To(Equal(appGuid))
+				Expect(actualAppInstances).To(Equal(appInstances))
+			})
+
+			It("reports the spike history of every instance", func() {
+				Expect(reports.InstanceReports).To(HaveLen(2))
+				Expect(reports.InstanceReports[0].SpikeHistory).To(Equal(&reporter.SpikeHistory{
+					Spikes: []reporter.Spike{
+						{From: time.Unix(5, 0), To: time.Unix(10, 0)},
+						{From: time.Unix(20, 0), To: time.Unix(30, 0)},
+					},
+				}))
+				Expect(reports.InstanceReports[0].SpikeHistory.TotalDuration()).To(Equal(15 * time.Second))
+				Expect(reports.InstanceReports[1].SpikeHistory).To(Equal(&reporter.SpikeHistory{}))
+			})
+
+			When("there were too many spikes to read them all", func() {
+				BeforeEach(func() {
+					spikeHistoryFetcher.FetchInstanceDataReturns(map[int]interface{}{
+						0: fetchers.SpikeHistoryInstanceData{
+							InstanceID:      0,
+							Spikes:          []fetchers.Spike{{From: time.Unix(20, 0), To: time.Unix(30, 0)}},
+							TruncatedBefore: time.Unix(15, 0),
+						},
+					}, nil)
+				})
+
+				It("reports the spike history of every instance as truncated", func() {
+					Expect(reports.InstanceReports[0].SpikeHistory.TruncatedBefore).To(Equal(time.Unix(15, 0)))
+					Expect(reports.InstanceReports[1].SpikeHistory.TruncatedBefore).To(Equal(time.Unix(15, 0)))
+					Expect(reports.InstanceReports[1].SpikeHistory.IsTruncated()).To(BeTrue())
+				})
+			})
+
+			When("fetching the spike history fails", func() {
+				BeforeEach(func() {
+					spikeHistoryFetcher.FetchInstanceDataReturns(nil, errors.New("fetch-spike-history-error"))
+				})
+
+				It("returns the error", func() {
+					Expect(err).To(MatchError("fetch-spike-history-error"))
+				})
+			})
+		})
+	})
+
+	Describe("Spike window", func() {
+		var (
+			retentionFetcher *reporterfakes.FakeRetentionFetcher
+			since            time.Time
+		)
+
+		BeforeEach(func() {
+			since = time.Unix(1000, 0)
+			retentionFetcher = new(reporterfakes.FakeRetentionFetcher)
+			retentionFetcher.FetchOldestTimestampReturns(time.Unix(1010, 0), nil)
+		})
+
+		It("does not check the spike window by default", func() {
+			Expect(reports.SpikeWindow).To(Equal(reporter.SpikeWindow{}))
+		})
+
+		When("the reporter has a spike window", func() {
+			BeforeEach(func() {
+				instanceReporter = instanceReporter.WithSpikeWindow(since, retentionFetcher)
+			})
+
+			It("fetches the oldest data available for the application", func() {
+				Expect(retentionFetcher.FetchOldestTimestampCallCount()).To(Equal(1))
+				_, _, actualAppGuid, actualSince := retentionFetcher.FetchOldestTimestampArgsForCall(0)
+				Expect(actualAppGuid).To(Equal(appGuid))
+				Expect(actualSince).To(Equal(since))
+			})
+
+			It("reports that the whole window is available", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reports.SpikeWindow).To(Equal(reporter.SpikeWindow{Since: since, AvailableSince: since}))
+				Expect(reports.SpikeWindow.IsTruncated()).To(BeFalse())
+			})
+
+			When("log-cache only holds data for part of the window", func() {
+				BeforeEach(func() {
+					retentionFetcher.FetchOldestTimestampReturns(time.Unix(5000, 0), nil)
+				})
+
+				It("reports when the available data starts", func() {
+					Expect(reports.SpikeWindow).To(Equal(reporter.SpikeWindow{Since: since, AvailableSince: time.Unix(5000, 0)}))
+					Expect(reports.SpikeWindow.IsTruncated()).To(BeTrue())
+				})
+			})
+
+			When("fetching the oldest data fails", func() {
+				BeforeEach(func() {
+					retentionFetcher.FetchOldestTimestampReturns(time.Time{}, errors.New("fetch-retention-error"))
+				})
+
+				It("returns the error", func() {
+					Expect(err).To(MatchError("fetch-retention-error"))
+				})
+			})
+		})
+	})
+
+	Describe("Usage history", func() {
+		var usageHistoryFetcher *reporterfakes.FakeInstanceDataFetcher
+
+		BeforeEach(func() {
+			currentUsageFetcher.FetchInstanceDataReturns(map[int]interface{}{
+				0: fetchers.CurrentInstanceData{InstanceID: 0, Usage: 0.5},
+				1: fetchers.CurrentInstanceData{InstanceID: 1, Usage: 0.5},
+			}, nil)
+
+			usageHistoryFetcher = new(reporterfakes.FakeInstanceDataFetcher)
+			usageHistoryFetcher.FetchInstanceDataReturns(map[int]interface{}{
+				0: fetchers.UsageHistoryInstanceData{
+					InstanceID: 0,
+					Samples: []fetchers.UsageSample{
+						{Time: time.Unix(60, 0), Usage: 0.5},
+						{Time: time.Unix(120, 0), Usage: 1.5},
+					},
+				},
+			}, nil)
+		})
+
+		It("does not report usage history by default", func() {
+			Expect(reports.InstanceReports[0].UsageHistory).To(BeNil())
+		})
+
+		When("the reporter has a usage history fetcher", func() {
+			BeforeEach(func() {
+				instanceReporter = instanceReporter.WithUsageHistoryFetcher(usageHistoryFetcher)
+			})
+
+			It("fetches the usage history of the application", func() {
+				Expect(usageHistoryFetcher.FetchInstanceDataCallCount()).To(Equal(1))
+				_, _, actualAppGuid, actualAppInstances := usageHistoryFetcher.FetchInstanceDataArgsForCall(0)
+				Expect(actualAppGuid).To(Equal(appGuid))
+				Expect(actualAppInstances).To(Equal(appInstances))
+			})
+
+			It("reports the usage history of every instance", func() {
+				Expect(reports.InstanceReports).To(HaveLen(2))
+				Expect(reports.InstanceReports[0].UsageHistory).To(Equal(&reporter.UsageHistory{
+					Samples: []reporter.UsageSample{
+						{Time: time.Unix(60, 0), Value: 0.5},
+						{Time: time.Unix(120, 0), Value: 1.5},
+					},
+				}))
+				Expect(reports.InstanceReports[1].UsageHistory).To(Equal(&reporter.UsageHistory{}))
+			})
+
+			When("fetching the usage history fails", func() {
+				BeforeEach(func() {
+					usageHistoryFetcher.FetchInstanceDataReturns(nil, errors.New("fetch-usage-history-error"))
+				})
+
+				It("returns the error", func() {
+					Expect(err).To(MatchError("fetch-usage-history-error"))
+				})
+			})
+		})
+	})
+
+	Describe("Usage stats", func() {
+		var usageStatsFetcher *reporterfakes.FakeInstanceDataFetcher
+
+		BeforeEach(func() {
+			currentUsageFetcher.FetchInstanceDataReturns(map[int]interface{}{
+				0: fetchers.CurrentInstanceData{InstanceID: 0, Usage: 0.5},
+				1: fetchers.CurrentInstanceData{InstanceID: 1, Usage: 0.5},
+				2: fetchers.CurrentInstanceData{InstanceID: 2, Usage: 0.5},
+			}, nil)
+
+			var samples []fetchers.UsageSample
+			for i := 100; i > 0; i-- {
+				samples = append(samples, fetchers.UsageSample{Time: time.Unix(int64(i), 0), Usage: float64(i) / 100})
+			}
+
+			usageStatsFetcher = new(reporterfakes.FakeInstanceDataFetcher)
+			usageStatsFetcher.FetchInstanceDataReturns(map[int]interface{}{
+				0: fetchers.UsageHistoryInstanceData{InstanceID: 0, Samples: samples},
+				1: fetchers.UsageHistoryInstanceData{
+					InstanceID: 1,
+					Samples:    []fetchers.UsageSample{{Time: time.Unix(60, 0), Usage: 0.7}},
+				},
+			}, nil)
+		})
+
+		It("does not report usage stats by default", func() {
+			Expect(reports.InstanceReports[0].UsageStats).To(BeNil())
+		})
+
+		When("the reporter has a usage stats fetcher", func() {
+			BeforeEach(func() {
+				instanceReporter = instanceReporter.WithUsageStatsFetcher(usageStatsFetcher)
+			})
+
+			It("fetches the usage samples of the application", func() {
+				Expect(usageStatsFetcher.FetchInstanceDataCallCount()).To(Equal(1))
+				_, _, actualAppGuid, actualAppInstances := usageStatsFetcher.FetchInstanceDataArgsForCall(0)
+				Expect(actualAppGuid).To(Equal(appGuid))
+				Expect(actualAppInstances).To(Equal(appInstances))
+			})
+
+			It("reports the percentiles and the maximum of the samples", func() {
+				Expect(reports.InstanceReports).To(HaveLen(3))
+				Expect(reports.InstanceReports[0].UsageStats).To(Equal(&reporter.UsageStats{
+					P50: 0.5, P95: 0.95, P99: 0.99, Max: 1, SampleCount: 100,
+				}))
+				Expect(reports.InstanceReports[1].UsageStats).To(Equal(&reporter.UsageStats{
+					P50: 0.7, P95: 0.7, P99: 0.7, Max: 0.7, SampleCount: 1,
+				}))
+			})
+
+			It("reports empty stats for instances without samples", func() {
+				Expect(reports.InstanceReports[2].UsageStats).To(Equal(&reporter.UsageStats{}))
+			})
+
+			When("fetching the usage samples fails", func() {
+				BeforeEach(func() {
+					usageStatsFetcher.FetchInstanceDataReturns(nil, errors.New("fetch-usage-stats-error"))
+				})
+
+				It("returns the error", func() {
+					Expect(err).To(MatchError("fetch-usage-stats-error"))
+				})
+			})
+		})
+	})
+
+	Describe("CPU rate", func() {
+		var cpuRateFetcher *reporterfakes.FakeInstanceDataFetcher
+
+		BeforeEach(func() {
+			cpuRateFetcher = new(reporterfakes.FakeInstanceDataFetcher)
+			cpuRateFetcher.FetchInstanceDataReturns(map[int]interface{}{
+				0: fetchers.CPURateInstanceData{InstanceID: 0, Usage: 0.125, Entitlement: 0.25},
+			}, nil)
+		})
+
+		It("does not report the CPU rate by default", func() {
+			Expect(reports.InstanceReports[0].CPURate).To(BeNil())
+		})
+
+		When("the reporter has a CPU rate fetcher", func() {
+			BeforeEach(func() {
+				instanceReporter = instanceReporter.WithCPURateFetcher(cpuRateFetcher)
+			})
+
+			It("fetches the CPU rate of the application", func() {
+				Expect(cpuRateFetcher.FetchInstanceDataCallCount()).To(Equal(1))
+				_, _, actualAppGuid, actualAppInstances := cpuRateFetcher.FetchInstanceDataArgsForCall(0)
+				Expect(actualAppGuid).To(Equal(appGuid))
+				Expect(actualAppInstances).To(Equal(appInstances))
+			})
+
+			It("reports the usage and entitlement in cores", func() {
+				Expect(reports.InstanceReports[0].CPURate).To(Equal(&reporter.CPURate{Usage: 0.125, Entitlement: 0.25}))
+			})
+
+			When("the fetcher returns the wrong type of instance data", func() {
+				BeforeEach(func() {
+					cpuRateFetcher.FetchInstanceDataReturns(map[int]interface{}{0: "not-a-rate"}, nil)
+				})
+
+				It("logs the wrong type", func() {
+					Expect(reports.InstanceReports[0].CPURate).To(BeNil())
+					Expect(logger).To(gbytes.Say("cpu-rate-reporter-returned-wrong-type"))
+				})
+			})
+
+			When("fetching the CPU rate fails", func() {
+				BeforeEach(func() {
+					cpuRateFetcher.FetchInstanceDataReturns(nil, errors.New("fetch-cpu-rate-error"))
+				})
+
+				It("returns the error", func() {
+					Expect(err).To(MatchError("fetch-cpu-rate-error"))
+				})
+			})
+		})
+	})
+
+	Describe("Throttle preview", func() {
+		var throttleFetcher *reporterfakes.FakeInstanceDataFetcher
+
+		BeforeEach(func() {
+			currentUsageFetcher.FetchInstanceDataReturns(map[int]interface{}{
+				0: fetchers.CurrentInstanceData{InstanceID: 0, Usage: 0.5},
+				1: fetchers.CurrentInstanceData{InstanceID: 1, Usage: 0.5},
+			}, nil)
+
+			var samples []fetchers.UsageSample
+			for i := 0; i < 4; i++ {
+				samples = append(samples, fetchers.UsageSample{Time: time.Unix(int64(i*60), 0), Usage: 1.5})
+			}
+
+			throttleFetcher = new(reporterfakes.FakeInstanceDataFetcher)
+			throttleFetcher.FetchInstanceDataReturns(map[int]interface{}{
+				0: fetchers.UsageHistoryInstanceData{InstanceID: 0, Samples: samples},
+			}, nil)
+		})
+
+		It("does not report a throttle preview by default", func() {
+			Expect(reports.InstanceReports[0].ThrottlePreview).To(BeNil())
+		})
+
+		When("the reporter has a throttle preview", func() {
+			BeforeEach(func() {
+				instanceReporter = instanceReporter.WithThrottlePreview(throttleFetcher, reporter.ThrottleModel{Window: 2 * time.Minute, Threshold: 1})
+			})
+
+			It("fetches the usage samples of the application", func() {
+				Expect(throttleFetcher.FetchInstanceDataCallCount()).To(Equal(1))
+				_, _, actualAppGuid, _ := throttleFetcher.FetchInstanceDataArgsForCall(0)
+				Expect(actualAppGuid).To(Equal(appGuid))
+			})
+
+			It("reports when each instance would have been deprioritized", func() {
+				Expect(reports.InstanceReports[0].ThrottlePreview).To(Equal(&reporter.ThrottlePreview{
+					Window:  2 * time.Minute,
+					Periods: []reporter.Period{{From: time.Unix(120, 0), To: time.Unix(180, 0)}},
+				}))
+			})
+
+			It("reports an empty preview for instances without samples", func() {
+				Expect(reports.InstanceReports[1].ThrottlePreview).To(Equal(&reporter.ThrottlePreview{Window: 2 * time.Minute}))
+			})
+
+			When("fetching the usage samples fails", func() {
+				BeforeEach(func() {
+					throttleFetcher.FetchInstanceDataReturns(nil, errors.New("fetch-throttle-error"))
+				})
+
+				It("returns the error", func() {
+					Expect(err).To(MatchError("fetch-throttle-error"))
+				})
+			})
+		})
+	})
+
+	Describe("Recommendations", func() {
+		BeforeEach(func() {
+			appInstances = map[int]cf.Instance{0: {InstanceID: 0}, 1: {InstanceID: 1}}
+			cfClient.GetApplicationReturns(cf.Application{Name: appName, Guid: appGuid, Instances: appInstances, MemoryInMB: 512, InstanceCount: 2}, nil)
+			cumulativeUsageFetcher.FetchInstanceDataReturns(map[int]interface{}{
+				0: fetchers.CumulativeInstanceData{InstanceID: 0, Usage: 1.08},
+				1: fetchers.CumulativeInstanceData{InstanceID: 1, Usage: 0.9},
+			}, nil)
+		})
+
+		It("does not recommend anything by default", func() {
+			Expect(reports.Recommendations).To(BeEmpty())
+		})
+
+		When("the reporter makes recommendations", func() {
+			BeforeEach(func() {
+				instanceReporter = instanceReporter.WithRecommendations(0.9, nil)
+			})
+
+			It("recommends scaling based on the average usage of the busiest instance", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reports.Recommendations).To(Equal([]reporter.Recommendation{{
+					ProcessType:           "web",
+					Target:                0.9,
+					Usage:                 1.08,
+					MemoryInMB:            512,
+					RecommendedMemoryInMB: 640,
+					InstanceCount:         2,
+					RecommendedInstances:  3,
+				}}))
+			})
+
+			When("the usage stats are available", func() {
+				var usageStatsFetcher *reporterfakes.FakeInstanceDataFetcher
+
+				BeforeEach(func() {
+					usageStatsFetcher = new(reporterfakes.FakeInstanceDataFetcher)
+					usageStatsFetcher.FetchInstanceDataReturns(map[int]interface{}{
+						0: fetchers.UsageHistoryInstanceData{InstanceID: 0, Samples: []fetchers.UsageSample{{Time: time.Unix(60, 0), Usage: 1.35}}},
+						1: fetchers.UsageHistoryInstanceData{InstanceID: 1, Samples: []fetchers.UsageSample{{Time: time.Unix(60, 0), Usage: 0.45}}},
+					}, nil)
+					instanceReporter = instanceReporter.WithRecommendations(0.9, usageStatsFetcher)
+				})
+
+				It("bases the recommendation on the p95 of the busiest instance", func() {
+					Expect(reports.Recommendations).To(HaveLen(1))
+					Expect(reports.Recommendations[0].UsesP95).To(BeTrue())
+					Expect(reports.Recommendations[0].Usage).To(Equal(1.35))
+					Expect(reports.Recommendations[0].RecommendedMemoryInMB).To(BeEquivalentTo(768))
+				})
+
+				It("does not recommend more instances when the average load is below the target", func() {
+					Expect(reports.Recommendations[0].RecommendedInstances).To(Equal(2))
+				})
+
+				It("does not include the usage stats in the reports", func() {
+					for _, instanceReport := range reports.InstanceReports {
+						Expect(instanceReport.UsageStats).To(BeNil())
+					}
+				})
+
+				When("the reporter also includes the usage stats", func() {
+					BeforeEach(func() {
+						instanceReporter = instanceReporter.WithUsageStatsFetcher(usageStatsFetcher)
+					})
+
+					It("includes the usage stats in the reports", func() {
+						Expect(reports.InstanceReports[0].UsageStats).NotTo(BeNil())
+						Expect(reports.InstanceReports[0].UsageStats.P95).To(Equal(1.35))
+					})
+
+					It("fetches the usage stats only once", func() {
+						Expect(usageStatsFetcher.FetchInstanceDataCallCount()).To(Equal(1))
+					})
+				})
+			})
+
+			When("the usage is below the target", func() {
+				BeforeEach(func() {
+					cumulativeUsageFetcher.FetchInstanceDataReturns(map[int]interface{}{
+						0: fetchers.CumulativeInstanceData{InstanceID: 0, Usage: 0.9},
+						1: fetchers.CumulativeInstanceData{InstanceID: 1, Usage: 0.5},
+					}, nil)
+				})
+
+				It("does not recommend anything", func() {
+					Expect(reports.Recommendations).To(BeEmpty())
+				})
+			})
+
+			When("the memory of the app is unknown", func() {
+				BeforeEach(func() {
+					cfClient.GetApplicationReturns(cf.Application{Name: appName, Guid: appGuid, Instances: appInstances}, nil)
+				})
+
+				It("only recommends more instances", func() {
+					Expect(reports.Recommendations).To(HaveLen(1))
+					Expect(reports.Recommendations[0].RecommendedMemoryInMB).To(BeZero())
+					Expect(reports.Recommendations[0].RecommendedInstances).To(Equal(3))
+				})
+			})
+		})
+	})
+})
+
+var _ = Describe("Reporter for multiple apps", func() {
+	var (
+		cumulativeUsageFetcher *reporterfakes.FakeInstanceDataFetcher
+		currentUsageFetcher    *reporterfakes.FakeInstanceDataFetcher
+		lastSpikeFetcher       *reporterfakes.FakeInstanceDataFetcher
+		cfClient               *reporterfakes.FakeAppReporterCloudFoundryClient
+		appReporter            reporter.AppReporter
+		ctx                    context.Context
+		appNames               []string
+		reports                []reporter.ApplicationReport
+		err                    error
+	)
+
+	BeforeEach(func() {
+		cumulativeUsageFetcher = new(reporterfakes.FakeInstanceDataFetcher)
+		currentUsageFetcher = new(reporterfakes.FakeInstanceDataFetcher)
+		lastSpikeFetcher = new(reporterfakes.FakeInstanceDataFetcher)
+		cfClient = new(reporterfakes.FakeAppReporterCloudFoundryClient)
+
+		cfClient.GetApplicationStub = func(_ context.Context, logger lager.Logger, appName string) (cf.Application, error) {
+			if appName == "missing" {
+				return cf.Application{}, errors.New("app-not-found")
+			}
+			return cf.Application{Name: appName, Guid: appName + "-guid", Instances: map[int]cf.Instance{0: {InstanceID: 0}}}, nil
+		}
+		cfClient.GetApplicationNamesReturns([]string{"payments-b", "orders", "payments-a"}, nil)
+
+		currentUsageFetcher.FetchInstanceDataReturns(map[int]interface{}{
+			0: fetchers.CurrentInstanceData{InstanceID: 0, Usage: 0.5},
+		}, nil)
+
+		appReporter = reporter.NewAppReporter(cfClient, currentUsageFetcher, lastSpikeFetcher, cumulativeUsageFetcher)
+		ctx = context.Background()
+		appNames = []string{"orders", "billing"}
+	})
+
+	JustBeforeEach(func() {
+		reports, err = appReporter.CreateApplicationReports(ctx, lagertest.NewTestLogger("app-reporter-test"), appNames)
+	})
+
+	It("creates a report for each app, in order", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reports).To(HaveLen(2))
+		Expect(reports[0].ApplicationName).To(Equal("orders"))
+		Expect(reports[1].ApplicationName).To(Equal("billing"))
+	})
+
+	It("does not list the apps in the space", func() {
+		Expect(cfClient.GetApplicationNamesCallCount()).To(BeZero())
+	})
+
+	When("an app name is a pattern", func() {
+		BeforeEach(func() {
+			appNames = []string{"payments-*", "orders", "payments-a"}
+		})
+
+		It("creates a report for each matching app in the space, sorted by name", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfClient.GetApplicationNamesCallCount()).To(Equal(1))
+
+			var reportedNames []string
+			for _, report := range reports {
+				reportedNames = append(reportedNames, report.ApplicationName)
+			}
+			Expect(reportedNames).To(Equal([]string{"payments-a", "payments-b", "orders"}))
+		})
+	})
+
+	When("a pattern matches no apps", func() {
+		BeforeEach(func() {
+			appNames = []string{"orders", "shipping-*"}
+		})
+
+		It("returns a no matching apps error", func() {
+			Expect(err).To(MatchError("No apps in the targeted space match shipping-*."))
+			Expect(err).To(BeAssignableToTypeOf(reporter.NoMatchingAppsError{}))
+		})
+	})
+
+	When("a pattern is malformed", func() {
+		BeforeEach(func() {
+			appNames = []string{"payments-["}
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(ContainSubstring("Invalid app name pattern payments-[")))
+		})
+	})
+
+	When("listing the apps in the space fails", func() {
+		BeforeEach(func() {
+			appNames = []string{"payments-*"}
+			cfClient.GetApplicationNamesReturns(nil, errors.New("get-apps-error"))
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError("get-apps-error"))
+		})
+	})
+
+	When("creating one of the reports fails", func() {
+		BeforeEach(func() {
+			appNames = []string{"orders", "missing"}
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError("app-not-found"))
+		})
+
+		When("the concurrency is limited to one", func() {
+			BeforeEach(func() {
+				appNames = []string{"missing", "orders", "billing"}
+				appReporter = appReporter.WithConcurrency(1)
+			})
+
+			It("does not create the remaining reports", func() {
+				Expect(err).To(MatchError("app-not-found"))
+				Expect(cfClient.GetApplicationCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	When("the reports are created concurrently", func() {
+		BeforeEach(func() {
+			started := make(chan struct{})
+			var startedCount int32
+			currentUsageFetcher.FetchInstanceDataStub = func(_ context.Context, logger lager.Logger, appGuid string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+				if atomic.AddInt32(&startedCount, 1) == 2 {
+					close(started)
+				}
+				select {
+				case <-started:
+				case <-time.After(5 * time.Second):
+					return nil, errors.New("the reports were not created concurrently")
+				}
+				return map[int]interface{}{0: fetchers.CurrentInstanceData{InstanceID: 0, Usage: 0.5}}, nil
+			}
+			appReporter = appReporter.WithConcurrency(2)
+		})
+
+		It("keeps the reports in order", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reports[0].ApplicationName).To(Equal("orders"))
+			Expect(reports[1].ApplicationName).To(Equal("billing"))
+		})
+	})
+
+	When("the context is cancelled", func() {
+		BeforeEach(func() {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithCancel(ctx)
+			cancel()
+		})
+
+		It("does not create any report and returns the context error", func() {
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(cfClient.GetApplicationCallCount()).To(BeZero())
+		})
+	})
 })
@@ -1,51 +1,161 @@
 package reporter
 
 import (
+	"context"
 	"sort"
 
 	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/pool"
 	"code.cloudfoundry.org/lager"
 )
 
 type OEIReport struct {
 	Org          string
 	Username     string
+	Thresholds   Thresholds
 	SpaceReports []SpaceReport
 }
 
+// AllOrgsOEIReport holds an OEIReport for every org the user can see which
+// has at least one app over or near entitlement.
+type AllOrgsOEIReport struct {
+	Username   string
+	Thresholds Thresholds
+	OrgReports []OEIReport
+}
+
+// SpaceReport lists the apps of a space with an instance over entitlement in
+// Apps, and the apps with an instance near entitlement only in NearApps.
+// ThrottledApps lists the apps with an instance that would have been
+// deprioritized, whatever their tier, when the reporter has a throttle
+// preview fetcher.
 type SpaceReport struct {
-	SpaceName string
-	Apps      []string
+	SpaceName     string
+	Apps          []OEIAppReport
+	NearApps      []OEIAppReport
+	ThrottledApps []OEIAppReport
+}
+
+type OEIAppReport struct {
+	Name            string
+	Guid            string
+	InstanceCount   int
+	InstanceReports []InstanceReport
+}
+
+// InstanceReportsAbove returns the reports of the instances whose cumulative
+// usage is above the given ratio.
+func (a OEIAppReport) InstanceReportsAbove(threshold float64) []InstanceReport {
+	var reports []InstanceReport
+	for _, report := range a.InstanceReports {
+		if report.CumulativeUsage.Value > threshold {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+// DeprioritizedInstanceReports returns the reports of the instances that
+// would have been deprioritized according to their throttle preview.
+func (a OEIAppReport) DeprioritizedInstanceReports() []InstanceReport {
+	var reports []InstanceReport
+	for _, report := range a.InstanceReports {
+		if report.ThrottlePreview != nil && report.ThrottlePreview.IsDeprioritized() {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+// WorstInstanceReport returns the report of the instance with the highest
+// cumulative usage.
+func (a OEIAppReport) WorstInstanceReport() InstanceReport {
+	var worst InstanceReport
+	for i, report := range a.InstanceReports {
+		if i == 0 || report.CumulativeUsage.Value > worst.CumulativeUsage.Value {
+			worst = report
+		}
+	}
+	return worst
 }
 
 //go:generate counterfeiter . MetricsFetcher
 
 type MetricsFetcher interface {
-	FetchInstanceData(logger lager.Logger, appGuid string, appInstances map[int]cf.Instance) (map[int]interface{}, error)
+	FetchInstanceData(ctx context.Context, logger lager.Logger, appGuid string, appInstances map[int]cf.Instance) (map[int]interface{}, error)
+}
+
+//go:generate counterfeiter . BatchMetricsFetcher
+
+type BatchMetricsFetcher interface {
+	FetchAppsInstanceData(ctx context.Context, logger lager.Logger, appsInstances map[string]map[int]cf.Instance) (map[string]map[int]interface{}, error)
 }
 
 //go:generate counterfeiter . CloudFoundryClient
 
 type CloudFoundryClient interface {
-	GetSpaces(logger lager.Logger) ([]cf.Space, error)
+	GetSpaces(ctx context.Context, logger lager.Logger) ([]cf.Space, error)
 	GetCurrentOrg(logger lager.Logger) (string, error)
+	GetOrgs(ctx context.Context, logger lager.Logger) ([]cf.Org, error)
+	GetSpacesInOrg(ctx context.Context, logger lager.Logger, orgGUID string) ([]cf.Space, error)
 	Username(logger lager.Logger) (string, error)
 }
 
 type OverEntitlementInstances struct {
-	cf             CloudFoundryClient
-	metricsFetcher MetricsFetcher
+	cf                  CloudFoundryClient
+	batchMetricsFetcher BatchMetricsFetcher
+	batchSize           int
+	lastSpikeFetcher    MetricsFetcher
+	throttleFetcher     MetricsFetcher
+	throttleModel       ThrottleModel
+	thresholds          Thresholds
+	concurrency         int
 }
 
-func NewOverEntitlementInstances(cf CloudFoundryClient, metricsFetcher MetricsFetcher) OverEntitlementInstances {
+// NewOverEntitlementInstances creates a reporter which fetches the usage of
+// batchSize apps at a time with batchMetricsFetcher.
+func NewOverEntitlementInstances(cf CloudFoundryClient, batchMetricsFetcher BatchMetricsFetcher, batchSize int) OverEntitlementInstances {
 	return OverEntitlementInstances{
-		cf:             cf,
-		metricsFetcher: metricsFetcher,
+		cf:                  cf,
+		batchMetricsFetcher: batchMetricsFetcher,
+		batchSize:           batchSize,
+		thresholds:          DefaultThresholds,
+		concurrency:         pool.DefaultSize,
 	}
 }
 
-func (r OverEntitlementInstances) OverEntitlementInstances(logger lager.Logger) (OEIReport, error) {
+// WithConcurrency sets how many apps the usage of is fetched at the same
+// time.
+func (r OverEntitlementInstances) WithConcurrency(concurrency int) OverEntitlementInstances {
+	r.concurrency = concurrency
+	return r
+}
+
+// WithThresholds changes when apps are reported as over or near their
+// entitlement.
+func (r OverEntitlementInstances) WithThresholds(thresholds Thresholds) OverEntitlementInstances {
+	r.thresholds = thresholds
+	return r
+}
+
+// WithLastSpikeFetcher makes the reporter include the last spike of every
+// instance of the apps over or near entitlement.
+func (r OverEntitlementInstances) WithLastSpikeFetcher(lastSpikeFetcher MetricsFetcher) OverEntitlementInstances {
+	r.lastSpikeFetcher = lastSpikeFetcher
+	return r
+}
+
+// WithThrottlePreview makes the reporter fetch the usage history of every app
+// with usageHistoryFetcher and list the apps with an instance that the model
+// would have deprioritized.
+func (r OverEntitlementInstances) WithThrottlePreview(usageHistoryFetcher MetricsFetcher, model ThrottleModel) OverEntitlementInstances {
+	r.throttleFetcher = usageHistoryFetcher
+	r.throttleModel = model
+	return r
+}
+
+func (r OverEntitlementInstances) OverEntitlementInstances(ctx context.Context, logger lager.Logger) (OEIReport, error) {
 	logger = logger.Session("oei-reporter")
 	logger.Info("start")
 	defer logger.Info("end")
@@ -60,64 +170,251 @@ func (r OverEntitlementInstances) OverEntitlementInstances(logger lager.Logger)
 		return OEIReport{}, err
 	}
 
-	spaces, err := r.cf.GetSpaces(logger)
+	spaces, err := r.cf.GetSpaces(ctx, logger)
 	if err != nil {
 		return OEIReport{}, err
 	}
 
-	spaceReports, err := r.buildSpaceReports(logger, spaces)
+	classifiedApps, err := r.classifyApps(ctx, logger, applicationsOf(spaces))
 	if err != nil {
 		return OEIReport{}, err
 	}
 
-	return OEIReport{Org: org, Username: user, SpaceReports: spaceReports}, nil
+	return OEIReport{Org: org, Username: user, Thresholds: r.thresholds, SpaceReports: buildSpaceReports(spaces, classifiedApps)}, nil
+}
+
+func (r OverEntitlementInstances) OverEntitlementInstancesInAllOrgs(ctx context.Context, logger lager.Logger) (AllOrgsOEIReport, error) {
+	logger = logger.Session("oei-reporter-all-orgs")
+	logger.Info("start")
+	defer logger.Info("end")
+
+	user, err := r.cf.Username(logger)
+	if err != nil {
+		return AllOrgsOEIReport{}, err
+	}
+
+	orgs, err := r.cf.GetOrgs(ctx, logger)
+	if err != nil {
+		return AllOrgsOEIReport{}, err
+	}
+
+	orgsSpaces := make([][]cf.Space, len(orgs))
+	err = pool.Run(ctx, r.concurrency, len(orgs), func(ctx context.Context, i int) error {
+		var err error
+		orgsSpaces[i], err = r.cf.GetSpacesInOrg(ctx, logger, orgs[i].Guid)
+		return err
+	})
+	if err != nil {
+		return AllOrgsOEIReport{}, err
+	}
+
+	// The apps of all the orgs are classified together, so that the batches
+	// of apps whose usage is fetched at once are not cut at org boundaries.
+	var spaces []cf.Space
+	for _, orgSpaces := range orgsSpaces {
+		spaces = append(spaces, orgSpaces...)
+	}
+	classifiedApps, err := r.classifyApps(ctx, logger, applicationsOf(spaces))
+	if err != nil {
+		return AllOrgsOEIReport{}, err
+	}
+
+	orgReports := []OEIReport{}
+	for i, org := range orgs {
+		orgAppCount := len(applicationsOf(orgsSpaces[i]))
+		spaceReports := buildSpaceReports(orgsSpaces[i], classifiedApps[:orgAppCount])
+		classifiedApps = classifiedApps[orgAppCount:]
+
+		if len(spaceReports) == 0 {
+			continue
+		}
+		orgReports = append(orgReports, OEIReport{Org: org.Name, Username: user, Thresholds: r.thresholds, SpaceReports: spaceReports})
+	}
+
+	sort.Slice(orgReports, func(i, j int) bool {
+		return orgReports[i].Org < orgReports[j].Org
+	})
+
+	return AllOrgsOEIReport{Username: user, Thresholds: r.thresholds, OrgReports: orgReports}, nil
+}
+
+type entitlementTier int
+
+const (
+	withinEntitlement entitlementTier = iota
+	nearEntitlement
+	overEntitlement
+)
+
+type classifiedApp struct {
+	report        OEIAppReport
+	tier          entitlementTier
+	deprioritized bool
+}
+
+func applicationsOf(spaces []cf.Space) []cf.Application {
+	var applications []cf.Application
+	for _, space := range spaces {
+		applications = append(applications, space.Applications...)
+	}
+	return applications
+}
+
+// classifyApps fetches the usage of the apps using up to the configured
+// number of concurrent fetches, and classifies them in the same order.
+func (r OverEntitlementInstances) classifyApps(ctx context.Context, logger lager.Logger, applications []cf.Application) ([]classifiedApp, error) {
+	appsUsages, err := r.fetchBatchedUsages(ctx, logger, applications)
+	if err != nil {
+		return nil, err
+	}
+
+	classifiedApps := make([]classifiedApp, len(applications))
+	err = pool.Run(ctx, r.concurrency, len(applications), func(ctx context.Context, i int) error {
+		var err error
+		classifiedApps[i], err = r.classifyApp(ctx, logger, applications[i], appsUsages[i])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return classifiedApps, nil
 }
 
-func (r OverEntitlementInstances) buildSpaceReports(logger lager.Logger, spaces []cf.Space) ([]SpaceReport, error) {
+// buildSpaceReports groups the apps over or near entitlement by space, given
+// the classified apps of all the spaces in the same order.
+func buildSpaceReports(spaces []cf.Space, classifiedApps []classifiedApp) []SpaceReport {
 	spaceReports := []SpaceReport{}
 	for _, space := range spaces {
-		apps, err := r.filterApps(logger, space.Applications)
-		if err != nil {
-			return nil, err
+		apps := []OEIAppReport{}
+		nearApps := []OEIAppReport{}
+		var throttledApps []OEIAppReport
+		for _, classified := range classifiedApps[:len(space.Applications)] {
+			switch classified.tier {
+			case overEntitlement:
+				apps = append(apps, classified.report)
+			case nearEntitlement:
+				nearApps = append(nearApps, classified.report)
+			}
+			if classified.deprioritized {
+				throttledApps = append(throttledApps, classified.report)
+			}
 		}
+		classifiedApps = classifiedApps[len(space.Applications):]
 
-		if len(apps) == 0 {
+		if len(apps) == 0 && len(nearApps) == 0 && len(throttledApps) == 0 {
 			continue
 		}
-		sort.Strings(apps)
-		spaceReports = append(spaceReports, SpaceReport{SpaceName: space.Name, Apps: apps})
+		sortOEIAppReports(apps)
+		sortOEIAppReports(nearApps)
+		sortOEIAppReports(throttledApps)
+		spaceReports = append(spaceReports, SpaceReport{SpaceName: space.Name, Apps: apps, NearApps: nearApps, ThrottledApps: throttledApps})
 	}
 
 	sort.Slice(spaceReports, func(i, j int) bool {
 		return spaceReports[i].SpaceName < spaceReports[j].SpaceName
 	})
 
-	return spaceReports, nil
+	return spaceReports
 }
 
-func (r OverEntitlementInstances) filterApps(logger lager.Logger, spaceApps []cf.Application) ([]string, error) {
-	apps := []string{}
-	for _, app := range spaceApps {
-		isOverEntitlement, err := r.isOverEntitlement(logger, app.Guid, app.Instances)
+func sortOEIAppReports(apps []OEIAppReport) {
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].Name < apps[j].Name
+	})
+}
+
+// fetchBatchedUsages fetches the usage of the apps in batches of the
+// configured size, using up to the configured number of concurrent fetches.
+// The usages are returned in the same order as the apps.
+func (r OverEntitlementInstances) fetchBatchedUsages(ctx context.Context, logger lager.Logger, applications []cf.Application) ([]map[int]interface{}, error) {
+	logger = logger.Session("fetch-batched-usages", lager.Data{"app-count": len(applications), "batch-size": r.batchSize})
+
+	appsUsages := make([]map[int]interface{}, len(applications))
+	batchCount := (len(applications) + r.batchSize - 1) / r.batchSize
+	err := pool.Run(ctx, r.concurrency, batchCount, func(ctx context.Context, batch int) error {
+		start := batch * r.batchSize
+		end := start + r.batchSize
+		if end > len(applications) {
+			end = len(applications)
+		}
+
+		appsInstances := map[string]map[int]cf.Instance{}
+		for _, app := range applications[start:end] {
+			appsInstances[app.Guid] = app.Instances
+		}
+
+		batchUsages, err := r.batchMetricsFetcher.FetchAppsInstanceData(ctx, logger, appsInstances)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		if isOverEntitlement {
-			apps = append(apps, app.Name)
+
+		for i := start; i < end; i++ {
+			appsUsages[i] = batchUsages[applications[i].Guid]
+			if appsUsages[i] == nil {
+				appsUsages[i] = map[int]interface{}{}
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return apps, nil
+
+	return appsUsages, nil
 }
 
-func (r OverEntitlementInstances) isOverEntitlement(logger lager.Logger, appGuid string, appInstances map[int]cf.Instance) (bool, error) {
-	logger = logger.Session("is-over-entitlement", lager.Data{"app-guid": appGuid})
-	appInstancesUsages, err := r.metricsFetcher.FetchInstanceData(logger, appGuid, appInstances)
-	if err != nil {
-		return false, err
+// classifyApp tells whether the app has an instance over entitlement, or an
+// instance near entitlement only, given the usage of its instances, and
+// whether it has an instance that would have been deprioritized.
+func (r OverEntitlementInstances) classifyApp(ctx context.Context, logger lager.Logger, app cf.Application, appUsages map[int]interface{}) (classifiedApp, error) {
+	instanceReports := r.buildInstanceReports(logger, app.Guid, appUsages)
+
+	deprioritized := false
+	if r.throttleFetcher != nil {
+		var err error
+		instanceReports, err = r.addThrottlePreviews(ctx, logger, app, instanceReports)
+		if err != nil {
+			return classifiedApp{}, err
+		}
+		deprioritized = len(OEIAppReport{InstanceReports: instanceReports}.DeprioritizedInstanceReports()) > 0
+	}
+
+	var tier entitlementTier
+	switch {
+	case r.isOverEntitlement(instanceReports):
+		tier = overEntitlement
+	case r.isNearEntitlement(instanceReports):
+		tier = nearEntitlement
+	case !deprioritized:
+		return classifiedApp{tier: withinEntitlement}, nil
 	}
 
-	isOverEntitlement := false
-	for _, instanceData := range appInstancesUsages {
+	if r.lastSpikeFetcher != nil {
+		var err error
+		instanceReports, err = r.addLastSpikes(ctx, logger, app.Guid, app.Instances, instanceReports)
+		if err != nil {
+			return classifiedApp{}, err
+		}
+	}
+
+	return classifiedApp{
+		report: OEIAppReport{
+			Name:            app.Name,
+			Guid:            app.Guid,
+			InstanceCount:   len(app.Instances),
+			InstanceReports: instanceReports,
+		},
+		tier:          tier,
+		deprioritized: deprioritized,
+	}, nil
+}
+
+func (r OverEntitlementInstances) buildInstanceReports(logger lager.Logger, appGuid string, appInstancesUsages map[int]interface{}) []InstanceReport {
+	logger = logger.Session("build-instance-reports", lager.Data{"app-guid": appGuid})
+
+	reports := map[int]InstanceReport{}
+	for instanceID, instanceData := range appInstancesUsages {
 		cumulativeInstanceData, ok := instanceData.(fetchers.CumulativeInstanceData)
 		if !ok {
 			logger.Info("metrics-fetcher-returned-wrong-type",
@@ -125,10 +422,82 @@ func (r OverEntitlementInstances) isOverEntitlement(logger lager.Logger, appGuid
 			continue
 		}
 
-		if cumulativeInstanceData.Usage > 1 {
-			isOverEntitlement = true
+		reports[instanceID] = InstanceReport{
+			InstanceID:      instanceID,
+			CumulativeUsage: CumulativeUsage{Value: cumulativeInstanceData.Usage},
+		}
+	}
+
+	return buildReportsSlice(reports)
+}
+
+func (r OverEntitlementInstances) addLastSpikes(ctx context.Context, logger lager.Logger, appGuid string, appInstances map[int]cf.Instance, instanceReports []InstanceReport) ([]InstanceReport, error) {
+	logger = logger.Session("add-last-spikes", lager.Data{"app-guid": appGuid})
+	lastSpikePerInstance, err := r.lastSpikeFetcher.FetchInstanceData(ctx, logger, appGuid, appInstances)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, report := range instanceReports {
+		data, ok := lastSpikePerInstance[report.InstanceID]
+		if !ok {
+			continue
+		}
+
+		lastSpikeInstanceData, ok := data.(fetchers.LastSpikeInstanceData)
+		if !ok {
+			logger.Info("last-spike-fetcher-returned-wrong-type",
+				lager.Data{"instance-data": data})
+			continue
+		}
+
+		instanceReports[i].LastSpike = LastSpike{From: lastSpikeInstanceData.From, To: lastSpikeInstanceData.To}
+	}
+
+	return instanceReports, nil
+}
+
+// addThrottlePreviews adds the throttle preview of every instance of the app,
+// including the instances without a cumulative usage.
+func (r OverEntitlementInstances) addThrottlePreviews(ctx context.Context, logger lager.Logger, app cf.Application, instanceReports []InstanceReport) ([]InstanceReport, error) {
+	logger = logger.Session("add-throttle-previews", lager.Data{"app-guid": app.Guid})
+	samplesPerInstance, err := fetchUsageSamples(ctx, logger, r.throttleFetcher, cf.Process{Guid: app.Guid, Instances: app.Instances})
+	if err != nil {
+		return nil, err
+	}
+
+	reports := map[int]InstanceReport{}
+	for _, report := range instanceReports {
+		report.ThrottlePreview = &ThrottlePreview{Window: r.throttleModel.Window}
+		reports[report.InstanceID] = report
+	}
+
+	for instanceID, samples := range samplesPerInstance {
+		throttlePreview := r.throttleModel.Preview(samples)
+		report := getOrCreateInstanceReport(reports, instanceID)
+		report.ThrottlePreview = &throttlePreview
+		reports[instanceID] = report
+	}
+
+	return buildReportsSlice(reports), nil
+}
+
+func (r OverEntitlementInstances) isOverEntitlement(instanceReports []InstanceReport) bool {
+	for _, report := range instanceReports {
+		if r.thresholds.IsOver(report.CumulativeUsage.Value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r OverEntitlementInstances) isNearEntitlement(instanceReports []InstanceReport) bool {
+	for _, report := range instanceReports {
+		if r.thresholds.IsNear(report.CumulativeUsage.Value) {
+			return true
 		}
 	}
 
-	return isOverEntitlement, nil
+	return false
 }
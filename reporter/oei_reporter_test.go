@@ -1,7 +1,10 @@
 package reporter_test
 
 import (
+	"context"
 	"errors"
+	"sync/atomic"
+	"time"
 
 	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
 	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
@@ -19,14 +22,18 @@ var _ = Describe("Over-entitlement Instances Reporter", func() {
 		oeiReporter        reporter.OverEntitlementInstances
 		fakeCfClient       *reporterfakes.FakeCloudFoundryClient
 		fakeMetricsFetcher *reporterfakes.FakeMetricsFetcher
+		fakeBatchFetcher   *reporterfakes.FakeBatchMetricsFetcher
 		report             reporter.OEIReport
 		logger             lager.Logger
 		err                error
+		ctx                context.Context
 	)
 
 	BeforeEach(func() {
 		fakeCfClient = new(reporterfakes.FakeCloudFoundryClient)
 		fakeMetricsFetcher = new(reporterfakes.FakeMetricsFetcher)
+		fakeBatchFetcher = new(reporterfakes.FakeBatchMetricsFetcher)
+		fakeBatchFetcher.FetchAppsInstanceDataStub = fetchEachApp(fakeMetricsFetcher)
 
 		fakeCfClient.GetCurrentOrgReturns("org", nil)
 		fakeCfClient.UsernameReturns("user", nil)
@@ -34,7 +41,10 @@ var _ = Describe("Over-entitlement Instances Reporter", func() {
 			{
 				Name: "space1",
 				Applications: []cf.Application{
-					{Name: "app1", Guid: "space1-app1-guid"},
+					{Name: "app1", Guid: "space1-app1-guid", Instances: map[int]cf.Instance{
+						0: {InstanceID: 0, ProcessInstanceID: "space1-app1-0"},
+						1: {InstanceID: 1, ProcessInstanceID: "space1-app1-1"},
+					}},
 					{Name: "app2", Guid: "space1-app2-guid"},
 				},
 			},
@@ -46,7 +56,7 @@ var _ = Describe("Over-entitlement Instances Reporter", func() {
 			},
 		}, nil)
 
-		fakeMetricsFetcher.FetchInstanceDataStub = func(logger lager.Logger, appGuid string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+		fakeMetricsFetcher.FetchInstanceDataStub = func(_ context.Context, logger lager.Logger, appGuid string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
 			switch appGuid {
 			case "space1-app1-guid":
 				return map[int]interface{}{
@@ -68,11 +78,12 @@ var _ = Describe("Over-entitlement Instances Reporter", func() {
 
 		logger = lagertest.NewTestLogger("oei-reporter-test")
 
-		oeiReporter = reporter.NewOverEntitlementInstances(fakeCfClient, fakeMetricsFetcher)
+		oeiReporter = reporter.NewOverEntitlementInstances(fakeCfClient, fakeBatchFetcher, 1)
+		ctx = context.Background()
 	})
 
 	JustBeforeEach(func() {
-		report, err = oeiReporter.OverEntitlementInstances(logger)
+		report, err = oeiReporter.OverEntitlementInstances(ctx, logger)
 	})
 
 	It("succeeds", func() {
@@ -86,14 +97,24 @@ var _ = Describe("Over-entitlement Instances Reporter", func() {
 
 	It("returns all instances that are over entitlement", func() {
 		Expect(report).To(Equal(reporter.OEIReport{
-			Org:      "org",
-			Username: "user",
+			Org:        "org",
+			Username:   "user",
+			Thresholds: reporter.DefaultThresholds,
 			SpaceReports: []reporter.SpaceReport{
 				reporter.SpaceReport{
 					SpaceName: "space1",
-					Apps: []string{
-						"app1",
+					Apps: []reporter.OEIAppReport{
+						{
+							Name:          "app1",
+							Guid:          "space1-app1-guid",
+							InstanceCount: 2,
+							InstanceReports: []reporter.InstanceReport{
+								{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 1.5}},
+								{InstanceID: 1, CumulativeUsage: reporter.CumulativeUsage{Value: 0.5}},
+							},
+						},
 					},
+					NearApps: []reporter.OEIAppReport{},
 				},
 			},
 		}))
@@ -141,7 +162,7 @@ var _ = Describe("Over-entitlement Instances Reporter", func() {
 
 	When("the fetcher returns the wrong type", func() {
 		BeforeEach(func() {
-			fakeMetricsFetcher.FetchInstanceDataStub = func(_ lager.Logger, appGuid string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+			fakeMetricsFetcher.FetchInstanceDataStub = func(_ context.Context, _ lager.Logger, appGuid string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
 				switch appGuid {
 				case "space1-app1-guid":
 					return map[int]interface{}{
@@ -160,7 +181,7 @@ var _ = Describe("Over-entitlement Instances Reporter", func() {
 		It("skips the instance with the wrong type", func() {
 			Expect(len(report.SpaceReports)).To(Equal(1))
 			Expect(len(report.SpaceReports[0].Apps)).To(Equal(1))
-			Expect(report.SpaceReports[0].Apps[0]).To(Equal("app2"))
+			Expect(report.SpaceReports[0].Apps[0].Name).To(Equal("app2"))
 		})
 
 		It("logs the wrong type", func() {
@@ -221,8 +242,550 @@ var _ = Describe("Over-entitlement Instances Reporter", func() {
 		It("reports sorted apps in the report", func() {
 			Expect(len(report.SpaceReports)).To(Equal(1))
 			Expect(len(report.SpaceReports[0].Apps)).To(Equal(2))
-			Expect(report.SpaceReports[0].Apps[0]).To(Equal("app1"))
-			Expect(report.SpaceReports[0].Apps[1]).To(Equal("app2"))
+			Expect(report.SpaceReports[0].Apps[0].Name).To(Equal("app1"))
+			Expect(report.SpaceReports[0].Apps[1].Name).To(Equal("app2"))
 		})
 	})
+	It("passes the context to the cf client", func() {
+		actualCtx, _ := fakeCfClient.GetSpacesArgsForCall(0)
+		Expect(actualCtx).To(Equal(ctx))
+	})
+
+	When("the usage of several apps is fetched concurrently", func() {
+		BeforeEach(func() {
+			started := make(chan struct{})
+			var startedCount int32
+			fakeMetricsFetcher.FetchInstanceDataStub = func(_ context.Context, logger lager.Logger, appGuid string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+				if atomic.AddInt32(&startedCount, 1) == 3 {
+					close(started)
+				}
+				select {
+				case <-started:
+				case <-time.After(5 * time.Second):
+					return nil, errors.New("the fetches did not run concurrently")
+				}
+				return map[int]interface{}{0: fetchers.CumulativeInstanceData{Usage: 1.5}}, nil
+			}
+			oeiReporter = oeiReporter.WithConcurrency(3)
+		})
+
+		It("keeps the report sorted", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.SpaceReports).To(HaveLen(2))
+			Expect(report.SpaceReports[0].SpaceName).To(Equal("space1"))
+			Expect(report.SpaceReports[0].Apps).To(HaveLen(2))
+			Expect(report.SpaceReports[0].Apps[0].Guid).To(Equal("space1-app1-guid"))
+			Expect(report.SpaceReports[0].Apps[1].Guid).To(Equal("space1-app2-guid"))
+			Expect(report.SpaceReports[1].Apps[0].Guid).To(Equal("space2-app1-guid"))
+		})
+	})
+
+	When("the concurrency is limited to one", func() {
+		BeforeEach(func() {
+			oeiReporter = oeiReporter.WithConcurrency(1)
+		})
+
+		It("fetches the usage of every app", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeMetricsFetcher.FetchInstanceDataCallCount()).To(Equal(3))
+			Expect(report.SpaceReports).To(HaveLen(1))
+		})
+	})
+
+	When("the context is cancelled", func() {
+		BeforeEach(func() {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithCancel(ctx)
+			cancel()
+		})
+
+		It("does not fetch any usage and returns the context error", func() {
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(fakeMetricsFetcher.FetchInstanceDataCallCount()).To(Equal(0))
+		})
+	})
+
+	When("an app is near entitlement", func() {
+		BeforeEach(func() {
+			fakeMetricsFetcher.FetchInstanceDataStub = func(_ context.Context, logger lager.Logger, appGuid string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+				switch appGuid {
+				case "space1-app1-guid":
+					return map[int]interface{}{0: fetchers.CumulativeInstanceData{Usage: 1.5}}, nil
+				case "space2-app1-guid":
+					return map[int]interface{}{0: fetchers.CumulativeInstanceData{Usage: 0.97}}, nil
+				}
+				return map[int]interface{}{0: fetchers.CumulativeInstanceData{Usage: 0.3}}, nil
+			}
+		})
+
+		It("reports it in the near tier", func() {
+			Expect(report.SpaceReports).To(HaveLen(2))
+			Expect(report.SpaceReports[0].Apps).To(HaveLen(1))
+			Expect(report.SpaceReports[0].NearApps).To(BeEmpty())
+			Expect(report.SpaceReports[1].SpaceName).To(Equal("space2"))
+			Expect(report.SpaceReports[1].Apps).To(BeEmpty())
+			Expect(report.SpaceReports[1].NearApps).To(Equal([]reporter.OEIAppReport{
+				{
+					Name:            "app1",
+					Guid:            "space2-app1-guid",
+					InstanceReports: []reporter.InstanceReport{{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 0.97}}},
+				},
+			}))
+		})
+
+		When("the thresholds are changed", func() {
+			BeforeEach(func() {
+				oeiReporter = oeiReporter.WithThresholds(reporter.Thresholds{Over: 0.9, Near: 0.2})
+			})
+
+			It("uses them to decide the tiers", func() {
+				Expect(report.Thresholds).To(Equal(reporter.Thresholds{Over: 0.9, Near: 0.2}))
+				Expect(report.SpaceReports[0].Apps).To(HaveLen(1))
+				Expect(report.SpaceReports[0].NearApps).To(HaveLen(1))
+				Expect(report.SpaceReports[0].NearApps[0].Guid).To(Equal("space1-app2-guid"))
+				Expect(report.SpaceReports[1].Apps).To(HaveLen(1))
+				Expect(report.SpaceReports[1].Apps[0].Guid).To(Equal("space2-app1-guid"))
+			})
+		})
+	})
+
+	When("a last spike fetcher is configured", func() {
+		var (
+			fakeLastSpikeFetcher *reporterfakes.FakeMetricsFetcher
+			spikeFrom            time.Time
+			spikeTo              time.Time
+		)
+
+		BeforeEach(func() {
+			spikeFrom = time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+			spikeTo = time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC)
+
+			fakeLastSpikeFetcher = new(reporterfakes.FakeMetricsFetcher)
+			fakeLastSpikeFetcher.FetchInstanceDataReturns(map[int]interface{}{
+				0: fetchers.LastSpikeInstanceData{InstanceID: 0, From: spikeFrom, To: spikeTo},
+				1: "not-a-spike",
+			}, nil)
+
+			oeiReporter = oeiReporter.WithLastSpikeFetcher(fakeLastSpikeFetcher)
+		})
+
+		It("fetches the last spikes of the apps over entitlement only", func() {
+			Expect(fakeLastSpikeFetcher.FetchInstanceDataCallCount()).To(Equal(1))
+			_, _, appGuid, _ := fakeLastSpikeFetcher.FetchInstanceDataArgsForCall(0)
+			Expect(appGuid).To(Equal("space1-app1-guid"))
+		})
+
+		It("adds the last spike to the instance reports", func() {
+			instanceReports := report.SpaceReports[0].Apps[0].InstanceReports
+			Expect(instanceReports[0].LastSpike).To(Equal(reporter.LastSpike{From: spikeFrom, To: spikeTo}))
+			Expect(instanceReports[1].LastSpike).To(Equal(reporter.LastSpike{}))
+		})
+
+		It("logs the wrong type", func() {
+			Expect(logger).To(gbytes.Say("last-spike-fetcher-returned-wrong-type"))
+		})
+
+		When("fetching the last spikes fails", func() {
+			BeforeEach(func() {
+				fakeLastSpikeFetcher.FetchInstanceDataReturns(nil, errors.New("last-spike-error"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("last-spike-error"))
+			})
+		})
+	})
+
+	When("a throttle preview fetcher is configured", func() {
+		var (
+			fakeUsageHistoryFetcher *reporterfakes.FakeMetricsFetcher
+			start                   time.Time
+		)
+
+		BeforeEach(func() {
+			start = time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+			history := func(values ...float64) fetchers.UsageHistoryInstanceData {
+				var samples []fetchers.UsageSample
+				for i, value := range values {
+					samples = append(samples, fetchers.UsageSample{Time: start.Add(time.Duration(i) * time.Minute), Usage: value})
+				}
+				return fetchers.UsageHistoryInstanceData{Samples: samples}
+			}
+
+			fakeUsageHistoryFetcher = new(reporterfakes.FakeMetricsFetcher)
+			fakeUsageHistoryFetcher.FetchInstanceDataStub = func(_ context.Context, logger lager.Logger, appGuid string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+				if appGuid == "space2-app1-guid" {
+					return map[int]interface{}{
+						0: history(0.5, 2, 2, 2),
+					}, nil
+				}
+				return map[int]interface{}{
+					0: history(0.5, 0.5, 0.5, 0.5),
+				}, nil
+			}
+
+			oeiReporter = oeiReporter.WithThrottlePreview(fakeUsageHistoryFetcher, reporter.ThrottleModel{Window: 2 * time.Minute, Threshold: 1})
+		})
+
+		It("fetches the usage history of every app", func() {
+			Expect(fakeUsageHistoryFetcher.FetchInstanceDataCallCount()).To(Equal(3))
+		})
+
+		It("lists the apps that would have been deprioritized, whatever their tier", func() {
+			Expect(report.SpaceReports).To(HaveLen(2))
+			Expect(report.SpaceReports[0].Apps).To(HaveLen(1))
+			Expect(report.SpaceReports[0].ThrottledApps).To(BeEmpty())
+
+			Expect(report.SpaceReports[1].SpaceName).To(Equal("space2"))
+			Expect(report.SpaceReports[1].Apps).To(BeEmpty())
+			Expect(report.SpaceReports[1].ThrottledApps).To(HaveLen(1))
+			throttledApp := report.SpaceReports[1].ThrottledApps[0]
+			Expect(throttledApp.Guid).To(Equal("space2-app1-guid"))
+			Expect(throttledApp.DeprioritizedInstanceReports()).To(HaveLen(1))
+			Expect(*throttledApp.InstanceReports[0].ThrottlePreview).To(Equal(reporter.ThrottlePreview{
+				Window:  2 * time.Minute,
+				Periods: []reporter.Period{{From: start.Add(2 * time.Minute), To: start.Add(3 * time.Minute)}},
+			}))
+		})
+
+		It("adds a throttle preview to the instances without usage history", func() {
+			instanceReports := report.SpaceReports[0].Apps[0].InstanceReports
+			Expect(instanceReports[1].ThrottlePreview).To(Equal(&reporter.ThrottlePreview{Window: 2 * time.Minute}))
+		})
+
+		When("fetching the usage history fails", func() {
+			BeforeEach(func() {
+				fakeUsageHistoryFetcher.FetchInstanceDataStub = nil
+				fakeUsageHistoryFetcher.FetchInstanceDataReturns(nil, errors.New("history-error"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("history-error"))
+			})
+		})
+	})
+
+	When("the usage of several apps is fetched at once", func() {
+		BeforeEach(func() {
+			oeiReporter = reporter.NewOverEntitlementInstances(fakeCfClient, fakeBatchFetcher, 2).WithConcurrency(1)
+		})
+
+		It("fetches the usage of the apps in batches", func() {
+			Expect(fakeBatchFetcher.FetchAppsInstanceDataCallCount()).To(Equal(2))
+			_, _, firstBatch := fakeBatchFetcher.FetchAppsInstanceDataArgsForCall(0)
+			Expect(firstBatch).To(HaveLen(2))
+			Expect(firstBatch).To(HaveKeyWithValue("space1-app1-guid", map[int]cf.Instance{
+				0: {InstanceID: 0, ProcessInstanceID: "space1-app1-0"},
+				1: {InstanceID: 1, ProcessInstanceID: "space1-app1-1"},
+			}))
+			Expect(firstBatch).To(HaveKey("space1-app2-guid"))
+			_, _, secondBatch := fakeBatchFetcher.FetchAppsInstanceDataArgsForCall(1)
+			Expect(secondBatch).To(HaveLen(1))
+			Expect(secondBatch).To(HaveKey("space2-app1-guid"))
+		})
+
+		It("reports the same instances", func() {
+			Expect(report.SpaceReports).To(HaveLen(1))
+			Expect(report.SpaceReports[0].Apps).To(HaveLen(1))
+			Expect(report.SpaceReports[0].Apps[0].Guid).To(Equal("space1-app1-guid"))
+			Expect(report.SpaceReports[0].Apps[0].InstanceReports).To(HaveLen(2))
+		})
+
+		When("the batch omits an app", func() {
+			BeforeEach(func() {
+				fakeBatchFetcher.FetchAppsInstanceDataStub = nil
+				fakeBatchFetcher.FetchAppsInstanceDataReturns(map[string]map[int]interface{}{}, nil)
+			})
+
+			It("considers it within entitlement", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.SpaceReports).To(BeEmpty())
+			})
+		})
+
+		When("fetching a batch fails", func() {
+			BeforeEach(func() {
+				fakeBatchFetcher.FetchAppsInstanceDataStub = nil
+				fakeBatchFetcher.FetchAppsInstanceDataReturns(nil, errors.New("batch-error"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("batch-error"))
+			})
+		})
+	})
+})
+
+var _ = Describe("OEIAppReport", func() {
+	var appReport reporter.OEIAppReport
+
+	BeforeEach(func() {
+		appReport = reporter.OEIAppReport{
+			InstanceReports: []reporter.InstanceReport{
+				{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 1.2}},
+				{InstanceID: 1, CumulativeUsage: reporter.CumulativeUsage{Value: 0.5}},
+				{InstanceID: 2, CumulativeUsage: reporter.CumulativeUsage{Value: 1.8}},
+			},
+		}
+	})
+
+	It("returns the instances above a threshold", func() {
+		Expect(appReport.InstanceReportsAbove(1)).To(Equal([]reporter.InstanceReport{
+			{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 1.2}},
+			{InstanceID: 2, CumulativeUsage: reporter.CumulativeUsage{Value: 1.8}},
+		}))
+	})
+
+	It("returns the worst instance", func() {
+		Expect(appReport.WorstInstanceReport()).To(Equal(reporter.InstanceReport{InstanceID: 2, CumulativeUsage: reporter.CumulativeUsage{Value: 1.8}}))
+	})
 })
+
+var _ = Describe("Over-entitlement Instances Reporter for all orgs", func() {
+	var (
+		oeiReporter        reporter.OverEntitlementInstances
+		fakeCfClient       *reporterfakes.FakeCloudFoundryClient
+		fakeMetricsFetcher *reporterfakes.FakeMetricsFetcher
+		fakeBatchFetcher   *reporterfakes.FakeBatchMetricsFetcher
+		report             reporter.AllOrgsOEIReport
+		logger             lager.Logger
+		err                error
+	)
+
+	BeforeEach(func() {
+		fakeCfClient = new(reporterfakes.FakeCloudFoundryClient)
+		fakeMetricsFetcher = new(reporterfakes.FakeMetricsFetcher)
+		fakeBatchFetcher = new(reporterfakes.FakeBatchMetricsFetcher)
+		fakeBatchFetcher.FetchAppsInstanceDataStub = fetchEachApp(fakeMetricsFetcher)
+
+		fakeCfClient.UsernameReturns("user", nil)
+		fakeCfClient.GetOrgsReturns([]cf.Org{
+			{Name: "org2", Guid: "org2-guid"},
+			{Name: "org1", Guid: "org1-guid"},
+			{Name: "org3", Guid: "org3-guid"},
+		}, nil)
+		fakeCfClient.GetSpacesInOrgStub = func(ctx context.Context, logger lager.Logger, orgGUID string) ([]cf.Space, error) {
+			return []cf.Space{
+				{
+					Name: "space1",
+					Applications: []cf.Application{
+						{Name: "app1", Guid: orgGUID + "-app1-guid", Instances: map[int]cf.Instance{
+							0: {InstanceID: 0, ProcessInstanceID: orgGUID + "-app1-0"},
+						}},
+					},
+				},
+			}, nil
+		}
+
+		fakeMetricsFetcher.FetchInstanceDataStub = func(_ context.Context, logger lager.Logger, appGuid string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+			switch appGuid {
+			case "org1-guid-app1-guid":
+				return map[int]interface{}{0: fetchers.CumulativeInstanceData{Usage: 1.5}}, nil
+			case "org2-guid-app1-guid":
+				return map[int]interface{}{0: fetchers.CumulativeInstanceData{Usage: 2.5}}, nil
+			}
+
+			return map[int]interface{}{0: fetchers.CumulativeInstanceData{Usage: 0.5}}, nil
+		}
+
+		logger = lagertest.NewTestLogger("oei-reporter-test")
+
+		oeiReporter = reporter.NewOverEntitlementInstances(fakeCfClient, fakeBatchFetcher, 1)
+	})
+
+	JustBeforeEach(func() {
+		report, err = oeiReporter.OverEntitlementInstancesInAllOrgs(context.Background(), logger)
+	})
+
+	It("succeeds", func() {
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("logs start and end of function", func() {
+		Expect(logger).To(gbytes.Say("oei-reporter-all-orgs.start"))
+		Expect(logger).To(gbytes.Say("oei-reporter-all-orgs.end"))
+	})
+
+	It("fetches the spaces of every org", func() {
+		Expect(fakeCfClient.GetSpacesInOrgCallCount()).To(Equal(3))
+		var orgGUIDs []string
+		for i := 0; i < fakeCfClient.GetSpacesInOrgCallCount(); i++ {
+			_, _, orgGUID := fakeCfClient.GetSpacesInOrgArgsForCall(i)
+			orgGUIDs = append(orgGUIDs, orgGUID)
+		}
+		Expect(orgGUIDs).To(ConsistOf("org1-guid", "org2-guid", "org3-guid"))
+	})
+
+	When("the spaces of several orgs are fetched at the same time", func() {
+		BeforeEach(func() {
+			getSpacesInOrg := fakeCfClient.GetSpacesInOrgStub
+			started := make(chan struct{})
+			var startedCount int32
+			fakeCfClient.GetSpacesInOrgStub = func(ctx context.Context, logger lager.Logger, orgGUID string) ([]cf.Space, error) {
+				if atomic.AddInt32(&startedCount, 1) == 3 {
+					close(started)
+				}
+				select {
+				case <-started:
+				case <-time.After(5 * time.Second):
+					return nil, errors.New("the orgs were not listed concurrently")
+				}
+				return getSpacesInOrg(ctx, logger, orgGUID)
+			}
+			oeiReporter = oeiReporter.WithConcurrency(3)
+		})
+
+		It("keeps the report sorted", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.OrgReports).To(HaveLen(2))
+			Expect(report.OrgReports[0].Org).To(Equal("org1"))
+			Expect(report.OrgReports[1].Org).To(Equal("org2"))
+		})
+	})
+
+	When("the usage of several apps is fetched at once", func() {
+		BeforeEach(func() {
+			oeiReporter = reporter.NewOverEntitlementInstances(fakeCfClient, fakeBatchFetcher, 50)
+		})
+
+		It("fetches the usage of the apps of all the orgs in a single batch", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeBatchFetcher.FetchAppsInstanceDataCallCount()).To(Equal(1))
+			_, _, batch := fakeBatchFetcher.FetchAppsInstanceDataArgsForCall(0)
+			Expect(batch).To(HaveLen(3))
+			Expect(batch).To(HaveKey("org1-guid-app1-guid"))
+			Expect(batch).To(HaveKey("org2-guid-app1-guid"))
+			Expect(batch).To(HaveKey("org3-guid-app1-guid"))
+		})
+
+		It("reports the apps under their own org", func() {
+			Expect(report.OrgReports).To(HaveLen(2))
+			Expect(report.OrgReports[0].Org).To(Equal("org1"))
+			Expect(report.OrgReports[0].SpaceReports[0].Apps[0].Guid).To(Equal("org1-guid-app1-guid"))
+			Expect(report.OrgReports[1].Org).To(Equal("org2"))
+			Expect(report.OrgReports[1].SpaceReports[0].Apps[0].Guid).To(Equal("org2-guid-app1-guid"))
+		})
+	})
+
+	It("does not depend on the targeted org", func() {
+		Expect(fakeCfClient.GetCurrentOrgCallCount()).To(Equal(0))
+		Expect(fakeCfClient.GetSpacesCallCount()).To(Equal(0))
+	})
+
+	It("reports the orgs with instances over entitlement sorted by name", func() {
+		Expect(report).To(Equal(reporter.AllOrgsOEIReport{
+			Username:   "user",
+			Thresholds: reporter.DefaultThresholds,
+			OrgReports: []reporter.OEIReport{
+				{
+					Org:        "org1",
+					Username:   "user",
+					Thresholds: reporter.DefaultThresholds,
+					SpaceReports: []reporter.SpaceReport{
+						{
+							SpaceName: "space1",
+							Apps: []reporter.OEIAppReport{
+								{
+									Name:            "app1",
+									Guid:            "org1-guid-app1-guid",
+									InstanceCount:   1,
+									InstanceReports: []reporter.InstanceReport{{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 1.5}}},
+								},
+							},
+							NearApps: []reporter.OEIAppReport{},
+						},
+					},
+				},
+				{
+					Org:        "org2",
+					Username:   "user",
+					Thresholds: reporter.DefaultThresholds,
+					SpaceReports: []reporter.SpaceReport{
+						{
+							SpaceName: "space1",
+							Apps: []reporter.OEIAppReport{
+								{
+									Name:            "app1",
+									Guid:            "org2-guid-app1-guid",
+									InstanceCount:   1,
+									InstanceReports: []reporter.InstanceReport{{InstanceID: 0, CumulativeUsage: reporter.CumulativeUsage{Value: 2.5}}},
+								},
+							},
+							NearApps: []reporter.OEIAppReport{},
+						},
+					},
+				},
+			},
+		}))
+	})
+
+	When("no org has instances over entitlement", func() {
+		BeforeEach(func() {
+			fakeMetricsFetcher.FetchInstanceDataStub = nil
+			fakeMetricsFetcher.FetchInstanceDataReturns(map[int]interface{}{0: fetchers.CumulativeInstanceData{Usage: 0.5}}, nil)
+		})
+
+		It("returns an empty report", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.OrgReports).To(BeEmpty())
+		})
+	})
+
+	When("getting the username fails", func() {
+		BeforeEach(func() {
+			fakeCfClient.UsernameReturns("", errors.New("username-err"))
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError("username-err"))
+		})
+	})
+
+	When("getting the orgs fails", func() {
+		BeforeEach(func() {
+			fakeCfClient.GetOrgsReturns(nil, errors.New("get-orgs-err"))
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError("get-orgs-err"))
+		})
+	})
+
+	When("getting the spaces of an org fails", func() {
+		BeforeEach(func() {
+			fakeCfClient.GetSpacesInOrgStub = nil
+			fakeCfClient.GetSpacesInOrgReturns(nil, errors.New("get-spaces-err"))
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError("get-spaces-err"))
+		})
+	})
+
+	When("getting the entitlement usage for an app fails", func() {
+		BeforeEach(func() {
+			fakeMetricsFetcher.FetchInstanceDataStub = nil
+			fakeMetricsFetcher.FetchInstanceDataReturns(nil, errors.New("fetch-error"))
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError("fetch-error"))
+		})
+	})
+})
+
+// fetchEachApp makes a batch metrics fetcher fetch the usage of each app of
+// the batch with appFetcher, so that the usage can be stubbed per app.
+func fetchEachApp(appFetcher *reporterfakes.FakeMetricsFetcher) func(context.Context, lager.Logger, map[string]map[int]cf.Instance) (map[string]map[int]interface{}, error) {
+	return func(ctx context.Context, logger lager.Logger, appsInstances map[string]map[int]cf.Instance) (map[string]map[int]interface{}, error) {
+		appsUsages := map[string]map[int]interface{}{}
+		for appGuid, appInstances := range appsInstances {
+			appUsages, err := appFetcher.FetchInstanceData(ctx, logger, appGuid, appInstances)
+			if err != nil {
+				return nil, err
+			}
+			appsUsages[appGuid] = appUsages
+		}
+		return appsUsages, nil
+	}
+}
@@ -0,0 +1,90 @@
+package reporter
+
+import (
+	"math"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+)
+
+// memoryIncrementInMB is the step recommended memory limits are rounded up
+// to.
+const memoryIncrementInMB = 128
+
+// Recommendation tells how to scale a process so that the usage of its
+// busiest instance stays below the target ratio of its entitlement. The
+// entitlement of an instance grows with its memory limit, so scaling the
+// memory by some factor divides the usage ratio by the same factor. Adding
+// instances only helps when the load is spread evenly across them.
+//
+// Usage is the p95 of the busiest instance when usage stats are available,
+// and its average usage otherwise. RecommendedMemoryInMB is zero when the
+// memory limit of the process is unknown.
+type Recommendation struct {
+	ProcessType           string
+	Target                float64
+	UsesP95               bool
+	Usage                 float64
+	MemoryInMB            int64
+	RecommendedMemoryInMB int64
+	InstanceCount         int
+	RecommendedInstances  int
+}
+
+// recommend returns how to scale the process, or false if the usage of all
+// its instances is already below the target.
+func recommend(process cf.Process, instanceReports []InstanceReport, target float64) (Recommendation, bool) {
+	if len(instanceReports) == 0 {
+		return Recommendation{}, false
+	}
+
+	instanceCount := process.InstanceCount
+	if instanceCount < len(instanceReports) {
+		instanceCount = len(instanceReports)
+	}
+
+	recommendation := Recommendation{
+		ProcessType:   process.Type,
+		Target:        target,
+		UsesP95:       true,
+		MemoryInMB:    process.MemoryInMB,
+		InstanceCount: instanceCount,
+	}
+
+	var totalUsage float64
+	for _, report := range instanceReports {
+		if report.UsageStats == nil || report.UsageStats.SampleCount == 0 {
+			recommendation.UsesP95 = false
+		}
+	}
+	for _, report := range instanceReports {
+		usage := report.CumulativeUsage.Value
+		if recommendation.UsesP95 {
+			usage = report.UsageStats.P95
+		}
+		totalUsage += usage
+		recommendation.Usage = math.Max(recommendation.Usage, usage)
+	}
+
+	if recommendation.Usage <= target {
+		return Recommendation{}, false
+	}
+
+	if process.MemoryInMB > 0 {
+		requiredMemory := float64(process.MemoryInMB) * recommendation.Usage / target
+		recommendation.RecommendedMemoryInMB = int64(ceil(requiredMemory/memoryIncrementInMB)) * memoryIncrementInMB
+	}
+
+	averageUsage := totalUsage / float64(len(instanceReports))
+	recommendation.RecommendedInstances = int(ceil(float64(instanceCount) * averageUsage / target))
+	if recommendation.RecommendedInstances < instanceCount {
+		recommendation.RecommendedInstances = instanceCount
+	}
+
+	return recommendation, true
+}
+
+// ceil rounds up, ignoring the rounding errors of the divisions above so that
+// exact multiples are not rounded up to the next step.
+func ceil(x float64) float64 {
+	return math.Ceil(x - 1e-9)
+}
@@ -2,6 +2,7 @@
 package reporterfakes
 
 import (
+	"context"
 	"sync"
 
 	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
@@ -10,11 +11,12 @@ import (
 )
 
 type FakeAppReporterCloudFoundryClient struct {
-	GetApplicationStub        func(lager.Logger, string) (cf.Application, error)
+	GetApplicationStub        func(context.Context, lager.Logger, string) (cf.Application, error)
 	getApplicationMutex       sync.RWMutex
 	getApplicationArgsForCall []struct {
-		arg1 lager.Logger
-		arg2 string
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
 	}
 	getApplicationReturns struct {
 		result1 cf.Application
@@ -24,6 +26,20 @@ type FakeAppReporterCloudFoundryClient struct {
 		result1 cf.Application
 		result2 error
 	}
+	GetApplicationNamesStub        func(context.Context, lager.Logger) ([]string, error)
+	getApplicationNamesMutex       sync.RWMutex
+	getApplicationNamesArgsForCall []struct {
+		arg1 context.Context
+		arg2 lager.Logger
+	}
+	getApplicationNamesReturns struct {
+		result1 []string
+		result2 error
+	}
+	getApplicationNamesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	GetCurrentOrgStub        func(lager.Logger) (string, error)
 	getCurrentOrgMutex       sync.RWMutex
 	getCurrentOrgArgsForCall []struct {
@@ -67,22 +83,24 @@ type FakeAppReporterCloudFoundryClient struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeAppReporterCloudFoundryClient) GetApplication(arg1 lager.Logger, arg2 string) (cf.Application, error) {
+func (fake *FakeAppReporterCloudFoundryClient) GetApplication(arg1 context.Context, arg2 lager.Logger, arg3 string) (cf.Application, error) {
 	fake.getApplicationMutex.Lock()
 	ret, specificReturn := fake.getApplicationReturnsOnCall[len(fake.getApplicationArgsForCall)]
 	fake.getApplicationArgsForCall = append(fake.getApplicationArgsForCall, struct {
-		arg1 lager.Logger
-		arg2 string
-	}{arg1, arg2})
-	fake.recordInvocation("GetApplication", []interface{}{arg1, arg2})
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.GetApplicationStub
+	fakeReturns := fake.getApplicationReturns
+	fake.recordInvocation("GetApplication", []interface{}{arg1, arg2, arg3})
 	fake.getApplicationMutex.Unlock()
-	if fake.GetApplicationStub != nil {
-		return fake.GetApplicationStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getApplicationReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -92,17 +110,17 @@ func (fake *FakeAppReporterCloudFoundryClient) GetApplicationCallCount() int {
 	return len(fake.getApplicationArgsForCall)
 }
 
-func (fake *FakeAppReporterCloudFoundryClient) GetApplicationCalls(stub func(lager.Logger, string) (cf.Application, error)) {
+func (fake *FakeAppReporterCloudFoundryClient) GetApplicationCalls(stub func(context.Context, lager.Logger, string) (cf.Application, error)) {
 	fake.getApplicationMutex.Lock()
 	defer fake.getApplicationMutex.Unlock()
 	fake.GetApplicationStub = stub
 }
 
-func (fake *FakeAppReporterCloudFoundryClient) GetApplicationArgsForCall(i int) (lager.Logger, string) {
+func (fake *FakeAppReporterCloudFoundryClient) GetApplicationArgsForCall(i int) (context.Context, lager.Logger, string) {
 	fake.getApplicationMutex.RLock()
 	defer fake.getApplicationMutex.RUnlock()
 	argsForCall := fake.getApplicationArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeAppReporterCloudFoundryClient) GetApplicationReturns(result1 cf.Application, result2 error) {
@@ -131,21 +149,87 @@ func (fake *FakeAppReporterCloudFoundryClient) GetApplicationReturnsOnCall(i int
 	}{result1, result2}
 }
 
+func (fake *FakeAppReporterCloudFoundryClient) GetApplicationNames(arg1 context.Context, arg2 lager.Logger) ([]string, error) {
+	fake.getApplicationNamesMutex.Lock()
+	ret, specificReturn := fake.getApplicationNamesReturnsOnCall[len(fake.getApplicationNamesArgsForCall)]
+	fake.getApplicationNamesArgsForCall = append(fake.getApplicationNamesArgsForCall, struct {
+		arg1 context.Context
+		arg2 lager.Logger
+	}{arg1, arg2})
+	stub := fake.GetApplicationNamesStub
+	fakeReturns := fake.getApplicationNamesReturns
+	fake.recordInvocation("GetApplicationNames", []interface{}{arg1, arg2})
+	fake.getApplicationNamesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAppReporterCloudFoundryClient) GetApplicationNamesCallCount() int {
+	fake.getApplicationNamesMutex.RLock()
+	defer fake.getApplicationNamesMutex.RUnlock()
+	return len(fake.getApplicationNamesArgsForCall)
+}
+
+func (fake *FakeAppReporterCloudFoundryClient) GetApplicationNamesCalls(stub func(context.Context, lager.Logger) ([]string, error)) {
+	fake.getApplicationNamesMutex.Lock()
+	defer fake.getApplicationNamesMutex.Unlock()
+	fake.GetApplicationNamesStub = stub
+}
+
+func (fake *FakeAppReporterCloudFoundryClient) GetApplicationNamesArgsForCall(i int) (context.Context, lager.Logger) {
+	fake.getApplicationNamesMutex.RLock()
+	defer fake.getApplicationNamesMutex.RUnlock()
+	argsForCall := fake.getApplicationNamesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeAppReporterCloudFoundryClient) GetApplicationNamesReturns(result1 []string, result2 error) {
+	fake.getApplicationNamesMutex.Lock()
+	defer fake.getApplicationNamesMutex.Unlock()
+	fake.GetApplicationNamesStub = nil
+	fake.getApplicationNamesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAppReporterCloudFoundryClient) GetApplicationNamesReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.getApplicationNamesMutex.Lock()
+	defer fake.getApplicationNamesMutex.Unlock()
+	fake.GetApplicationNamesStub = nil
+	if fake.getApplicationNamesReturnsOnCall == nil {
+		fake.getApplicationNamesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.getApplicationNamesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeAppReporterCloudFoundryClient) GetCurrentOrg(arg1 lager.Logger) (string, error) {
 	fake.getCurrentOrgMutex.Lock()
 	ret, specificReturn := fake.getCurrentOrgReturnsOnCall[len(fake.getCurrentOrgArgsForCall)]
 	fake.getCurrentOrgArgsForCall = append(fake.getCurrentOrgArgsForCall, struct {
 		arg1 lager.Logger
 	}{arg1})
+	stub := fake.GetCurrentOrgStub
+	fakeReturns := fake.getCurrentOrgReturns
 	fake.recordInvocation("GetCurrentOrg", []interface{}{arg1})
 	fake.getCurrentOrgMutex.Unlock()
-	if fake.GetCurrentOrgStub != nil {
-		return fake.GetCurrentOrgStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getCurrentOrgReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -200,15 +284,16 @@ func (fake *FakeAppReporterCloudFoundryClient) GetCurrentSpace(arg1 lager.Logger
 	fake.getCurrentSpaceArgsForCall = append(fake.getCurrentSpaceArgsForCall, struct {
 		arg1 lager.Logger
 	}{arg1})
+	stub := fake.GetCurrentSpaceStub
+	fakeReturns := fake.getCurrentSpaceReturns
 	fake.recordInvocation("GetCurrentSpace", []interface{}{arg1})
 	fake.getCurrentSpaceMutex.Unlock()
-	if fake.GetCurrentSpaceStub != nil {
-		return fake.GetCurrentSpaceStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getCurrentSpaceReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -263,15 +348,16 @@ func (fake *FakeAppReporterCloudFoundryClient) Username(arg1 lager.Logger) (stri
 	fake.usernameArgsForCall = append(fake.usernameArgsForCall, struct {
 		arg1 lager.Logger
 	}{arg1})
+	stub := fake.UsernameStub
+	fakeReturns := fake.usernameReturns
 	fake.recordInvocation("Username", []interface{}{arg1})
 	fake.usernameMutex.Unlock()
-	if fake.UsernameStub != nil {
-		return fake.UsernameStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.usernameReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -325,6 +411,8 @@ func (fake *FakeAppReporterCloudFoundryClient) Invocations() map[string][][]inte
 	defer fake.invocationsMutex.RUnlock()
 	fake.getApplicationMutex.RLock()
 	defer fake.getApplicationMutex.RUnlock()
+	fake.getApplicationNamesMutex.RLock()
+	defer fake.getApplicationNamesMutex.RUnlock()
 	fake.getCurrentOrgMutex.RLock()
 	defer fake.getCurrentOrgMutex.RUnlock()
 	fake.getCurrentSpaceMutex.RLock()
@@ -0,0 +1,123 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package reporterfakes
+
+import (
+	"context"
+	"sync"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+	"code.cloudfoundry.org/lager"
+)
+
+type FakeBatchMetricsFetcher struct {
+	FetchAppsInstanceDataStub        func(context.Context, lager.Logger, map[string]map[int]cf.Instance) (map[string]map[int]interface{}, error)
+	fetchAppsInstanceDataMutex       sync.RWMutex
+	fetchAppsInstanceDataArgsForCall []struct {
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 map[string]map[int]cf.Instance
+	}
+	fetchAppsInstanceDataReturns struct {
+		result1 map[string]map[int]interface{}
+		result2 error
+	}
+	fetchAppsInstanceDataReturnsOnCall map[int]struct {
+		result1 map[string]map[int]interface{}
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeBatchMetricsFetcher) FetchAppsInstanceData(arg1 context.Context, arg2 lager.Logger, arg3 map[string]map[int]cf.Instance) (map[string]map[int]interface{}, error) {
+	fake.fetchAppsInstanceDataMutex.Lock()
+	ret, specificReturn := fake.fetchAppsInstanceDataReturnsOnCall[len(fake.fetchAppsInstanceDataArgsForCall)]
+	fake.fetchAppsInstanceDataArgsForCall = append(fake.fetchAppsInstanceDataArgsForCall, struct {
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 map[string]map[int]cf.Instance
+	}{arg1, arg2, arg3})
+	stub := fake.FetchAppsInstanceDataStub
+	fakeReturns := fake.fetchAppsInstanceDataReturns
+	fake.recordInvocation("FetchAppsInstanceData", []interface{}{arg1, arg2, arg3})
+	fake.fetchAppsInstanceDataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeBatchMetricsFetcher) FetchAppsInstanceDataCallCount() int {
+	fake.fetchAppsInstanceDataMutex.RLock()
+	defer fake.fetchAppsInstanceDataMutex.RUnlock()
+	return len(fake.fetchAppsInstanceDataArgsForCall)
+}
+
+func (fake *FakeBatchMetricsFetcher) FetchAppsInstanceDataCalls(stub func(context.Context, lager.Logger, map[string]map[int]cf.Instance) (map[string]map[int]interface{}, error)) {
+	fake.fetchAppsInstanceDataMutex.Lock()
+	defer fake.fetchAppsInstanceDataMutex.Unlock()
+	fake.FetchAppsInstanceDataStub = stub
+}
+
+func (fake *FakeBatchMetricsFetcher) FetchAppsInstanceDataArgsForCall(i int) (context.Context, lager.Logger, map[string]map[int]cf.Instance) {
+	fake.fetchAppsInstanceDataMutex.RLock()
+	defer fake.fetchAppsInstanceDataMutex.RUnlock()
+	argsForCall := fake.fetchAppsInstanceDataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeBatchMetricsFetcher) FetchAppsInstanceDataReturns(result1 map[string]map[int]interface{}, result2 error) {
+	fake.fetchAppsInstanceDataMutex.Lock()
+	defer fake.fetchAppsInstanceDataMutex.Unlock()
+	fake.FetchAppsInstanceDataStub = nil
+	fake.fetchAppsInstanceDataReturns = struct {
+		result1 map[string]map[int]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBatchMetricsFetcher) FetchAppsInstanceDataReturnsOnCall(i int, result1 map[string]map[int]interface{}, result2 error) {
+	fake.fetchAppsInstanceDataMutex.Lock()
+	defer fake.fetchAppsInstanceDataMutex.Unlock()
+	fake.FetchAppsInstanceDataStub = nil
+	if fake.fetchAppsInstanceDataReturnsOnCall == nil {
+		fake.fetchAppsInstanceDataReturnsOnCall = make(map[int]struct {
+			result1 map[string]map[int]interface{}
+			result2 error
+		})
+	}
+	fake.fetchAppsInstanceDataReturnsOnCall[i] = struct {
+		result1 map[string]map[int]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBatchMetricsFetcher) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.fetchAppsInstanceDataMutex.RLock()
+	defer fake.fetchAppsInstanceDataMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeBatchMetricsFetcher) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ reporter.BatchMetricsFetcher = new(FakeBatchMetricsFetcher)
@@ -2,6 +2,7 @@
 package reporterfakes
 
 import (
+	"context"
 	"sync"
 
 	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
@@ -23,10 +24,25 @@ type FakeCloudFoundryClient struct {
 		result1 string
 		result2 error
 	}
-	GetSpacesStub        func(lager.Logger) ([]cf.Space, error)
+	GetOrgsStub        func(context.Context, lager.Logger) ([]cf.Org, error)
+	getOrgsMutex       sync.RWMutex
+	getOrgsArgsForCall []struct {
+		arg1 context.Context
+		arg2 lager.Logger
+	}
+	getOrgsReturns struct {
+		result1 []cf.Org
+		result2 error
+	}
+	getOrgsReturnsOnCall map[int]struct {
+		result1 []cf.Org
+		result2 error
+	}
+	GetSpacesStub        func(context.Context, lager.Logger) ([]cf.Space, error)
 	getSpacesMutex       sync.RWMutex
 	getSpacesArgsForCall []struct {
-		arg1 lager.Logger
+		arg1 context.Context
+		arg2 lager.Logger
 	}
 	getSpacesReturns struct {
 		result1 []cf.Space
@@ -36,6 +52,21 @@ type FakeCloudFoundryClient struct {
 		result1 []cf.Space
 		result2 error
 	}
+	GetSpacesInOrgStub        func(context.Context, lager.Logger, string) ([]cf.Space, error)
+	getSpacesInOrgMutex       sync.RWMutex
+	getSpacesInOrgArgsForCall []struct {
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+	}
+	getSpacesInOrgReturns struct {
+		result1 []cf.Space
+		result2 error
+	}
+	getSpacesInOrgReturnsOnCall map[int]struct {
+		result1 []cf.Space
+		result2 error
+	}
 	UsernameStub        func(lager.Logger) (string, error)
 	usernameMutex       sync.RWMutex
 	usernameArgsForCall []struct {
@@ -59,15 +90,16 @@ func (fake *FakeCloudFoundryClient) GetCurrentOrg(arg1 lager.Logger) (string, er
 	fake.getCurrentOrgArgsForCall = append(fake.getCurrentOrgArgsForCall, struct {
 		arg1 lager.Logger
 	}{arg1})
+	stub := fake.GetCurrentOrgStub
+	fakeReturns := fake.getCurrentOrgReturns
 	fake.recordInvocation("GetCurrentOrg", []interface{}{arg1})
 	fake.getCurrentOrgMutex.Unlock()
-	if fake.GetCurrentOrgStub != nil {
-		return fake.GetCurrentOrgStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getCurrentOrgReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -116,21 +148,88 @@ func (fake *FakeCloudFoundryClient) GetCurrentOrgReturnsOnCall(i int, result1 st
 	}{result1, result2}
 }
 
-func (fake *FakeCloudFoundryClient) GetSpaces(arg1 lager.Logger) ([]cf.Space, error) {
+func (fake *FakeCloudFoundryClient) GetOrgs(arg1 context.Context, arg2 lager.Logger) ([]cf.Org, error) {
+	fake.getOrgsMutex.Lock()
+	ret, specificReturn := fake.getOrgsReturnsOnCall[len(fake.getOrgsArgsForCall)]
+	fake.getOrgsArgsForCall = append(fake.getOrgsArgsForCall, struct {
+		arg1 context.Context
+		arg2 lager.Logger
+	}{arg1, arg2})
+	stub := fake.GetOrgsStub
+	fakeReturns := fake.getOrgsReturns
+	fake.recordInvocation("GetOrgs", []interface{}{arg1, arg2})
+	fake.getOrgsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCloudFoundryClient) GetOrgsCallCount() int {
+	fake.getOrgsMutex.RLock()
+	defer fake.getOrgsMutex.RUnlock()
+	return len(fake.getOrgsArgsForCall)
+}
+
+func (fake *FakeCloudFoundryClient) GetOrgsCalls(stub func(context.Context, lager.Logger) ([]cf.Org, error)) {
+	fake.getOrgsMutex.Lock()
+	defer fake.getOrgsMutex.Unlock()
+	fake.GetOrgsStub = stub
+}
+
+func (fake *FakeCloudFoundryClient) GetOrgsArgsForCall(i int) (context.Context, lager.Logger) {
+	fake.getOrgsMutex.RLock()
+	defer fake.getOrgsMutex.RUnlock()
+	argsForCall := fake.getOrgsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCloudFoundryClient) GetOrgsReturns(result1 []cf.Org, result2 error) {
+	fake.getOrgsMutex.Lock()
+	defer fake.getOrgsMutex.Unlock()
+	fake.GetOrgsStub = nil
+	fake.getOrgsReturns = struct {
+		result1 []cf.Org
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCloudFoundryClient) GetOrgsReturnsOnCall(i int, result1 []cf.Org, result2 error) {
+	fake.getOrgsMutex.Lock()
+	defer fake.getOrgsMutex.Unlock()
+	fake.GetOrgsStub = nil
+	if fake.getOrgsReturnsOnCall == nil {
+		fake.getOrgsReturnsOnCall = make(map[int]struct {
+			result1 []cf.Org
+			result2 error
+		})
+	}
+	fake.getOrgsReturnsOnCall[i] = struct {
+		result1 []cf.Org
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCloudFoundryClient) GetSpaces(arg1 context.Context, arg2 lager.Logger) ([]cf.Space, error) {
 	fake.getSpacesMutex.Lock()
 	ret, specificReturn := fake.getSpacesReturnsOnCall[len(fake.getSpacesArgsForCall)]
 	fake.getSpacesArgsForCall = append(fake.getSpacesArgsForCall, struct {
-		arg1 lager.Logger
-	}{arg1})
-	fake.recordInvocation("GetSpaces", []interface{}{arg1})
+		arg1 context.Context
+		arg2 lager.Logger
+	}{arg1, arg2})
+	stub := fake.GetSpacesStub
+	fakeReturns := fake.getSpacesReturns
+	fake.recordInvocation("GetSpaces", []interface{}{arg1, arg2})
 	fake.getSpacesMutex.Unlock()
-	if fake.GetSpacesStub != nil {
-		return fake.GetSpacesStub(arg1)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getSpacesReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -140,17 +239,17 @@ func (fake *FakeCloudFoundryClient) GetSpacesCallCount() int {
 	return len(fake.getSpacesArgsForCall)
 }
 
-func (fake *FakeCloudFoundryClient) GetSpacesCalls(stub func(lager.Logger) ([]cf.Space, error)) {
+func (fake *FakeCloudFoundryClient) GetSpacesCalls(stub func(context.Context, lager.Logger) ([]cf.Space, error)) {
 	fake.getSpacesMutex.Lock()
 	defer fake.getSpacesMutex.Unlock()
 	fake.GetSpacesStub = stub
 }
 
-func (fake *FakeCloudFoundryClient) GetSpacesArgsForCall(i int) lager.Logger {
+func (fake *FakeCloudFoundryClient) GetSpacesArgsForCall(i int) (context.Context, lager.Logger) {
 	fake.getSpacesMutex.RLock()
 	defer fake.getSpacesMutex.RUnlock()
 	argsForCall := fake.getSpacesArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeCloudFoundryClient) GetSpacesReturns(result1 []cf.Space, result2 error) {
@@ -179,21 +278,88 @@ func (fake *FakeCloudFoundryClient) GetSpacesReturnsOnCall(i int, result1 []cf.S
 	}{result1, result2}
 }
 
+func (fake *FakeCloudFoundryClient) GetSpacesInOrg(arg1 context.Context, arg2 lager.Logger, arg3 string) ([]cf.Space, error) {
+	fake.getSpacesInOrgMutex.Lock()
+	ret, specificReturn := fake.getSpacesInOrgReturnsOnCall[len(fake.getSpacesInOrgArgsForCall)]
+	fake.getSpacesInOrgArgsForCall = append(fake.getSpacesInOrgArgsForCall, struct {
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.GetSpacesInOrgStub
+	fakeReturns := fake.getSpacesInOrgReturns
+	fake.recordInvocation("GetSpacesInOrg", []interface{}{arg1, arg2, arg3})
+	fake.getSpacesInOrgMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCloudFoundryClient) GetSpacesInOrgCallCount() int {
+	fake.getSpacesInOrgMutex.RLock()
+	defer fake.getSpacesInOrgMutex.RUnlock()
+	return len(fake.getSpacesInOrgArgsForCall)
+}
+
+func (fake *FakeCloudFoundryClient) GetSpacesInOrgCalls(stub func(context.Context, lager.Logger, string) ([]cf.Space, error)) {
+	fake.getSpacesInOrgMutex.Lock()
+	defer fake.getSpacesInOrgMutex.Unlock()
+	fake.GetSpacesInOrgStub = stub
+}
+
+func (fake *FakeCloudFoundryClient) GetSpacesInOrgArgsForCall(i int) (context.Context, lager.Logger, string) {
+	fake.getSpacesInOrgMutex.RLock()
+	defer fake.getSpacesInOrgMutex.RUnlock()
+	argsForCall := fake.getSpacesInOrgArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeCloudFoundryClient) GetSpacesInOrgReturns(result1 []cf.Space, result2 error) {
+	fake.getSpacesInOrgMutex.Lock()
+	defer fake.getSpacesInOrgMutex.Unlock()
+	fake.GetSpacesInOrgStub = nil
+	fake.getSpacesInOrgReturns = struct {
+		result1 []cf.Space
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCloudFoundryClient) GetSpacesInOrgReturnsOnCall(i int, result1 []cf.Space, result2 error) {
+	fake.getSpacesInOrgMutex.Lock()
+	defer fake.getSpacesInOrgMutex.Unlock()
+	fake.GetSpacesInOrgStub = nil
+	if fake.getSpacesInOrgReturnsOnCall == nil {
+		fake.getSpacesInOrgReturnsOnCall = make(map[int]struct {
+			result1 []cf.Space
+			result2 error
+		})
+	}
+	fake.getSpacesInOrgReturnsOnCall[i] = struct {
+		result1 []cf.Space
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeCloudFoundryClient) Username(arg1 lager.Logger) (string, error) {
 	fake.usernameMutex.Lock()
 	ret, specificReturn := fake.usernameReturnsOnCall[len(fake.usernameArgsForCall)]
 	fake.usernameArgsForCall = append(fake.usernameArgsForCall, struct {
 		arg1 lager.Logger
 	}{arg1})
+	stub := fake.UsernameStub
+	fakeReturns := fake.usernameReturns
 	fake.recordInvocation("Username", []interface{}{arg1})
 	fake.usernameMutex.Unlock()
-	if fake.UsernameStub != nil {
-		return fake.UsernameStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.usernameReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -247,8 +413,12 @@ func (fake *FakeCloudFoundryClient) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.getCurrentOrgMutex.RLock()
 	defer fake.getCurrentOrgMutex.RUnlock()
+	fake.getOrgsMutex.RLock()
+	defer fake.getOrgsMutex.RUnlock()
 	fake.getSpacesMutex.RLock()
 	defer fake.getSpacesMutex.RUnlock()
+	fake.getSpacesInOrgMutex.RLock()
+	defer fake.getSpacesInOrgMutex.RUnlock()
 	fake.usernameMutex.RLock()
 	defer fake.usernameMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
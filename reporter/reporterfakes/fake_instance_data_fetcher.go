@@ -2,6 +2,7 @@
 package reporterfakes
 
 import (
+	"context"
 	"sync"
 
 	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
@@ -10,12 +11,13 @@ import (
 )
 
 type FakeInstanceDataFetcher struct {
-	FetchInstanceDataStub        func(lager.Logger, string, map[int]cf.Instance) (map[int]interface{}, error)
+	FetchInstanceDataStub        func(context.Context, lager.Logger, string, map[int]cf.Instance) (map[int]interface{}, error)
 	fetchInstanceDataMutex       sync.RWMutex
 	fetchInstanceDataArgsForCall []struct {
-		arg1 lager.Logger
-		arg2 string
-		arg3 map[int]cf.Instance
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+		arg4 map[int]cf.Instance
 	}
 	fetchInstanceDataReturns struct {
 		result1 map[int]interface{}
@@ -29,23 +31,25 @@ type FakeInstanceDataFetcher struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeInstanceDataFetcher) FetchInstanceData(arg1 lager.Logger, arg2 string, arg3 map[int]cf.Instance) (map[int]interface{}, error) {
+func (fake *FakeInstanceDataFetcher) FetchInstanceData(arg1 context.Context, arg2 lager.Logger, arg3 string, arg4 map[int]cf.Instance) (map[int]interface{}, error) {
 	fake.fetchInstanceDataMutex.Lock()
 	ret, specificReturn := fake.fetchInstanceDataReturnsOnCall[len(fake.fetchInstanceDataArgsForCall)]
 	fake.fetchInstanceDataArgsForCall = append(fake.fetchInstanceDataArgsForCall, struct {
-		arg1 lager.Logger
-		arg2 string
-		arg3 map[int]cf.Instance
-	}{arg1, arg2, arg3})
-	fake.recordInvocation("FetchInstanceData", []interface{}{arg1, arg2, arg3})
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+		arg4 map[int]cf.Instance
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.FetchInstanceDataStub
+	fakeReturns := fake.fetchInstanceDataReturns
+	fake.recordInvocation("FetchInstanceData", []interface{}{arg1, arg2, arg3, arg4})
 	fake.fetchInstanceDataMutex.Unlock()
-	if fake.FetchInstanceDataStub != nil {
-		return fake.FetchInstanceDataStub(arg1, arg2, arg3)
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.fetchInstanceDataReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -55,17 +59,17 @@ func (fake *FakeInstanceDataFetcher) FetchInstanceDataCallCount() int {
 	return len(fake.fetchInstanceDataArgsForCall)
 }
 
-func (fake *FakeInstanceDataFetcher) FetchInstanceDataCalls(stub func(lager.Logger, string, map[int]cf.Instance) (map[int]interface{}, error)) {
+func (fake *FakeInstanceDataFetcher) FetchInstanceDataCalls(stub func(context.Context, lager.Logger, string, map[int]cf.Instance) (map[int]interface{}, error)) {
 	fake.fetchInstanceDataMutex.Lock()
 	defer fake.fetchInstanceDataMutex.Unlock()
 	fake.FetchInstanceDataStub = stub
 }
 
-func (fake *FakeInstanceDataFetcher) FetchInstanceDataArgsForCall(i int) (lager.Logger, string, map[int]cf.Instance) {
+func (fake *FakeInstanceDataFetcher) FetchInstanceDataArgsForCall(i int) (context.Context, lager.Logger, string, map[int]cf.Instance) {
 	fake.fetchInstanceDataMutex.RLock()
 	defer fake.fetchInstanceDataMutex.RUnlock()
 	argsForCall := fake.fetchInstanceDataArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeInstanceDataFetcher) FetchInstanceDataReturns(result1 map[int]interface{}, result2 error) {
@@ -0,0 +1,125 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package reporterfakes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+	"code.cloudfoundry.org/lager"
+)
+
+type FakeRetentionFetcher struct {
+	FetchOldestTimestampStub        func(context.Context, lager.Logger, string, time.Time) (time.Time, error)
+	fetchOldestTimestampMutex       sync.RWMutex
+	fetchOldestTimestampArgsForCall []struct {
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+		arg4 time.Time
+	}
+	fetchOldestTimestampReturns struct {
+		result1 time.Time
+		result2 error
+	}
+	fetchOldestTimestampReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeRetentionFetcher) FetchOldestTimestamp(arg1 context.Context, arg2 lager.Logger, arg3 string, arg4 time.Time) (time.Time, error) {
+	fake.fetchOldestTimestampMutex.Lock()
+	ret, specificReturn := fake.fetchOldestTimestampReturnsOnCall[len(fake.fetchOldestTimestampArgsForCall)]
+	fake.fetchOldestTimestampArgsForCall = append(fake.fetchOldestTimestampArgsForCall, struct {
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+		arg4 time.Time
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.FetchOldestTimestampStub
+	fakeReturns := fake.fetchOldestTimestampReturns
+	fake.recordInvocation("FetchOldestTimestamp", []interface{}{arg1, arg2, arg3, arg4})
+	fake.fetchOldestTimestampMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRetentionFetcher) FetchOldestTimestampCallCount() int {
+	fake.fetchOldestTimestampMutex.RLock()
+	defer fake.fetchOldestTimestampMutex.RUnlock()
+	return len(fake.fetchOldestTimestampArgsForCall)
+}
+
+func (fake *FakeRetentionFetcher) FetchOldestTimestampCalls(stub func(context.Context, lager.Logger, string, time.Time) (time.Time, error)) {
+	fake.fetchOldestTimestampMutex.Lock()
+	defer fake.fetchOldestTimestampMutex.Unlock()
+	fake.FetchOldestTimestampStub = stub
+}
+
+func (fake *FakeRetentionFetcher) FetchOldestTimestampArgsForCall(i int) (context.Context, lager.Logger, string, time.Time) {
+	fake.fetchOldestTimestampMutex.RLock()
+	defer fake.fetchOldestTimestampMutex.RUnlock()
+	argsForCall := fake.fetchOldestTimestampArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeRetentionFetcher) FetchOldestTimestampReturns(result1 time.Time, result2 error) {
+	fake.fetchOldestTimestampMutex.Lock()
+	defer fake.fetchOldestTimestampMutex.Unlock()
+	fake.FetchOldestTimestampStub = nil
+	fake.fetchOldestTimestampReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRetentionFetcher) FetchOldestTimestampReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.fetchOldestTimestampMutex.Lock()
+	defer fake.fetchOldestTimestampMutex.Unlock()
+	fake.FetchOldestTimestampStub = nil
+	if fake.fetchOldestTimestampReturnsOnCall == nil {
+		fake.fetchOldestTimestampReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.fetchOldestTimestampReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRetentionFetcher) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.fetchOldestTimestampMutex.RLock()
+	defer fake.fetchOldestTimestampMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeRetentionFetcher) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ reporter.RetentionFetcher = new(FakeRetentionFetcher)
@@ -0,0 +1,110 @@
+package reporter
+
+import (
+	"context"
+	"sort"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/pool"
+	"code.cloudfoundry.org/lager"
+)
+
+type SpaceUsageReport struct {
+	Org      string
+	Space    string
+	Username string
+	Apps     []AppUsageReport
+}
+
+// AppUsageReport summarises the instances of an app. The usages are the
+// means of the usages of its instances, and WorstInstance is the instance with
+// the highest average usage. They are zero when the app has no instances.
+type AppUsageReport struct {
+	ApplicationName string
+	InstanceCount   int
+	AverageUsage    float64
+	CurrentUsage    float64
+	WorstInstance   InstanceReport
+}
+
+// CreateSpaceReport summarises the usage of every app in the targeted space,
+// sorted by average usage, highest first. Apps without CPU data, such as apps
+// that have just been started, are left out. The apps are reported on
+// concurrently, and as soon as one fails the others are cancelled.
+func (r AppReporter) CreateSpaceReport(ctx context.Context, logger lager.Logger) (SpaceUsageReport, error) {
+	logger = logger.Session("create-space-report")
+	logger.Info("start")
+	defer logger.Info("end")
+
+	org, err := r.cfClient.GetCurrentOrg(logger)
+	if err != nil {
+		return SpaceUsageReport{}, err
+	}
+
+	space, err := r.cfClient.GetCurrentSpace(logger)
+	if err != nil {
+		return SpaceUsageReport{}, err
+	}
+
+	user, err := r.cfClient.Username(logger)
+	if err != nil {
+		return SpaceUsageReport{}, err
+	}
+
+	appNames, err := r.cfClient.GetApplicationNames(ctx, logger)
+	if err != nil {
+		return SpaceUsageReport{}, err
+	}
+
+	appReports := make([]*ApplicationReport, len(appNames))
+	err = pool.Run(ctx, r.concurrency, len(appNames), func(ctx context.Context, i int) error {
+		appReport, err := r.CreateApplicationReport(ctx, logger, appNames[i])
+		if err != nil {
+			if _, ok := err.(UnsupportedCFDeploymentError); ok {
+				logger.Info("skipping-app-without-cpu-data", lager.Data{"app": appNames[i]})
+				return nil
+			}
+			return err
+		}
+
+		appReports[i] = &appReport
+		return nil
+	})
+	if err != nil {
+		return SpaceUsageReport{}, err
+	}
+
+	var apps []AppUsageReport
+	for _, appReport := range appReports {
+		if appReport != nil {
+			apps = append(apps, summariseApp(*appReport))
+		}
+	}
+
+	sort.SliceStable(apps, func(i, j int) bool {
+		return apps[i].AverageUsage > apps[j].AverageUsage
+	})
+
+	return SpaceUsageReport{Org: org, Space: space, Username: user, Apps: apps}, nil
+}
+
+func summariseApp(appReport ApplicationReport) AppUsageReport {
+	app := AppUsageReport{
+		ApplicationName: appReport.ApplicationName,
+		InstanceCount:   len(appReport.InstanceReports),
+	}
+	if app.InstanceCount == 0 {
+		return app
+	}
+
+	for i, instanceReport := range appReport.InstanceReports {
+		app.AverageUsage += instanceReport.CumulativeUsage.Value
+		app.CurrentUsage += instanceReport.CurrentUsage.Value
+		if i == 0 || instanceReport.CumulativeUsage.Value > app.WorstInstance.CumulativeUsage.Value {
+			app.WorstInstance = instanceReport
+		}
+	}
+	app.AverageUsage /= float64(app.InstanceCount)
+	app.CurrentUsage /= float64(app.InstanceCount)
+
+	return app
+}
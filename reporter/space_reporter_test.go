@@ -0,0 +1,177 @@
+package reporter_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/cf"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/fetchers"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter/reporterfakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+)
+
+var _ = Describe("Space Reporter", func() {
+	var (
+		cumulativeUsageFetcher *reporterfakes.FakeInstanceDataFetcher
+		currentUsageFetcher    *reporterfakes.FakeInstanceDataFetcher
+		lastSpikeFetcher       *reporterfakes.FakeInstanceDataFetcher
+		cfClient               *reporterfakes.FakeAppReporterCloudFoundryClient
+		appReporter            reporter.AppReporter
+		report                 reporter.SpaceUsageReport
+		err                    error
+	)
+
+	BeforeEach(func() {
+		cumulativeUsageFetcher = new(reporterfakes.FakeInstanceDataFetcher)
+		currentUsageFetcher = new(reporterfakes.FakeInstanceDataFetcher)
+		lastSpikeFetcher = new(reporterfakes.FakeInstanceDataFetcher)
+		cfClient = new(reporterfakes.FakeAppReporterCloudFoundryClient)
+
+		cfClient.GetCurrentOrgReturns("the-org", nil)
+		cfClient.GetCurrentSpaceReturns("the-space", nil)
+		cfClient.UsernameReturns("the-user", nil)
+		cfClient.GetApplicationNamesReturns([]string{"idle", "busy", "stopped", "starting"}, nil)
+		cfClient.GetApplicationStub = func(_ context.Context, logger lager.Logger, appName string) (cf.Application, error) {
+			switch appName {
+			case "stopped":
+				return cf.Application{Name: appName, Guid: appName}, nil
+			case "busy":
+				return cf.Application{Name: appName, Guid: appName, Instances: map[int]cf.Instance{0: {InstanceID: 0}, 1: {InstanceID: 1}}}, nil
+			}
+			return cf.Application{Name: appName, Guid: appName, Instances: map[int]cf.Instance{0: {InstanceID: 0}}}, nil
+		}
+
+		currentUsageFetcher.FetchInstanceDataStub = func(_ context.Context, logger lager.Logger, appGUID string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+			switch appGUID {
+			case "busy":
+				return map[int]interface{}{
+					0: fetchers.CurrentInstanceData{InstanceID: 0, Usage: 0.5},
+					1: fetchers.CurrentInstanceData{InstanceID: 1, Usage: 1.5},
+				}, nil
+			case "idle":
+				return map[int]interface{}{
+					0: fetchers.CurrentInstanceData{InstanceID: 0, Usage: 0.1},
+				}, nil
+			}
+			return map[int]interface{}{}, nil
+		}
+		cumulativeUsageFetcher.FetchInstanceDataStub = func(_ context.Context, logger lager.Logger, appGUID string, appInstances map[int]cf.Instance) (map[int]interface{}, error) {
+			switch appGUID {
+			case "busy":
+				return map[int]interface{}{
+					0: fetchers.CumulativeInstanceData{InstanceID: 0, Usage: 0.75},
+					1: fetchers.CumulativeInstanceData{InstanceID: 1, Usage: 1.25},
+				}, nil
+			case "idle":
+				return map[int]interface{}{
+					0: fetchers.CumulativeInstanceData{InstanceID: 0, Usage: 0.2},
+				}, nil
+			}
+			return map[int]interface{}{}, nil
+		}
+
+		appReporter = reporter.NewAppReporter(cfClient, currentUsageFetcher, lastSpikeFetcher, cumulativeUsageFetcher)
+	})
+
+	JustBeforeEach(func() {
+		report, err = appReporter.CreateSpaceReport(context.Background(), lagertest.NewTestLogger("space-reporter-test"))
+	})
+
+	It("reports the org, space and user", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Org).To(Equal("the-org"))
+		Expect(report.Space).To(Equal("the-space"))
+		Expect(report.Username).To(Equal("the-user"))
+	})
+
+	It("summarises every app with CPU data, sorted by average usage", func() {
+		Expect(report.Apps).To(Equal([]reporter.AppUsageReport{
+			{
+				ApplicationName: "busy",
+				InstanceCount:   2,
+				AverageUsage:    1,
+				CurrentUsage:    1,
+				WorstInstance: reporter.InstanceReport{
+					ProcessType:     "web",
+					InstanceID:      1,
+					CumulativeUsage: reporter.CumulativeUsage{Value: 1.25},
+					CurrentUsage:    reporter.CurrentUsage{Value: 1.5},
+				},
+			},
+			{
+				ApplicationName: "idle",
+				InstanceCount:   1,
+				AverageUsage:    0.2,
+				CurrentUsage:    0.1,
+				WorstInstance: reporter.InstanceReport{
+					ProcessType:     "web",
+					InstanceID:      0,
+					CumulativeUsage: reporter.CumulativeUsage{Value: 0.2},
+					CurrentUsage:    reporter.CurrentUsage{Value: 0.1},
+				},
+			},
+			{
+				ApplicationName: "stopped",
+			},
+		}))
+	})
+
+	When("listing the apps fails", func() {
+		BeforeEach(func() {
+			cfClient.GetApplicationNamesReturns(nil, errors.New("get-apps-error"))
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError("get-apps-error"))
+		})
+	})
+
+	When("getting the current org fails", func() {
+		BeforeEach(func() {
+			cfClient.GetCurrentOrgReturns("", errors.New("get-org-error"))
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError("get-org-error"))
+		})
+	})
+
+	When("creating the report of an app fails", func() {
+		BeforeEach(func() {
+			cumulativeUsageFetcher.FetchInstanceDataReturns(nil, errors.New("fetch-error"))
+			cumulativeUsageFetcher.FetchInstanceDataStub = nil
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError("fetch-error"))
+		})
+
+		When("the concurrency is limited to one", func() {
+			BeforeEach(func() {
+				appReporter = appReporter.WithConcurrency(1)
+			})
+
+			It("does not create the remaining reports", func() {
+				Expect(err).To(MatchError("fetch-error"))
+				Expect(cfClient.GetApplicationCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	When("the concurrency is limited to one", func() {
+		BeforeEach(func() {
+			appReporter = appReporter.WithConcurrency(1)
+		})
+
+		It("still summarises every app with CPU data", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Apps).To(HaveLen(3))
+			Expect(report.Apps[0].ApplicationName).To(Equal("busy"))
+		})
+	})
+})
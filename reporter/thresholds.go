@@ -0,0 +1,22 @@
+package reporter
+
+// Thresholds are the ratios of usage to entitlement above which an instance
+// is considered over or near its entitlement.
+type Thresholds struct {
+	Over float64
+	Near float64
+}
+
+// DefaultThresholds treats any usage above the entitlement as over it, and
+// any usage above 95% of it as near it.
+var DefaultThresholds = Thresholds{Over: 1, Near: 0.95}
+
+func (t Thresholds) IsOver(usage float64) bool {
+	return usage > t.Over
+}
+
+// IsNear is true when the usage is above the near threshold but not over the
+// entitlement.
+func (t Thresholds) IsNear(usage float64) bool {
+	return usage > t.Near && !t.IsOver(usage)
+}
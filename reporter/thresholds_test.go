@@ -0,0 +1,27 @@
+package reporter_test
+
+import (
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Thresholds", func() {
+	var thresholds reporter.Thresholds
+
+	BeforeEach(func() {
+		thresholds = reporter.Thresholds{Over: 0.8, Near: 0.6}
+	})
+
+	It("is over above the over threshold", func() {
+		Expect(thresholds.IsOver(0.81)).To(BeTrue())
+		Expect(thresholds.IsOver(0.8)).To(BeFalse())
+	})
+
+	It("is near above the near threshold but not over", func() {
+		Expect(thresholds.IsNear(0.6)).To(BeFalse())
+		Expect(thresholds.IsNear(0.61)).To(BeTrue())
+		Expect(thresholds.IsNear(0.8)).To(BeTrue())
+		Expect(thresholds.IsNear(0.81)).To(BeFalse())
+	})
+})
@@ -0,0 +1,94 @@
+package reporter
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ThrottleModel simulates the future CPU policy, under which an instance is
+// deprioritized while its average usage over the rolling window before it is
+// above the threshold ratio of its entitlement.
+type ThrottleModel struct {
+	Window    time.Duration
+	Threshold float64
+}
+
+// ThrottlePreview is when an instance would have been deprioritized by the
+// throttle model. It is only set on instance reports when the reporter has a
+// throttle preview fetcher.
+type ThrottlePreview struct {
+	Window  time.Duration
+	Periods []Period
+}
+
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+func (p Period) Duration() time.Duration {
+	return p.To.Sub(p.From)
+}
+
+// TotalDuration is the time the instance would have been deprioritized.
+func (p ThrottlePreview) TotalDuration() time.Duration {
+	var total time.Duration
+	for _, period := range p.Periods {
+		total += period.Duration()
+	}
+	return total
+}
+
+// Preview applies the model to usage samples taken at a regular step. Only
+// samples with a whole window of history before them are considered, so the
+// first window of the samples is never deprioritized. A period lasts from the
+// first deprioritized sample to the first sample after it that is not, or to
+// the last sample. Samples that are not finite, such as the NaN of a step
+// without any entitlement, are skipped, as they would spoil the running sum.
+func (m ThrottleModel) Preview(samples []UsageSample) ThrottlePreview {
+	var sorted []UsageSample
+	for _, sample := range samples {
+		if !math.IsNaN(sample.Value) && !math.IsInf(sample.Value, 0) {
+			sorted = append(sorted, sample)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	preview := ThrottlePreview{Window: m.Window}
+	var current *Period
+	var sum float64
+	start := 0
+	for i, sample := range sorted {
+		sum += sample.Value
+		for !sorted[start].Time.After(sample.Time.Add(-m.Window)) {
+			sum -= sorted[start].Value
+			start++
+		}
+
+		deprioritized := start > 0 && sum/float64(i-start+1) > m.Threshold
+		switch {
+		case deprioritized && current == nil:
+			current = &Period{From: sample.Time, To: sample.Time}
+		case deprioritized:
+			current.To = sample.Time
+		case current != nil:
+			current.To = sample.Time
+			preview.Periods = append(preview.Periods, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		preview.Periods = append(preview.Periods, *current)
+	}
+
+	return preview
+}
+
+// IsDeprioritized tells whether the instance would have been deprioritized at
+// all.
+func (p ThrottlePreview) IsDeprioritized() bool {
+	return len(p.Periods) > 0
+}
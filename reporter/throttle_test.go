@@ -0,0 +1,89 @@
+package reporter_test
+
+import (
+	"math"
+	"time"
+
+	"code.cloudfoundry.org/cpu-entitlement-plugin/reporter"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ThrottleModel", func() {
+	var (
+		model   reporter.ThrottleModel
+		samples []reporter.UsageSample
+		preview reporter.ThrottlePreview
+	)
+
+	minute := func(i int) time.Time {
+		return time.Unix(0, 0).Add(time.Duration(i) * time.Minute)
+	}
+
+	BeforeEach(func() {
+		model = reporter.ThrottleModel{Window: 3 * time.Minute, Threshold: 1}
+		samples = nil
+		for i, value := range []float64{2, 2, 2, 0.5, 0.5, 0.5, 0.5, 2, 2, 2} {
+			samples = append(samples, reporter.UsageSample{Time: minute(i), Value: value})
+		}
+	})
+
+	JustBeforeEach(func() {
+		preview = model.Preview(samples)
+	})
+
+	It("reports the periods the average over the window is above the threshold", func() {
+		Expect(preview.Window).To(Equal(3 * time.Minute))
+		Expect(preview.Periods).To(Equal([]reporter.Period{
+			{From: minute(3), To: minute(4)},
+			{From: minute(8), To: minute(9)},
+		}))
+		Expect(preview.TotalDuration()).To(Equal(2 * time.Minute))
+		Expect(preview.IsDeprioritized()).To(BeTrue())
+	})
+
+	When("the samples are not sorted", func() {
+		BeforeEach(func() {
+			samples[0], samples[9] = samples[9], samples[0]
+		})
+
+		It("sorts them first", func() {
+			Expect(preview.Periods).To(HaveLen(2))
+		})
+	})
+
+	When("a sample is not a number", func() {
+		BeforeEach(func() {
+			samples[5].Value = math.NaN()
+		})
+
+		It("skips it and averages the samples left in the window", func() {
+			Expect(preview.Periods).To(Equal([]reporter.Period{
+				{From: minute(3), To: minute(4)},
+				{From: minute(7), To: minute(9)},
+			}))
+		})
+	})
+
+	When("a sample is infinite", func() {
+		BeforeEach(func() {
+			samples[5].Value = math.Inf(1)
+		})
+
+		It("skips it", func() {
+			Expect(preview.Periods).To(HaveLen(2))
+			Expect(preview.TotalDuration()).To(Equal(3 * time.Minute))
+		})
+	})
+
+	When("the samples do not cover a whole window", func() {
+		BeforeEach(func() {
+			samples = samples[:3]
+		})
+
+		It("does not deprioritize the instance", func() {
+			Expect(preview.Periods).To(BeEmpty())
+			Expect(preview.IsDeprioritized()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,48 @@
+package reporter
+
+import (
+	"math"
+	"sort"
+)
+
+// UsageStats summarises the usage samples of an instance. It is only set on
+// instance reports when the reporter has a usage stats fetcher. All values are
+// zero when there are no samples.
+type UsageStats struct {
+	P50         float64
+	P95         float64
+	P99         float64
+	Max         float64
+	SampleCount int
+}
+
+func computeUsageStats(samples []UsageSample) UsageStats {
+	if len(samples) == 0 {
+		return UsageStats{}
+	}
+
+	values := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		values = append(values, sample.Value)
+	}
+	sort.Float64s(values)
+
+	return UsageStats{
+		P50:         percentile(values, 50),
+		P95:         percentile(values, 95),
+		P99:         percentile(values, 99),
+		Max:         values[len(values)-1],
+		SampleCount: len(values),
+	}
+}
+
+// percentile uses the nearest-rank method, so the result is always one of the
+// sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
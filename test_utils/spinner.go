@@ -43,9 +43,18 @@ func pushSpinnerCmd(appName string, instances int) Command {
 		WithTimeout("3m")
 }
 
+// insecureClient skips SSL validation without changing http.DefaultTransport
+// for the rest of the tests.
+var insecureClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	},
+}
+
 func httpGet(url string) {
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	resp, err := http.Get(url)
+	resp, err := insecureClient.Get(url)
 	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	defer resp.Body.Close()
 	ExpectWithOffset(1, resp.StatusCode).To(Equal(http.StatusOK))
 }